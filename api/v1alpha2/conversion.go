@@ -44,6 +44,7 @@ func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 
 	dst.Spec.ControlPlaneRef = restored.Spec.ControlPlaneRef
 	dst.Status.ControlPlaneReady = restored.Status.ControlPlaneReady
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -102,6 +103,9 @@ func (src *Machine) ConvertTo(dstRaw conversion.Hub) error {
 		return err
 	}
 	restoreMachineSpec(&restored.Spec, &dst.Spec)
+	dst.Status.CertificatesExpiryDate = restored.Status.CertificatesExpiryDate
+	dst.Status.NodeDrainStartTime = restored.Status.NodeDrainStartTime
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -111,6 +115,8 @@ func restoreMachineSpec(restored *v1alpha3.MachineSpec, dst *v1alpha3.MachineSpe
 		dst.ClusterName = restored.ClusterName
 	}
 	dst.Bootstrap.DataSecretName = restored.Bootstrap.DataSecretName
+	dst.FailureDomain = restored.FailureDomain
+	dst.NodeDrainTimeout = restored.NodeDrainTimeout
 }
 
 func (dst *Machine) ConvertFrom(srcRaw conversion.Hub) error {
@@ -161,6 +167,7 @@ func (src *MachineSet) ConvertTo(dstRaw conversion.Hub) error {
 		dst.Spec.ClusterName = restored.Spec.ClusterName
 	}
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -214,6 +221,8 @@ func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 	}
 	dst.Spec.Paused = restored.Spec.Paused
 	dst.Status.Phase = restored.Status.Phase
+	dst.Status.LastProgressTime = restored.Status.LastProgressTime
+	dst.Status.Conditions = restored.Status.Conditions
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
 
 	return nil
@@ -31,6 +31,10 @@ const (
 	// Controllers working with Cluster API objects must check the existence of this annotation
 	// on the reconciled object.
 	PausedAnnotation = "cluster.x-k8s.io/paused"
+
+	// DeleteMachineAnnotation marks machines that will be given priority for deletion
+	// when a machineset scales down. This annotation is given top priority on all delete policies.
+	DeleteMachineAnnotation = "cluster.x-k8s.io/delete-machine"
 )
 
 // MachineAddressType describes a valid MachineAddress type.
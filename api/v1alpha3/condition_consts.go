@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+// Common ConditionReasons used by Cluster API objects.
+
+const (
+	// DeletingReason (Severity=Info) documents an object waiting for the cleanup of its dependent objects
+	// before being deleted.
+	DeletingReason = "Deleting"
+)
+
+// Conditions and condition Reasons for the Cluster object
+
+const (
+	// InfrastructureReadyCondition reports a summary of the current status of the infrastructure object defined
+	// for this cluster/machine. This condition is mirrored from the Ready condition in the infrastructure ref
+	// object when available, otherwise it is derived from the ready field of the infrastructure object status.
+	InfrastructureReadyCondition ConditionType = "InfrastructureReady"
+
+	// WaitingForInfrastructureFallbackReason (Severity=Info) documents a cluster/machine waiting for the infrastructure
+	// object to be ready.
+	// NOTE: This reason is used only as a fallback when the infrastructure object is not reporting its own ready condition.
+	WaitingForInfrastructureFallbackReason = "WaitingForInfrastructure"
+)
+
+const (
+	// ControlPlaneReadyCondition reports the ready condition from the control plane object defined for this cluster.
+	// This condition is mirrored from the Ready condition in the control plane ref object when available, otherwise
+	// it is derived from the ready field of the control plane object status.
+	ControlPlaneReadyCondition ConditionType = "ControlPlaneReady"
+
+	// WaitingForControlPlaneFallbackReason (Severity=Info) documents a cluster waiting for the control plane
+	// to be available.
+	// NOTE: This reason is used only as a fallback when the control plane object is not reporting its own ready condition.
+	WaitingForControlPlaneFallbackReason = "WaitingForControlPlane"
+)
+
+// Conditions and condition Reasons for the Machine object
+
+const (
+	// BootstrapReadyCondition reports a summary of the current status of the bootstrap object defined for this machine.
+	// This condition is mirrored from the Ready condition in the bootstrap ref object when available, otherwise
+	// it is derived from the ready field of the bootstrap object status.
+	BootstrapReadyCondition ConditionType = "BootstrapReady"
+
+	// WaitingForDataSecretFallbackReason (Severity=Info) documents a machine waiting for the bootstrap data secret
+	// to be available.
+	// NOTE: This reason is used only as a fallback when the bootstrap object is not reporting its own ready condition.
+	WaitingForDataSecretFallbackReason = "WaitingForDataSecret"
+)
+
+const (
+	// NodeHealthyCondition provides info about the operational state of the Kubernetes node hosted on the machine
+	// by summarizing the node conditions. If the node is ready, the condition is True.
+	NodeHealthyCondition ConditionType = "NodeHealthy"
+
+	// WaitingForNodeRefReason (Severity=Info) documents a machine.spec.providerId is not assigned yet.
+	WaitingForNodeRefReason = "WaitingForNodeRef"
+
+	// NodeNotFoundReason (Severity=Error) documents a machine's node has previously been observed but is now gone.
+	// NB. provisioned --> NodeRef != ""
+	NodeNotFoundReason = "NodeNotFound"
+
+	// NodeConditionsFailedReason (Severity=Warning) documents a node that is not ready, summarizing the node
+	// conditions that are failing.
+	NodeConditionsFailedReason = "NodeConditionsFailed"
+)
+
+const (
+	// DrainingSucceededCondition provide evidence of the status of the node drain operation which happens during the
+	// machine deletion process.
+	DrainingSucceededCondition ConditionType = "DrainingSucceeded"
+
+	// DrainingReason (Severity=Info) documents a machine node being drained.
+	DrainingReason = "Draining"
+
+	// DrainingFailedReason (Severity=Warning) documents a machine node drain operation failed.
+	DrainingFailedReason = "DrainingFailed"
+
+	// DrainingTimeoutExceededReason (Severity=Warning) documents a machine node that was not drained within
+	// the machine NodeDrainTimeout; the deletion of the machine proceeds without waiting for the drain to complete.
+	DrainingTimeoutExceededReason = "DrainingTimeoutExceeded"
+)
+
+const (
+	// PreDrainDeleteHookSucceededCondition reports a machine waiting for a PreDrainDeleteHook before being deleted.
+	PreDrainDeleteHookSucceededCondition ConditionType = "PreDrainDeleteHookSucceeded"
+
+	// PreTerminateDeleteHookSucceededCondition reports a machine waiting for a PreTerminateDeleteHook before being deleted.
+	PreTerminateDeleteHookSucceededCondition ConditionType = "PreTerminateDeleteHookSucceeded"
+
+	// WaitingExternalHookReason (Severity=Info) provide evidence that we are waiting for an external hook to complete.
+	WaitingExternalHookReason = "WaitingExternalHook"
+)
+
+// Conditions and condition Reasons for the MachineSet object
+
+const (
+	// MachinesCreatedCondition documents that the machines controlled by the MachineSet are created.
+	// When this condition is false, it indicates that there was an error when cloning the infrastructure/bootstrap
+	// template or when generating the machine object.
+	MachinesCreatedCondition ConditionType = "MachinesCreated"
+
+	// MachineCreationFailedReason (Severity=Error) documents a MachineSet failing to create one or more of its
+	// Machines, including the cloning of the infrastructure and bootstrap templates.
+	MachineCreationFailedReason = "MachineCreationFailed"
+)
+
+const (
+	// ResizedCondition documents a MachineSet is resizing the set of controlled machines.
+	ResizedCondition ConditionType = "Resized"
+
+	// ScalingUpReason (Severity=Info) documents a MachineSet is increasing the number of replicas.
+	ScalingUpReason = "ScalingUp"
+
+	// ScalingDownReason (Severity=Info) documents a MachineSet is decreasing the number of replicas.
+	ScalingDownReason = "ScalingDown"
+)
+
+const (
+	// MachinesReadyCondition documents that all the machines controlled by the MachineSet have a ready Node.
+	MachinesReadyCondition ConditionType = "MachinesReady"
+
+	// MachinesNotReadyReason (Severity=Warning) documents that some of the machines controlled by the
+	// MachineSet don't have a ready Node.
+	MachinesNotReadyReason = "MachinesNotReady"
+)
+
+// Conditions and condition Reasons for the MachineDeployment object
+
+const (
+	// MachineDeploymentAvailableCondition means the MachineDeployment is available, that is, at least the minimum
+	// available machines required (i.e. Spec.Replicas-MaxUnavailable when MachineDeploymentStrategyType =
+	// RollingUpdate) are up and running for at least minReadySeconds.
+	MachineDeploymentAvailableCondition ConditionType = "Available"
+
+	// WaitingForAvailableMachinesReason (Severity=Warning) reflects the fact that the required minimum number of
+	// machines for a MachineDeployment are not available.
+	WaitingForAvailableMachinesReason = "WaitingForAvailableMachines"
+)
+
+const (
+	// MachineDeploymentProgressingCondition documents that the MachineDeployment is making progress rolling out
+	// its Machines, or that the rollout is complete, within the deadline defined by Spec.ProgressDeadlineSeconds.
+	MachineDeploymentProgressingCondition ConditionType = "Progressing"
+
+	// ProgressDeadlineExceededReason (Severity=Error) documents a MachineDeployment that did not make progress
+	// within Spec.ProgressDeadlineSeconds; the rollout is not aborted, but user intervention might be required.
+	ProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
+)
@@ -37,6 +37,20 @@ const (
 
 	// MachineDeploymentLabelName is the label set on machines if they're controlled by MachineDeployment
 	MachineDeploymentLabelName = "cluster.x-k8s.io/deployment-name"
+
+	// PreDrainDeleteHookAnnotationPrefix annotation specifies the prefix we
+	// search each annotation for during the pre-drain.delete lifecycle hook
+	// to pause reconciliation of deletion. These hooks will prevent removal of
+	// draining the associated node until all are removed.
+	// Example: pre-drain.delete.hook.machine.cluster.x-k8s.io/lb-deregister: my-lb-controller
+	PreDrainDeleteHookAnnotationPrefix = "pre-drain.delete.hook.machine.cluster.x-k8s.io"
+
+	// PreTerminateDeleteHookAnnotationPrefix annotation specifies the prefix we
+	// search each annotation for during the pre-terminate.delete lifecycle hook
+	// to pause reconciliation of deletion. These hooks will prevent removal of
+	// an instance from an infrastructure provider until all are removed.
+	// Example: pre-terminate.delete.hook.machine.cluster.x-k8s.io/etcd-snapshot: my-backup-controller
+	PreTerminateDeleteHookAnnotationPrefix = "pre-terminate.delete.hook.machine.cluster.x-k8s.io"
 )
 
 // ANCHOR: MachineSpec
@@ -72,6 +86,17 @@ type MachineSpec struct {
 	// be interfacing with cluster-api as generic provider.
 	// +optional
 	ProviderID *string `json:"providerID,omitempty"`
+
+	// FailureDomain is the failure domain the machine will be created in.
+	// Must match a key in the FailureDomains map stored on the cluster object.
+	// +optional
+	FailureDomain *string `json:"failureDomain,omitempty"`
+
+	// NodeDrainTimeout is the total amount of time that the controller will spend on draining a node.
+	// The default value is 0, meaning that the node can be drained without any time limitations.
+	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
+	// +optional
+	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
@@ -88,6 +113,10 @@ type MachineStatus struct {
 	// +optional
 	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
 
+	// NodeDrainStartTime is the time when the drain of the node started.
+	// +optional
+	NodeDrainStartTime *metav1.Time `json:"nodeDrainStartTime,omitempty"`
+
 	// Version specifies the current version of Kubernetes running
 	// on the corresponding Node. This is meant to be a means of bubbling
 	// up status from the Node to the Machine.
@@ -150,6 +179,15 @@ type MachineStatus struct {
 	// InfrastructureReady is the state of the infrastructure provider.
 	// +optional
 	InfrastructureReady bool `json:"infrastructureReady"`
+
+	// CertificatesExpiryDate is the expiry date of the certificates of the Machine.
+	// This value is only set for control plane Machines.
+	// +optional
+	CertificatesExpiryDate *metav1.Time `json:"certificatesExpiryDate,omitempty"`
+
+	// Conditions defines current service state of the Machine.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineStatus
@@ -222,6 +260,16 @@ type Machine struct {
 	Status MachineStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (m *Machine) GetConditions() Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (m *Machine) SetConditions(conditions Conditions) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineList contains a list of Machine
@@ -27,6 +27,14 @@ const (
 	// Replace the old MachineSet by new one using rolling update
 	// i.e. gradually scale down the old MachineSet and scale up the new one.
 	RollingUpdateMachineDeploymentStrategyType MachineDeploymentStrategyType = "RollingUpdate"
+
+	// Replace the old Machines only when they are deleted by users,
+	// i.e. the old MachineSet never deletes Machines and the new one grows as old Machines are deleted.
+	OnDeleteMachineDeploymentStrategyType MachineDeploymentStrategyType = "OnDelete"
+
+	// Delete all the old Machines before creating the new ones,
+	// i.e. scale down the old MachineSet to zero and then scale up the new one.
+	RecreateMachineDeploymentStrategyType MachineDeploymentStrategyType = "Recreate"
 )
 
 // ANCHOR: MachineDeploymentSpec
@@ -86,9 +94,10 @@ type MachineDeploymentSpec struct {
 // MachineDeploymentStrategy describes how to replace existing machines
 // with new ones.
 type MachineDeploymentStrategy struct {
-	// Type of deployment. Currently the only supported strategy is
-	// "RollingUpdate".
+	// Type of deployment. Valid values are "RollingUpdate", "OnDelete"
+	// and "Recreate".
 	// Default is RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete;Recreate
 	// +optional
 	Type MachineDeploymentStrategyType `json:"type,omitempty"`
 
@@ -182,6 +191,16 @@ type MachineDeploymentStatus struct {
 	// Phase represents the current phase of a MachineDeployment (ScalingUp, ScalingDown, Running, Failed, or Unknown).
 	// +optional
 	Phase string `json:"phase,omitempty"`
+
+	// LastProgressTime is the last time the MachineDeployment made progress rolling out its Machines,
+	// e.g. a new MachineSet was created or more Machines became available. It is compared against
+	// ProgressDeadlineSeconds to detect rollouts that are stuck.
+	// +optional
+	LastProgressTime *metav1.Time `json:"lastProgressTime,omitempty"`
+
+	// Conditions defines current service state of the MachineDeployment.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentStatus
@@ -242,6 +261,16 @@ type MachineDeployment struct {
 	Status MachineDeploymentStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (m *MachineDeployment) GetConditions() Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (m *MachineDeployment) SetConditions(conditions Conditions) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineDeploymentList contains a list of MachineDeployment
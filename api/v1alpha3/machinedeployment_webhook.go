@@ -81,6 +81,35 @@ func (m *MachineDeployment) validate() error {
 		)
 	}
 
+	if m.Spec.Strategy != nil {
+		switch m.Spec.Strategy.Type {
+		case RollingUpdateMachineDeploymentStrategyType, OnDeleteMachineDeploymentStrategyType, RecreateMachineDeploymentStrategyType:
+		default:
+			allErrs = append(
+				allErrs,
+				field.NotSupported(
+					field.NewPath("spec", "strategy", "type"),
+					m.Spec.Strategy.Type,
+					[]string{
+						string(RollingUpdateMachineDeploymentStrategyType),
+						string(OnDeleteMachineDeploymentStrategyType),
+						string(RecreateMachineDeploymentStrategyType),
+					},
+				),
+			)
+		}
+
+		if m.Spec.Strategy.Type != RollingUpdateMachineDeploymentStrategyType && m.Spec.Strategy.RollingUpdate != nil {
+			allErrs = append(
+				allErrs,
+				field.Forbidden(
+					field.NewPath("spec", "strategy", "rollingUpdate"),
+					fmt.Sprintf("may not be specified when strategy type is %q", m.Spec.Strategy.Type),
+				),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
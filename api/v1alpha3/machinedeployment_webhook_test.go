@@ -107,3 +107,84 @@ func TestMachineDeploymentValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineDeploymentStrategyValidation(t *testing.T) {
+	rollingUpdate := &MachineRollingUpdateDeployment{}
+
+	tests := []struct {
+		name      string
+		strategy  *MachineDeploymentStrategy
+		expectErr bool
+	}{
+		{
+			name:      "should not return error for RollingUpdate",
+			strategy:  &MachineDeploymentStrategy{Type: RollingUpdateMachineDeploymentStrategyType, RollingUpdate: rollingUpdate},
+			expectErr: false,
+		},
+		{
+			name:      "should not return error for OnDelete",
+			strategy:  &MachineDeploymentStrategy{Type: OnDeleteMachineDeploymentStrategyType},
+			expectErr: false,
+		},
+		{
+			name:      "should not return error for Recreate",
+			strategy:  &MachineDeploymentStrategy{Type: RecreateMachineDeploymentStrategyType},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for an unknown strategy",
+			strategy:  &MachineDeploymentStrategy{Type: "BlueGreen"},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for rolling update params with Recreate",
+			strategy:  &MachineDeploymentStrategy{Type: RecreateMachineDeploymentStrategyType, RollingUpdate: rollingUpdate},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for rolling update params with OnDelete",
+			strategy:  &MachineDeploymentStrategy{Type: OnDeleteMachineDeploymentStrategyType, RollingUpdate: rollingUpdate},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			md := &MachineDeployment{
+				Spec: MachineDeploymentSpec{
+					Selector: v1.LabelSelector{
+						MatchLabels: map[string]string{"foo": "bar"},
+					},
+					Template: MachineTemplateSpec{
+						ObjectMeta: ObjectMeta{
+							Labels: map[string]string{"foo": "bar"},
+						},
+					},
+					Strategy: tt.strategy,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(md.ValidateCreate()).NotTo(gomega.Succeed())
+				g.Expect(md.ValidateUpdate(nil)).NotTo(gomega.Succeed())
+			} else {
+				g.Expect(md.ValidateCreate()).To(gomega.Succeed())
+				g.Expect(md.ValidateUpdate(nil)).To(gomega.Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineDeploymentDefaultNonRollingStrategy(t *testing.T) {
+	g := gomega.NewWithT(t)
+	md := &MachineDeployment{
+		Spec: MachineDeploymentSpec{
+			Strategy: &MachineDeploymentStrategy{Type: RecreateMachineDeploymentStrategyType},
+		},
+	}
+
+	md.Default()
+
+	g.Expect(md.Spec.Strategy.Type).To(gomega.Equal(RecreateMachineDeploymentStrategyType))
+	g.Expect(md.Spec.Strategy.RollingUpdate).To(gomega.BeNil())
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestMachineHealthCheckDefault(t *testing.T) {
+	g := gomega.NewWithT(t)
+	mhc := &MachineHealthCheck{
+		Spec: MachineHealthCheckSpec{
+			ClusterName: "test-cluster",
+		},
+	}
+
+	mhc.Default()
+
+	g.Expect(mhc.Labels[ClusterLabelName]).To(gomega.Equal("test-cluster"))
+	g.Expect(mhc.Spec.MaxUnhealthy.String()).To(gomega.Equal("100%"))
+	g.Expect(mhc.Spec.NodeStartupTimeout).To(gomega.Equal(&metav1.Duration{Duration: 10 * time.Minute}))
+}
+
+func TestMachineHealthCheckValidation(t *testing.T) {
+	valid := &MachineHealthCheck{
+		Spec: MachineHealthCheckSpec{
+			ClusterName: "test-cluster",
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"role": "worker"},
+			},
+		},
+	}
+
+	invalidSelector := valid.DeepCopy()
+	invalidSelector.Spec.Selector.MatchLabels = map[string]string{"-123-foo": "bar"}
+
+	emptySelector := valid.DeepCopy()
+	emptySelector.Spec.Selector = metav1.LabelSelector{}
+
+	shortNodeStartupTimeout := valid.DeepCopy()
+	shortNodeStartupTimeout.Spec.NodeStartupTimeout = &metav1.Duration{Duration: 10 * time.Second}
+
+	maxUnhealthyPercent := intstr.FromString("40%")
+	validMaxUnhealthyPercent := valid.DeepCopy()
+	validMaxUnhealthyPercent.Spec.MaxUnhealthy = &maxUnhealthyPercent
+
+	maxUnhealthyInvalid := intstr.FromString("forty")
+	invalidMaxUnhealthy := valid.DeepCopy()
+	invalidMaxUnhealthy.Spec.MaxUnhealthy = &maxUnhealthyInvalid
+
+	maxUnhealthyNegative := intstr.FromInt(-1)
+	negativeMaxUnhealthy := valid.DeepCopy()
+	negativeMaxUnhealthy.Spec.MaxUnhealthy = &maxUnhealthyNegative
+
+	tests := []struct {
+		name      string
+		mhc       *MachineHealthCheck
+		expectErr bool
+	}{
+		{
+			name: "should succeed when given a valid MachineHealthCheck",
+			mhc:  valid,
+		},
+		{
+			name:      "should return error for invalid selector",
+			mhc:       invalidSelector,
+			expectErr: true,
+		},
+		{
+			name:      "should return error for empty selector",
+			mhc:       emptySelector,
+			expectErr: true,
+		},
+		{
+			name:      "should return error when the node startup timeout is too short",
+			mhc:       shortNodeStartupTimeout,
+			expectErr: true,
+		},
+		{
+			name: "should succeed when maxUnhealthy is a percentage",
+			mhc:  validMaxUnhealthyPercent,
+		},
+		{
+			name:      "should return error when maxUnhealthy is neither an integer nor a percentage",
+			mhc:       invalidMaxUnhealthy,
+			expectErr: true,
+		},
+		{
+			name:      "should return error when maxUnhealthy is negative",
+			mhc:       negativeMaxUnhealthy,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			if tt.expectErr {
+				g.Expect(tt.mhc.ValidateCreate()).NotTo(gomega.Succeed())
+				g.Expect(tt.mhc.ValidateUpdate(tt.mhc)).NotTo(gomega.Succeed())
+			} else {
+				g.Expect(tt.mhc.ValidateCreate()).To(gomega.Succeed())
+				g.Expect(tt.mhc.ValidateUpdate(tt.mhc)).To(gomega.Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckImmutableFields(t *testing.T) {
+	old := &MachineHealthCheck{
+		Spec: MachineHealthCheckSpec{
+			ClusterName: "test-cluster",
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"role": "worker"},
+			},
+		},
+	}
+
+	otherCluster := old.DeepCopy()
+	otherCluster.Spec.ClusterName = "other-cluster"
+
+	otherSelector := old.DeepCopy()
+	otherSelector.Spec.Selector.MatchLabels = map[string]string{"role": "control-plane"}
+
+	otherTimeout := old.DeepCopy()
+	otherTimeout.Spec.NodeStartupTimeout = &metav1.Duration{Duration: 20 * time.Minute}
+
+	g := gomega.NewWithT(t)
+	g.Expect(otherCluster.ValidateUpdate(old)).NotTo(gomega.Succeed())
+	g.Expect(otherSelector.ValidateUpdate(old)).NotTo(gomega.Succeed())
+	g.Expect(otherTimeout.ValidateUpdate(old)).To(gomega.Succeed())
+}
@@ -171,6 +171,7 @@ func (m *MachinePoolStatus) GetTypedPhase() MachinePoolPhase {
 		MachinePoolPhasePending,
 		MachinePoolPhaseProvisioning,
 		MachinePoolPhaseProvisioned,
+		MachinePoolPhaseRunning,
 		MachinePoolPhaseDeleting,
 		MachinePoolPhaseFailed:
 		return phase
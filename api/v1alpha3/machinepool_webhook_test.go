@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestMachinePoolDefault(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	m := &MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foobar",
+		},
+		Spec: MachinePoolSpec{
+			ClusterName: "test-cluster",
+			Template: MachineTemplateSpec{
+				Spec: MachineSpec{
+					Bootstrap: Bootstrap{ConfigRef: &corev1.ObjectReference{}},
+				},
+			},
+		},
+	}
+
+	m.Default()
+
+	g.Expect(m.Labels[ClusterLabelName]).To(gomega.Equal("test-cluster"))
+	g.Expect(m.Spec.Replicas).To(gomega.Equal(pointer.Int32Ptr(1)))
+	g.Expect(m.Spec.Template.Spec.Bootstrap.ConfigRef.Namespace).To(gomega.Equal(m.Namespace))
+	g.Expect(m.Spec.Template.Spec.InfrastructureRef.Namespace).To(gomega.Equal(m.Namespace))
+}
+
+func TestMachinePoolValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		bootstrap Bootstrap
+		infraRef  corev1.ObjectReference
+		expectErr bool
+	}{
+		{
+			name:      "should return error if configref and data are nil",
+			bootstrap: Bootstrap{ConfigRef: nil, DataSecretName: nil},
+			expectErr: true,
+		},
+		{
+			name:      "should not return error if dataSecretName is set",
+			bootstrap: Bootstrap{DataSecretName: pointer.StringPtr("test")},
+		},
+		{
+			name:      "should not return error if config ref is set",
+			bootstrap: Bootstrap{ConfigRef: &corev1.ObjectReference{}},
+		},
+		{
+			name:      "should return error if the config ref namespace doesn't match",
+			namespace: "foobar",
+			bootstrap: Bootstrap{ConfigRef: &corev1.ObjectReference{Namespace: "other"}},
+			infraRef:  corev1.ObjectReference{Namespace: "foobar"},
+			expectErr: true,
+		},
+		{
+			name:      "should return error if the infrastructure ref namespace doesn't match",
+			namespace: "foobar",
+			bootstrap: Bootstrap{DataSecretName: pointer.StringPtr("test")},
+			infraRef:  corev1.ObjectReference{Namespace: "other"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			m := &MachinePool{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace},
+				Spec: MachinePoolSpec{
+					Template: MachineTemplateSpec{
+						Spec: MachineSpec{
+							Bootstrap:         tt.bootstrap,
+							InfrastructureRef: tt.infraRef,
+						},
+					},
+				},
+			}
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(gomega.Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(gomega.Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(gomega.Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(gomega.Succeed())
+			}
+		})
+	}
+}
+
+func TestMachinePoolClusterNameImmutable(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	old := &MachinePool{
+		Spec: MachinePoolSpec{
+			ClusterName: "test-cluster",
+			Template: MachineTemplateSpec{
+				Spec: MachineSpec{
+					Bootstrap: Bootstrap{DataSecretName: pointer.StringPtr("test")},
+				},
+			},
+		},
+	}
+	m := old.DeepCopy()
+	m.Spec.ClusterName = "other-cluster"
+
+	g.Expect(m.ValidateUpdate(old)).NotTo(gomega.Succeed())
+}
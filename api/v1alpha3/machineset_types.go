@@ -26,6 +26,13 @@ import (
 	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
+const (
+	// DisableMachineCreateAnnotation can be set to "true" on a MachineSet to prevent it from creating new Machines,
+	// e.g. to replace the Machines deleted by users. It is set on the old MachineSets of a MachineDeployment using
+	// the OnDelete strategy.
+	DisableMachineCreateAnnotation = "cluster.x-k8s.io/disable-machine-create"
+)
+
 // ANCHOR: MachineSetSpec
 
 // MachineSetSpec defines the desired state of MachineSet
@@ -89,19 +96,22 @@ type MachineSetDeletePolicy string
 const (
 	// RandomMachineSetDeletePolicy prioritizes both Machines that have the annotation
 	// "cluster.x-k8s.io/delete-machine=yes" and Machines that are unhealthy
-	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
+	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value,
+	// Status.NodeRef is not set, or the NodeHealthy condition is not true).
 	// Finally, it picks Machines at random to delete.
 	RandomMachineSetDeletePolicy MachineSetDeletePolicy = "Random"
 
 	// NewestMachineSetDeletePolicy prioritizes both Machines that have the annotation
 	// "cluster.x-k8s.io/delete-machine=yes" and Machines that are unhealthy
-	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
+	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value,
+	// Status.NodeRef is not set, or the NodeHealthy condition is not true).
 	// It then prioritizes the newest Machines for deletion based on the Machine's CreationTimestamp.
 	NewestMachineSetDeletePolicy MachineSetDeletePolicy = "Newest"
 
 	// OldestMachineSetDeletePolicy prioritizes both Machines that have the annotation
 	// "cluster.x-k8s.io/delete-machine=yes" and Machines that are unhealthy
-	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value).
+	// (Status.FailureReason or Status.FailureMessage are set to a non-empty value,
+	// Status.NodeRef is not set, or the NodeHealthy condition is not true).
 	// It then prioritizes the oldest Machines for deletion based on the Machine's CreationTimestamp.
 	OldestMachineSetDeletePolicy MachineSetDeletePolicy = "Oldest"
 )
@@ -157,6 +167,10 @@ type MachineSetStatus struct {
 	FailureReason *capierrors.MachineSetStatusError `json:"failureReason,omitempty"`
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions defines current service state of the MachineSet.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 // ANCHOR_END: MachineSetStatus
@@ -219,6 +233,16 @@ type MachineSet struct {
 	Status MachineSetStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (m *MachineSet) GetConditions() Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (m *MachineSet) SetConditions(conditions Conditions) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineSetList contains a list of MachineSet
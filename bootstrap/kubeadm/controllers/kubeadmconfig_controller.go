@@ -67,6 +67,9 @@ type KubeadmConfigReconciler struct {
 	Log             logr.Logger
 	scheme          *runtime.Scheme
 
+	// Tracker, if set, provides cached clients for the workload clusters.
+	Tracker *remote.ClusterCacheTracker
+
 	// for testing
 	remoteClient func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
 }
@@ -85,6 +88,9 @@ func (r *KubeadmConfigReconciler) SetupWithManager(mgr ctrl.Manager, option cont
 	}
 	if r.remoteClient == nil {
 		r.remoteClient = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClient = r.Tracker.ClusterClient
+		}
 	}
 
 	r.scheme = mgr.GetScheme()
@@ -431,7 +437,8 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 		scope.Config.Spec.JoinConfiguration.ControlPlane = &kubeadmv1beta1.JoinControlPlane{}
 	}
 
-	certificates := secret.NewCertificatesForJoiningControlPlane()
+	// The cluster configuration is only provided to joining control planes to describe an external etcd cluster.
+	certificates := secret.NewCertificatesForJoiningControlPlane(scope.Config.Spec.ClusterConfiguration)
 	err := certificates.Lookup(
 		ctx,
 		r.Client,
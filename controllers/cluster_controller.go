@@ -25,6 +25,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,6 +37,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/metrics"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -121,6 +123,14 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 		r.reconcilePhase(ctx, cluster)
 		r.reconcileMetrics(ctx, cluster)
 
+		// Always update the Ready condition with the summary of the Cluster conditions.
+		conditions.SetSummary(cluster,
+			conditions.WithConditions(
+				clusterv1.ControlPlaneReadyCondition,
+				clusterv1.InfrastructureReadyCondition,
+			),
+		)
+
 		// Always attempt to Patch the Cluster object and status after each reconciliation.
 		if err := patchHelper.Patch(ctx, cluster); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
@@ -216,65 +226,59 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		logger.Error(err, "Failed to extract direct descendants")
 		return reconcile.Result{}, err
 	}
+	workerChildren, controlPlaneChildren := splitControlPlaneMachines(children)
 
-	if len(children) > 0 {
-		logger.Info("Cluster still has children - deleting them first", "count", len(children))
-
-		var errs []error
-
-		for _, child := range children {
-			accessor, err := meta.Accessor(child)
-			if err != nil {
-				logger.Error(err, "Couldn't create accessor", "type", fmt.Sprintf("%T", child))
-				continue
-			}
-
-			if !accessor.GetDeletionTimestamp().IsZero() {
-				// Don't handle deleted child
-				continue
-			}
-
-			gvk := child.GetObjectKind().GroupVersionKind().String()
-
-			logger.Info("Deleting child", "gvk", gvk, "name", accessor.GetName())
-			if err := r.Client.Delete(context.Background(), child); err != nil {
-				err = errors.Wrapf(err, "error deleting cluster %s/%s: failed to delete %s %s", cluster.Namespace, cluster.Name, gvk, accessor.GetName())
-				logger.Error(err, "Error deleting resource", "gvk", gvk, "name", accessor.GetName())
-				errs = append(errs, err)
-			}
+	// Delete the workers first: MachineDeployments, MachineSets, MachinePools and worker Machines.
+	if workerCount := descendants.workersLength(); workerCount > 0 {
+		logger.Info("Cluster still has workers - deleting them first", "direct", len(workerChildren), "indirect", workerCount-len(workerChildren))
+		deleted, err := r.deleteChildren(ctx, cluster, workerChildren)
+		if err != nil {
+			return ctrl.Result{}, err
 		}
 
-		if len(errs) > 0 {
-			return ctrl.Result{}, kerrors.NewAggregate(errs)
+		// Report the workers stage only when deletions are issued, not while waiting for them to complete.
+		if deleted > 0 {
+			r.recorder.Eventf(cluster, corev1.EventTypeNormal, "DeletingWorkers", "Waiting for %d worker objects to be deleted", workerCount)
 		}
+		// Requeue so we can check the next time to see if there are still any workers left.
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
 	}
 
-	if descendantCount := descendants.length(); descendantCount > 0 {
-		indirect := descendantCount - len(children)
-		logger.Info("Cluster still has descendants - need to requeue", "direct", len(children), "indirect", indirect)
-		// Requeue so we can check the next time to see if there are still any descendants left.
+	// Then delete the control plane, both the object referenced by the Cluster and the control plane Machines.
+	controlPlaneDeleted := true
+	if cluster.Spec.ControlPlaneRef != nil {
+		controlPlaneDeleted, err = r.deleteExternal(ctx, cluster, cluster.Spec.ControlPlaneRef)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if _, err := r.deleteChildren(ctx, cluster, controlPlaneChildren); err != nil {
+		return ctrl.Result{}, err
+	}
+	if machineCount := len(descendants.controlPlaneMachines.Items); !controlPlaneDeleted || machineCount > 0 {
+		logger.Info("Cluster still has a control plane - need to requeue", "control-plane-machines", machineCount)
+		if conditions.GetReason(cluster, clusterv1.ControlPlaneReadyCondition) != clusterv1.DeletingReason {
+			conditions.MarkFalse(cluster, clusterv1.ControlPlaneReadyCondition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo, "")
+			r.recorder.Eventf(cluster, corev1.EventTypeNormal, "DeletingControlPlane", "Waiting for the control plane and %d control plane Machines to be deleted", machineCount)
+		}
 		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
 	}
 
+	// Delete the infrastructure last.
 	if cluster.Spec.InfrastructureRef != nil {
-		obj, err := external.Get(ctx, r.Client, cluster.Spec.InfrastructureRef, cluster.Namespace)
-		switch {
-		case apierrors.IsNotFound(errors.Cause(err)):
-			// All good - the infra resource has been deleted
-		case err != nil:
-			return ctrl.Result{}, errors.Wrapf(err, "failed to get %s %q for Cluster %s/%s",
-				path.Join(cluster.Spec.InfrastructureRef.APIVersion, cluster.Spec.InfrastructureRef.Kind),
-				cluster.Spec.InfrastructureRef.Name, cluster.Namespace, cluster.Name)
-		default:
-			// Issue a deletion request for the infrastructure object.
-			// Once it's been deleted, the cluster will get processed again.
-			if err := r.Client.Delete(ctx, obj); err != nil {
-				return ctrl.Result{}, errors.Wrapf(err,
-					"failed to delete %v %q for Cluster %q in namespace %q",
-					obj.GroupVersionKind(), obj.GetName(), cluster.Name, cluster.Namespace)
+		deleted, err := r.deleteExternal(ctx, cluster, cluster.Spec.InfrastructureRef)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !deleted {
+			logger.Info("Cluster still has the infrastructure - need to requeue")
+			if conditions.GetReason(cluster, clusterv1.InfrastructureReadyCondition) != clusterv1.DeletingReason {
+				conditions.MarkFalse(cluster, clusterv1.InfrastructureReadyCondition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo, "")
+				r.recorder.Eventf(cluster, corev1.EventTypeNormal, "DeletingInfrastructure", "Waiting for the infrastructure to be deleted")
 			}
 
-			// Return here so we don't remove the finalizer yet.
+			// Return here so we don't remove the finalizer yet; once the infrastructure has been deleted,
+			// the cluster will get processed again.
 			return ctrl.Result{}, nil
 		}
 	}
@@ -283,22 +287,79 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	return ctrl.Result{}, nil
 }
 
+// deleteChildren issues a deletion request for each of the given children of the Cluster that
+// is not already being deleted, and returns the number of requests issued.
+func (r *ClusterReconciler) deleteChildren(ctx context.Context, cluster *clusterv1.Cluster, children []runtime.Object) (int, error) {
+	logger := r.Log.WithValues("cluster", cluster.Name, "namespace", cluster.Namespace)
+
+	var errs []error
+	deleted := 0
+	for _, child := range children {
+		accessor, err := meta.Accessor(child)
+		if err != nil {
+			logger.Error(err, "Couldn't create accessor", "type", fmt.Sprintf("%T", child))
+			continue
+		}
+
+		if !accessor.GetDeletionTimestamp().IsZero() {
+			// Don't handle deleted child
+			continue
+		}
+
+		gvk := child.GetObjectKind().GroupVersionKind().String()
+
+		logger.Info("Deleting child", "gvk", gvk, "name", accessor.GetName())
+		if err := r.Client.Delete(ctx, child); err != nil {
+			err = errors.Wrapf(err, "error deleting cluster %s/%s: failed to delete %s %s", cluster.Namespace, cluster.Name, gvk, accessor.GetName())
+			logger.Error(err, "Error deleting resource", "gvk", gvk, "name", accessor.GetName())
+			errs = append(errs, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, kerrors.NewAggregate(errs)
+}
+
+// deleteExternal issues a deletion request for the external object referenced by the Cluster, if it
+// still exists. It returns true once the object is gone.
+func (r *ClusterReconciler) deleteExternal(ctx context.Context, cluster *clusterv1.Cluster, ref *corev1.ObjectReference) (bool, error) {
+	obj, err := external.Get(ctx, r.Client, ref, cluster.Namespace)
+	switch {
+	case apierrors.IsNotFound(errors.Cause(err)):
+		// All good - the external object has been deleted
+		return true, nil
+	case err != nil:
+		return false, errors.Wrapf(err, "failed to get %s %q for Cluster %s/%s",
+			path.Join(ref.APIVersion, ref.Kind), ref.Name, cluster.Namespace, cluster.Name)
+	}
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		if err := r.Client.Delete(ctx, obj); err != nil {
+			return false, errors.Wrapf(err,
+				"failed to delete %v %q for Cluster %q in namespace %q",
+				obj.GroupVersionKind(), obj.GetName(), cluster.Name, cluster.Namespace)
+		}
+	}
+	return false, nil
+}
+
 type clusterDescendants struct {
 	machineDeployments   clusterv1.MachineDeploymentList
 	machineSets          clusterv1.MachineSetList
+	machinePools         clusterv1.MachinePoolList
 	controlPlaneMachines clusterv1.MachineList
 	workerMachines       clusterv1.MachineList
 }
 
-// length returns the number of descendants
-func (c *clusterDescendants) length() int {
+// workersLength returns the number of descendants that must be deleted before the control plane.
+func (c *clusterDescendants) workersLength() int {
 	return len(c.machineDeployments.Items) +
 		len(c.machineSets.Items) +
-		len(c.controlPlaneMachines.Items) +
+		len(c.machinePools.Items) +
 		len(c.workerMachines.Items)
 }
 
-// listDescendants returns a list of all MachineDeployments, MachineSets, and Machines for the cluster.
+// listDescendants returns a list of all MachineDeployments, MachineSets, MachinePools and Machines for the cluster.
 func (r *ClusterReconciler) listDescendants(ctx context.Context, cluster *clusterv1.Cluster) (clusterDescendants, error) {
 	var descendants clusterDescendants
 
@@ -315,6 +376,10 @@ func (r *ClusterReconciler) listDescendants(ctx context.Context, cluster *cluste
 		return descendants, errors.Wrapf(err, "failed to list MachineSets for cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
 
+	if err := r.Client.List(ctx, &descendants.machinePools, listOptions...); err != nil {
+		return descendants, errors.Wrapf(err, "failed to list MachinePools for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
 	var machines clusterv1.MachineList
 	if err := r.Client.List(ctx, &machines, listOptions...); err != nil {
 		return descendants, errors.Wrapf(err, "failed to list Machines for cluster %s/%s", cluster.Namespace, cluster.Name)
@@ -348,6 +413,7 @@ func (c clusterDescendants) filterOwnedDescendants(cluster *clusterv1.Cluster) (
 	lists := []runtime.Object{
 		&c.machineDeployments,
 		&c.machineSets,
+		&c.machinePools,
 		&c.workerMachines,
 		&c.controlPlaneMachines,
 	}
@@ -360,6 +426,19 @@ func (c clusterDescendants) filterOwnedDescendants(cluster *clusterv1.Cluster) (
 	return ownedDescendants, nil
 }
 
+// splitControlPlaneMachines separates the control plane Machines from the other objects.
+func splitControlPlaneMachines(objs []runtime.Object) ([]runtime.Object, []runtime.Object) {
+	var others, controlPlaneMachines []runtime.Object
+	for _, o := range objs {
+		if m, ok := o.(*clusterv1.Machine); ok && util.IsControlPlaneMachine(m) {
+			controlPlaneMachines = append(controlPlaneMachines, o)
+			continue
+		}
+		others = append(others, o)
+	}
+	return others, controlPlaneMachines
+}
+
 // splitMachineList separates the machines running the control plane from other worker nodes.
 func splitMachineList(list *clusterv1.MachineList) (*clusterv1.MachineList, *clusterv1.MachineList) {
 	nodes := &clusterv1.MachineList{}
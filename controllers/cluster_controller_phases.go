@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
@@ -162,6 +163,13 @@ func (r *ClusterReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		return err
 	}
 	cluster.Status.InfrastructureReady = ready
+
+	// Report a summary of current status of the infrastructure object defined for this cluster.
+	conditions.SetMirror(cluster, clusterv1.InfrastructureReadyCondition,
+		conditions.UnstructuredGetter(infraConfig),
+		conditions.WithFallbackValue(ready, clusterv1.WaitingForInfrastructureFallbackReason, clusterv1.ConditionSeverityInfo, ""),
+	)
+
 	if !ready {
 		logger.V(3).Info("Infrastructure provider is not ready yet")
 		return nil
@@ -218,6 +226,12 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, cluster *
 	}
 	cluster.Status.ControlPlaneReady = ready
 
+	// Report a summary of current status of the control plane object defined for this cluster.
+	conditions.SetMirror(cluster, clusterv1.ControlPlaneReadyCondition,
+		conditions.UnstructuredGetter(controlPlaneConfig),
+		conditions.WithFallbackValue(ready, clusterv1.WaitingForControlPlaneFallbackReason, clusterv1.ConditionSeverityInfo, ""),
+	)
+
 	return nil
 }
 
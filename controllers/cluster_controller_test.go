@@ -25,9 +25,12 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,6 +41,7 @@ import (
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
 )
@@ -651,6 +655,32 @@ func (b *machineSetBuilder) build() clusterv1.MachineSet {
 	return b.ms
 }
 
+type machinePoolBuilder struct {
+	mp clusterv1.MachinePool
+}
+
+func newMachinePoolBuilder() *machinePoolBuilder {
+	return &machinePoolBuilder{}
+}
+
+func (b *machinePoolBuilder) named(name string) *machinePoolBuilder {
+	b.mp.Name = name
+	return b
+}
+
+func (b *machinePoolBuilder) ownedBy(c *clusterv1.Cluster) *machinePoolBuilder {
+	b.mp.OwnerReferences = append(b.mp.OwnerReferences, metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       c.Name,
+	})
+	return b
+}
+
+func (b *machinePoolBuilder) build() clusterv1.MachinePool {
+	return b.mp
+}
+
 type machineBuilder struct {
 	m clusterv1.Machine
 }
@@ -701,6 +731,9 @@ func TestFilterOwnedDescendants(t *testing.T) {
 	ms3NotOwnedByCluster := newMachineSetBuilder().named("ms3").build()
 	ms4OwnedByCluster := newMachineSetBuilder().named("ms4").ownedBy(&c).build()
 
+	mp1NotOwnedByCluster := newMachinePoolBuilder().named("mp1").build()
+	mp2OwnedByCluster := newMachinePoolBuilder().named("mp2").ownedBy(&c).build()
+
 	m1NotOwnedByCluster := newMachineBuilder().named("m1").build()
 	m2OwnedByCluster := newMachineBuilder().named("m2").ownedBy(&c).build()
 	m3ControlPlaneOwnedByCluster := newMachineBuilder().named("m3").ownedBy(&c).controlPlane().build()
@@ -725,6 +758,12 @@ func TestFilterOwnedDescendants(t *testing.T) {
 				ms4OwnedByCluster,
 			},
 		},
+		machinePools: clusterv1.MachinePoolList{
+			Items: []clusterv1.MachinePool{
+				mp1NotOwnedByCluster,
+				mp2OwnedByCluster,
+			},
+		},
 		controlPlaneMachines: clusterv1.MachineList{
 			Items: []clusterv1.Machine{
 				m3ControlPlaneOwnedByCluster,
@@ -749,6 +788,7 @@ func TestFilterOwnedDescendants(t *testing.T) {
 		&md4OwnedByCluster,
 		&ms2OwnedByCluster,
 		&ms4OwnedByCluster,
+		&mp2OwnedByCluster,
 		&m2OwnedByCluster,
 		&m5OwnedByCluster,
 		&m3ControlPlaneOwnedByCluster,
@@ -757,3 +797,175 @@ func TestFilterOwnedDescendants(t *testing.T) {
 
 	g.Expect(actual).To(Equal(expected))
 }
+
+func TestClusterReconcilerReconcileDelete(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-cluster",
+			Namespace:  "default",
+			Finalizers: []string{clusterv1.ClusterFinalizer},
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef: &corev1.ObjectReference{
+				APIVersion: "controlplane.cluster.x-k8s.io/v1alpha3",
+				Kind:       "ControlPlane",
+				Name:       "test-control-plane",
+			},
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+				Kind:       "InfrastructureCluster",
+				Name:       "test-infra",
+			},
+		},
+	}
+
+	controlPlane := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "ControlPlane",
+			"apiVersion": "controlplane.cluster.x-k8s.io/v1alpha3",
+			"metadata": map[string]interface{}{
+				"name":      "test-control-plane",
+				"namespace": "default",
+			},
+		},
+	}
+
+	infraCluster := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureCluster",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+			"metadata": map[string]interface{}{
+				"name":      "test-infra",
+				"namespace": "default",
+			},
+		},
+	}
+
+	labels := map[string]string{clusterv1.ClusterLabelName: cluster.Name}
+
+	machinePool := newMachinePoolBuilder().named("test-pool").ownedBy(cluster).build()
+	machinePool.Namespace = "default"
+	machinePool.Labels = labels
+
+	deletingMachinePool := machinePool.DeepCopy()
+	deletionTimestamp := metav1.Now()
+	deletingMachinePool.DeletionTimestamp = &deletionTimestamp
+	deletingMachinePool.Finalizers = []string{"test"}
+
+	controlPlaneMachine := newMachineBuilder().named("test-cp-machine").ownedBy(cluster).controlPlane().build()
+	controlPlaneMachine.Namespace = "default"
+	controlPlaneMachine.Labels[clusterv1.ClusterLabelName] = cluster.Name
+
+	tests := []struct {
+		name                    string
+		objs                    []runtime.Object
+		deletingConditions      []clusterv1.ConditionType
+		expectRequeue           bool
+		expectEvent             string
+		expectDeleted           []runtime.Object
+		expectExisting          []runtime.Object
+		expectConditionNotReady clusterv1.ConditionType
+		expectFinalizerRemoved  bool
+	}{
+		{
+			name:           "deletes the workers before the control plane",
+			objs:           []runtime.Object{machinePool.DeepCopy(), controlPlaneMachine.DeepCopy(), controlPlane.DeepCopy(), infraCluster.DeepCopy()},
+			expectRequeue:  true,
+			expectEvent:    "DeletingWorkers",
+			expectDeleted:  []runtime.Object{&clusterv1.MachinePool{ObjectMeta: machinePool.ObjectMeta}},
+			expectExisting: []runtime.Object{&clusterv1.Machine{ObjectMeta: controlPlaneMachine.ObjectMeta}, controlPlane.DeepCopy(), infraCluster.DeepCopy()},
+		},
+		{
+			name:           "doesn't report the workers again while waiting for their deletion",
+			objs:           []runtime.Object{deletingMachinePool.DeepCopy(), controlPlane.DeepCopy(), infraCluster.DeepCopy()},
+			expectRequeue:  true,
+			expectExisting: []runtime.Object{controlPlane.DeepCopy(), infraCluster.DeepCopy()},
+		},
+		{
+			name:                    "deletes the control plane before the infrastructure",
+			objs:                    []runtime.Object{controlPlaneMachine.DeepCopy(), controlPlane.DeepCopy(), infraCluster.DeepCopy()},
+			expectRequeue:           true,
+			expectEvent:             "DeletingControlPlane",
+			expectDeleted:           []runtime.Object{&clusterv1.Machine{ObjectMeta: controlPlaneMachine.ObjectMeta}, controlPlane.DeepCopy()},
+			expectExisting:          []runtime.Object{infraCluster.DeepCopy()},
+			expectConditionNotReady: clusterv1.ControlPlaneReadyCondition,
+		},
+		{
+			name:                    "doesn't report the control plane again while waiting for its deletion",
+			objs:                    []runtime.Object{controlPlane.DeepCopy(), infraCluster.DeepCopy()},
+			deletingConditions:      []clusterv1.ConditionType{clusterv1.ControlPlaneReadyCondition},
+			expectRequeue:           true,
+			expectExisting:          []runtime.Object{infraCluster.DeepCopy()},
+			expectConditionNotReady: clusterv1.ControlPlaneReadyCondition,
+		},
+		{
+			name:                    "deletes the infrastructure last",
+			objs:                    []runtime.Object{infraCluster.DeepCopy()},
+			expectEvent:             "DeletingInfrastructure",
+			expectDeleted:           []runtime.Object{infraCluster.DeepCopy()},
+			expectConditionNotReady: clusterv1.InfrastructureReadyCondition,
+		},
+		{
+			name:                    "doesn't report the infrastructure again while waiting for its deletion",
+			objs:                    []runtime.Object{infraCluster.DeepCopy()},
+			deletingConditions:      []clusterv1.ConditionType{clusterv1.ControlPlaneReadyCondition, clusterv1.InfrastructureReadyCondition},
+			expectConditionNotReady: clusterv1.InfrastructureReadyCondition,
+		},
+		{
+			name:                   "removes the finalizer once everything is gone",
+			expectFinalizerRemoved: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := cluster.DeepCopy()
+			for _, condition := range tt.deletingConditions {
+				conditions.MarkFalse(c, condition, clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo, "")
+			}
+			recorder := record.NewFakeRecorder(32)
+			r := &ClusterReconciler{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, append(tt.objs, c)...),
+				Log:      log.Log,
+				recorder: recorder,
+			}
+
+			res, err := r.reconcileDelete(ctx, c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(res.RequeueAfter > 0).To(Equal(tt.expectRequeue))
+
+			if tt.expectEvent != "" {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring(tt.expectEvent)))
+			} else {
+				g.Expect(recorder.Events).NotTo(Receive())
+			}
+
+			for _, obj := range tt.expectDeleted {
+				key, err := client.ObjectKeyFromObject(obj)
+				g.Expect(err).NotTo(HaveOccurred())
+				err = r.Client.Get(ctx, key, obj)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected %s to be deleted", key)
+			}
+			for _, obj := range tt.expectExisting {
+				key, err := client.ObjectKeyFromObject(obj)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(r.Client.Get(ctx, key, obj)).To(Succeed())
+			}
+
+			if tt.expectConditionNotReady != "" {
+				g.Expect(conditions.IsFalse(c, tt.expectConditionNotReady)).To(BeTrue())
+				g.Expect(conditions.GetReason(c, tt.expectConditionNotReady)).To(Equal(clusterv1.DeletingReason))
+			}
+			if tt.expectFinalizerRemoved {
+				g.Expect(c.Finalizers).NotTo(ContainElement(clusterv1.ClusterFinalizer))
+			} else {
+				g.Expect(c.Finalizers).To(ContainElement(clusterv1.ClusterFinalizer))
+			}
+		})
+	}
+}
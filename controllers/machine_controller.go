@@ -19,6 +19,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -38,14 +41,18 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/metrics"
+	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	kubedrain "sigs.k8s.io/cluster-api/third_party/kubernetes-drain"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var (
@@ -65,11 +72,32 @@ type MachineReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
+	// Tracker, if set, provides cached clients for the workload clusters and allows
+	// to watch their Nodes.
+	Tracker *remote.ClusterCacheTracker
+
+	// NodeDrainOptions configures how Nodes are drained before deleting their Machines.
+	NodeDrainOptions NodeDrainOptions
+
 	config           *rest.Config
 	controller       controller.Controller
 	recorder         record.EventRecorder
 	externalWatchers sync.Map
 	scheme           *runtime.Scheme
+	remoteClient     func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
+}
+
+// NodeDrainOptions configures how the MachineReconciler drains the Node of a Machine being deleted.
+type NodeDrainOptions struct {
+	// DisableEviction deletes the pods instead of evicting them, so PodDisruptionBudgets are not honored.
+	DisableEviction bool
+
+	// SkipPodSelector is a label selector; the pods matching it are left on the Node.
+	SkipPodSelector string
+
+	// GracePeriodSeconds is the period of time given to each pod to terminate gracefully.
+	// If nil or negative, the termination grace period of the pod is used.
+	GracePeriodSeconds *int
 }
 
 func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -86,6 +114,12 @@ func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 	r.recorder = mgr.GetEventRecorderFor("machine-controller")
 	r.config = mgr.GetConfig()
 	r.scheme = mgr.GetScheme()
+	if r.remoteClient == nil {
+		r.remoteClient = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClient = r.Tracker.ClusterClient
+		}
+	}
 	return nil
 }
 
@@ -128,6 +162,15 @@ func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 		r.reconcilePhase(ctx, m)
 		r.reconcileMetrics(ctx, m)
 
+		// Always update the Ready condition with the summary of the Machine conditions.
+		conditions.SetSummary(m,
+			conditions.WithConditions(
+				clusterv1.BootstrapReadyCondition,
+				clusterv1.InfrastructureReadyCondition,
+				clusterv1.NodeHealthyCondition,
+			),
+		)
+
 		// Always attempt to patch the object and status after each reconciliation.
 		if err := patchHelper.Patch(ctx, m); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
@@ -168,6 +211,12 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 		m.Finalizers = append(m.Finalizers, clusterv1.MachineFinalizer)
 	}
 
+	// Watch the Nodes of the workload cluster, so the Machine is reconciled as soon as its Node changes.
+	if err := r.watchClusterNodes(ctx, cluster); err != nil {
+		logger.Error(err, "Failed to watch the Nodes of the workload cluster")
+		return ctrl.Result{}, err
+	}
+
 	// Call the inner reconciliation methods.
 	reconciliationErrors := []error{
 		r.reconcileBootstrap(ctx, m),
@@ -216,6 +265,13 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	logger := r.Log.WithValues("machine", m.Name, "namespace", m.Namespace)
 	logger = logger.WithValues("cluster", cluster.Name)
 
+	// Wait for all the pre-drain hooks to be removed before draining the node.
+	if r.waitForDeleteHooks(m, clusterv1.PreDrainDeleteHookAnnotationPrefix, clusterv1.PreDrainDeleteHookSucceededCondition) {
+		logger.Info("Waiting for pre-drain hooks to complete")
+		return ctrl.Result{}, nil
+	}
+
+	isDeleteNodeAllowed := false
 	if err := r.isDeleteNodeAllowed(ctx, m); err != nil {
 		switch err {
 		case errNilNodeRef:
@@ -227,15 +283,23 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			return ctrl.Result{}, err
 		}
 	} else {
+		isDeleteNodeAllowed = true
+
 		// Drain node before deletion
 		if _, exists := m.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; !exists {
-			logger.Info("Draining node", "node", m.Status.NodeRef.Name)
-			if err := r.drainNode(cluster, m.Status.NodeRef.Name, m.Name); err != nil {
-				r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+			if err := r.reconcileDrainNode(cluster, m); err != nil {
 				return ctrl.Result{}, err
 			}
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
 		}
+	}
+
+	// Wait for all the pre-terminate hooks to be removed before deleting the node and the infrastructure.
+	if r.waitForDeleteHooks(m, clusterv1.PreTerminateDeleteHookAnnotationPrefix, clusterv1.PreTerminateDeleteHookSucceededCondition) {
+		logger.Info("Waiting for pre-terminate hooks to complete")
+		return ctrl.Result{}, nil
+	}
+
+	if isDeleteNodeAllowed {
 		logger.Info("Deleting node", "node", m.Status.NodeRef.Name)
 
 		var deleteNodeErr error
@@ -258,9 +322,41 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	}
 
 	m.ObjectMeta.Finalizers = util.Filter(m.ObjectMeta.Finalizers, clusterv1.MachineFinalizer)
+	r.deleteNodeDrainMetrics(m)
 	return ctrl.Result{}, nil
 }
 
+// waitForDeleteHooks returns true if the Machine has annotations with the given deletion lifecycle hook
+// prefix, reporting the pending hooks in the given condition and in an event; the condition is set to true
+// once all the hooks have been removed by their owners.
+func (r *MachineReconciler) waitForDeleteHooks(m *clusterv1.Machine, prefix string, conditionType clusterv1.ConditionType) bool {
+	hooks := getDeleteHooks(m, prefix)
+	if len(hooks) == 0 {
+		conditions.MarkTrue(m, conditionType)
+		return false
+	}
+
+	message := fmt.Sprintf("Waiting for hooks %s", strings.Join(hooks, ", "))
+	if !conditions.IsFalse(m, conditionType) || conditions.GetMessage(m, conditionType) != message {
+		r.recorder.Eventf(m, corev1.EventTypeNormal, "WaitingForDeleteHooks", "%s: %s", conditionType, message)
+	}
+	conditions.MarkFalse(m, conditionType, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, message)
+	return true
+}
+
+// getDeleteHooks returns the sorted names of the annotations of a Machine with the given deletion
+// lifecycle hook prefix.
+func getDeleteHooks(m *clusterv1.Machine, prefix string) []string {
+	var hooks []string
+	for key := range m.Annotations {
+		if strings.HasPrefix(key, prefix+"/") {
+			hooks = append(hooks, key)
+		}
+	}
+	sort.Strings(hooks)
+	return hooks
+}
+
 // isDeleteNodeAllowed returns nil only if the Machine's NodeRef is not nil
 // and if the Machine is not the last control plane node in the cluster.
 func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, machine *clusterv1.Machine) error {
@@ -293,6 +389,76 @@ func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, machine *cl
 	}
 }
 
+// reconcileDrainNode drains the node of a Machine being deleted, recording the progress in the
+// DrainingSucceeded condition, in events and in metrics. Once the NodeDrainTimeout of the Machine is
+// exceeded, the drain is given up and the deletion proceeds.
+func (r *MachineReconciler) reconcileDrainNode(cluster *clusterv1.Cluster, m *clusterv1.Machine) error {
+	logger := r.Log.WithValues("machine", m.Name, "namespace", m.Namespace, "cluster", cluster.Name, "node", m.Status.NodeRef.Name)
+
+	if m.Status.NodeDrainStartTime == nil {
+		now := metav1.Now()
+		m.Status.NodeDrainStartTime = &now
+		conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
+		r.recorder.Eventf(m, corev1.EventTypeNormal, "DrainingNode", "draining Machine's node %q", m.Status.NodeRef.Name)
+	}
+
+	// The drain was already given up during a previous reconciliation.
+	if conditions.GetReason(m, clusterv1.DrainingSucceededCondition) == clusterv1.DrainingTimeoutExceededReason {
+		return nil
+	}
+
+	if isNodeDrainTimeoutExceeded(m) {
+		logger.Info("Node drain timeout exceeded, proceeding with the deletion", "timeout", m.Spec.NodeDrainTimeout.Duration.String())
+		conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingTimeoutExceededReason, clusterv1.ConditionSeverityWarning,
+			"Node was not drained within %s", m.Spec.NodeDrainTimeout.Duration)
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "NodeDrainTimeoutExceeded", "Machine's node %q was not drained within %s, proceeding with the deletion",
+			m.Status.NodeRef.Name, m.Spec.NodeDrainTimeout.Duration)
+		r.recordNodeDrainMetrics(m, "timeout")
+		return nil
+	}
+
+	logger.Info("Draining node")
+	if err := r.drainNode(cluster, m.Status.NodeRef.Name, m.Name); err != nil {
+		conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+		metrics.MachineNodeDrainAttempts.WithLabelValues(m.Name, m.Namespace, m.Spec.ClusterName, "failed").Inc()
+		return err
+	}
+
+	if !conditions.IsTrue(m, clusterv1.DrainingSucceededCondition) {
+		conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
+		r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+		r.recordNodeDrainMetrics(m, "succeeded")
+	}
+	return nil
+}
+
+// recordNodeDrainMetrics records the final result of the drain of the node of a Machine,
+// together with the time spent draining it.
+func (r *MachineReconciler) recordNodeDrainMetrics(m *clusterv1.Machine, result string) {
+	metrics.MachineNodeDrainAttempts.WithLabelValues(m.Name, m.Namespace, m.Spec.ClusterName, result).Inc()
+	if m.Status.NodeDrainStartTime != nil {
+		metrics.MachineNodeDrainDuration.WithLabelValues(m.Name, m.Namespace, m.Spec.ClusterName).Set(time.Since(m.Status.NodeDrainStartTime.Time).Seconds())
+	}
+}
+
+// deleteNodeDrainMetrics deletes the node drain metrics of a Machine, so that they don't outlive it.
+func (r *MachineReconciler) deleteNodeDrainMetrics(m *clusterv1.Machine) {
+	for _, result := range []string{"succeeded", "failed", "timeout"} {
+		metrics.MachineNodeDrainAttempts.DeleteLabelValues(m.Name, m.Namespace, m.Spec.ClusterName, result)
+	}
+	metrics.MachineNodeDrainDuration.DeleteLabelValues(m.Name, m.Namespace, m.Spec.ClusterName)
+}
+
+// isNodeDrainTimeoutExceeded returns true if the Machine has a NodeDrainTimeout and the drain
+// of its node started longer than NodeDrainTimeout ago.
+func isNodeDrainTimeoutExceeded(m *clusterv1.Machine) bool {
+	if m.Spec.NodeDrainTimeout == nil || m.Spec.NodeDrainTimeout.Duration <= 0 || m.Status.NodeDrainStartTime == nil {
+		return false
+	}
+	return time.Since(m.Status.NodeDrainStartTime.Time) > m.Spec.NodeDrainTimeout.Duration
+}
+
 func (r *MachineReconciler) drainNode(cluster *clusterv1.Cluster, nodeName string, machineName string) error {
 	logger := r.Log.WithValues("machine", machineName, "node", nodeName, "cluster", cluster.Name, "namespace", cluster.Namespace)
 	var kubeClient kubernetes.Interface
@@ -326,12 +492,19 @@ func (r *MachineReconciler) drainNode(cluster *clusterv1.Cluster, nodeName strin
 		return errors.Errorf("unable to get node %q: %v", nodeName, err)
 	}
 
+	gracePeriodSeconds := -1
+	if r.NodeDrainOptions.GracePeriodSeconds != nil {
+		gracePeriodSeconds = *r.NodeDrainOptions.GracePeriodSeconds
+	}
+
 	drainer := &kubedrain.Helper{
 		Client:              kubeClient,
 		Force:               true,
 		IgnoreAllDaemonSets: true,
 		DeleteLocalData:     true,
-		GracePeriodSeconds:  -1,
+		GracePeriodSeconds:  gracePeriodSeconds,
+		SkipPodSelector:     r.NodeDrainOptions.SkipPodSelector,
+		DisableEviction:     r.NodeDrainOptions.DisableEviction,
 		// If a pod is not evicted in 20 seconds, retry the eviction next time the
 		// machine gets reconciled again (to allow other machines to be reconciled).
 		Timeout: 20 * time.Second,
@@ -368,7 +541,7 @@ func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.C
 	logger := r.Log.WithValues("machine", name, "cluster", cluster.Name, "namespace", cluster.Namespace)
 
 	// Create a remote client to delete the node
-	c, err := remote.NewClusterClient(r.Client, cluster, r.scheme)
+	c, err := r.remoteClient(r.Client, cluster, r.scheme)
 	if err != nil {
 		logger.Error(err, "Error creating a remote client for cluster while deleting Machine, won't retry")
 		return nil
@@ -427,6 +600,67 @@ func (r *MachineReconciler) shouldAdopt(m *clusterv1.Machine) bool {
 	return !util.HasOwner(m.OwnerReferences, clusterv1.GroupVersion.String(), []string{"MachineSet", "Cluster"})
 }
 
+// watchClusterNodes starts watching the Nodes of the workload cluster through the cluster cache tracker,
+// so that Machines are reconciled as soon as their Nodes change.
+func (r *MachineReconciler) watchClusterNodes(ctx context.Context, cluster *clusterv1.Cluster) error {
+	// Nodes can only be watched when running in a manager with a cluster cache tracker.
+	if r.controller == nil || r.Tracker == nil {
+		return nil
+	}
+
+	// There are no Nodes to watch before the control plane is initialized.
+	if !cluster.Status.ControlPlaneInitialized {
+		return nil
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}
+	return r.Tracker.Watch(ctx, remote.WatchInput{
+		Name:         "machine-watchNodes",
+		Cluster:      key,
+		Watcher:      r.controller,
+		Kind:         &corev1.Node{},
+		EventHandler: &handler.EnqueueRequestsFromMapFunc{ToRequests: r.nodeToMachine(key)},
+	})
+}
+
+// nodeToMachine returns a function mapping a Node of the given workload cluster to the Machine hosting it,
+// matching either the NodeRef or the ProviderID of the Machine.
+func (r *MachineReconciler) nodeToMachine(cluster client.ObjectKey) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		node, ok := o.Object.(*corev1.Node)
+		if !ok {
+			r.Log.Error(errors.Errorf("expected a Node but got a %T", o.Object), "failed to get Machine for Node")
+			return nil
+		}
+
+		machineList := &clusterv1.MachineList{}
+		if err := r.Client.List(
+			context.Background(),
+			machineList,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+		); err != nil {
+			r.Log.Error(err, "Unable to list Machines", "cluster", cluster.Name, "namespace", cluster.Namespace)
+			return nil
+		}
+
+		nodeProviderID, _ := noderefutil.NewProviderID(node.Spec.ProviderID)
+		for i := range machineList.Items {
+			m := &machineList.Items[i]
+			if m.Status.NodeRef != nil && m.Status.NodeRef.Name == node.Name {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: m.Namespace, Name: m.Name}}}
+			}
+			if nodeProviderID == nil || m.Spec.ProviderID == nil {
+				continue
+			}
+			if machineProviderID, err := noderefutil.NewProviderID(*m.Spec.ProviderID); err == nil && machineProviderID.Equals(nodeProviderID) {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: m.Namespace, Name: m.Name}}}
+			}
+		}
+		return nil
+	}
+}
+
 // writer implements io.Writer interface as a pass-through for klog.
 type writer struct {
 	logFunc func(args ...interface{})
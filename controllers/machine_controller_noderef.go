@@ -22,11 +22,12 @@ import (
 
 	"github.com/pkg/errors"
 	apicorev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
-	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -34,18 +35,13 @@ var (
 	ErrNodeNotFound = errors.New("cannot find node with matching ProviderID")
 )
 
-func (r *MachineReconciler) reconcileNodeRef(_ context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
+func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
 	logger := r.Log.WithValues("machine", machine.Name, "namespace", machine.Namespace)
 	// Check that the Machine hasn't been deleted or in the process.
 	if !machine.DeletionTimestamp.IsZero() {
 		return nil
 	}
 
-	// Check that the Machine doesn't already have a NodeRef.
-	if machine.Status.NodeRef != nil {
-		return nil
-	}
-
 	// Check that Cluster isn't nil.
 	if cluster == nil {
 		logger.V(2).Info("Machine doesn't have a linked cluster, won't assign NodeRef")
@@ -54,9 +50,24 @@ func (r *MachineReconciler) reconcileNodeRef(_ context.Context, cluster *cluster
 
 	logger = logger.WithValues("cluster", cluster.Name)
 
+	// If the Machine already has a NodeRef, only refresh the health of its Node.
+	if machine.Status.NodeRef != nil {
+		// The Node can't be read before the control plane is initialized.
+		if !cluster.Status.ControlPlaneInitialized {
+			return nil
+		}
+
+		clusterClient, err := r.remoteClient(r.Client, cluster, r.scheme)
+		if err != nil {
+			return err
+		}
+		return r.reconcileNodeHealth(ctx, clusterClient, machine)
+	}
+
 	// Check that the Machine has a valid ProviderID.
 	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
 		logger.Info("Machine doesn't have a valid ProviderID yet")
+		conditions.MarkFalse(machine, clusterv1.NodeHealthyCondition, clusterv1.WaitingForNodeRefReason, clusterv1.ConditionSeverityInfo, "")
 		return nil
 	}
 
@@ -65,7 +76,7 @@ func (r *MachineReconciler) reconcileNodeRef(_ context.Context, cluster *cluster
 		return err
 	}
 
-	clusterClient, err := remote.NewClusterClient(r.Client, cluster, r.scheme)
+	clusterClient, err := r.remoteClient(r.Client, cluster, r.scheme)
 	if err != nil {
 		return err
 	}
@@ -74,6 +85,7 @@ func (r *MachineReconciler) reconcileNodeRef(_ context.Context, cluster *cluster
 	nodeRef, err := r.getNodeReference(clusterClient, providerID)
 	if err != nil {
 		if err == ErrNodeNotFound {
+			conditions.MarkFalse(machine, clusterv1.NodeHealthyCondition, clusterv1.WaitingForNodeRefReason, clusterv1.ConditionSeverityInfo, "")
 			return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 10 * time.Second},
 				"cannot assign NodeRef to Machine %q in namespace %q, no matching Node", machine.Name, machine.Namespace)
 		}
@@ -86,9 +98,41 @@ func (r *MachineReconciler) reconcileNodeRef(_ context.Context, cluster *cluster
 	machine.Status.NodeRef = nodeRef
 	logger.Info("Set Machine's NodeRef", "noderef", machine.Status.NodeRef.Name)
 	r.recorder.Event(machine, apicorev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
+
+	// Report the health of the Node.
+	return r.reconcileNodeHealth(ctx, clusterClient, machine)
+}
+
+// reconcileNodeHealth reads the Node referenced by the Machine from the workload cluster and
+// updates the NodeHealthy condition of the Machine accordingly.
+func (r *MachineReconciler) reconcileNodeHealth(ctx context.Context, clusterClient client.Client, machine *clusterv1.Machine) error {
+	node := &apicorev1.Node{}
+	if err := clusterClient.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(machine, clusterv1.NodeHealthyCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityError, "")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get Node %q for Machine %q in namespace %q", machine.Status.NodeRef.Name, machine.Name, machine.Namespace)
+	}
+	setNodeHealthyCondition(machine, node)
 	return nil
 }
 
+// setNodeHealthyCondition sets the NodeHealthy condition of a Machine according to the
+// Ready condition of its Node.
+func setNodeHealthyCondition(machine *clusterv1.Machine, node *apicorev1.Node) {
+	if noderefutil.IsNodeReady(node) {
+		conditions.MarkTrue(machine, clusterv1.NodeHealthyCondition)
+		return
+	}
+
+	message := "Node is not ready"
+	if readyCondition := noderefutil.GetReadyCondition(&node.Status); readyCondition != nil && readyCondition.Message != "" {
+		message = readyCondition.Message
+	}
+	conditions.MarkFalse(machine, clusterv1.NodeHealthyCondition, clusterv1.NodeConditionsFailedReason, clusterv1.ConditionSeverityWarning, message)
+}
+
 func (r *MachineReconciler) getNodeReference(client client.Client, providerID *noderefutil.ProviderID) (*apicorev1.ObjectReference, error) {
 	logger := r.Log.WithValues("providerID", providerID)
 
@@ -25,11 +25,13 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestGetNodeReference(t *testing.T) {
@@ -125,3 +127,70 @@ func TestGetNodeReference(t *testing.T) {
 
 	}
 }
+
+func TestSetNodeHealthyCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	machine := &clusterv1.Machine{}
+	node := &corev1.Node{
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Message: "kubelet stopped posting node status"},
+			},
+		},
+	}
+
+	setNodeHealthyCondition(machine, node)
+	g.Expect(conditions.IsFalse(machine, clusterv1.NodeHealthyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machine, clusterv1.NodeHealthyCondition)).To(Equal(clusterv1.NodeConditionsFailedReason))
+	g.Expect(conditions.GetMessage(machine, clusterv1.NodeHealthyCondition)).To(Equal("kubelet stopped posting node status"))
+
+	node.Status.Conditions[0].Status = corev1.ConditionTrue
+	setNodeHealthyCondition(machine, node)
+	g.Expect(conditions.IsTrue(machine, clusterv1.NodeHealthyCondition)).To(BeTrue())
+}
+
+func TestReconcileNodeRefRefreshesNodeHealth(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	remoteClient := fake.NewFakeClientWithScheme(scheme.Scheme, node)
+
+	r := &MachineReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme),
+		Log:      log.Log,
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error) {
+			return remoteClient, nil
+		},
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Status:     clusterv1.ClusterStatus{ControlPlaneInitialized: true},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "node-1"},
+		},
+	}
+
+	g.Expect(r.reconcileNodeRef(ctx, cluster, machine)).To(Succeed())
+	g.Expect(conditions.IsTrue(machine, clusterv1.NodeHealthyCondition)).To(BeTrue())
+
+	// The Node going away is reported on the Machine.
+	g.Expect(remoteClient.Delete(ctx, node)).To(Succeed())
+	g.Expect(r.reconcileNodeRef(ctx, cluster, machine)).To(Succeed())
+	g.Expect(conditions.IsFalse(machine, clusterv1.NodeHealthyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machine, clusterv1.NodeHealthyCondition)).To(Equal(clusterv1.NodeNotFoundReason))
+}
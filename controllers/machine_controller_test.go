@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -27,14 +28,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capimetrics "sigs.k8s.io/cluster-api/controllers/metrics"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestMachineFinalizer(t *testing.T) {
@@ -565,11 +570,24 @@ func TestRemoveMachineFinalizerAfterDeleteReconcile(t *testing.T) {
 		Client: fake.NewFakeClientWithScheme(scheme.Scheme, testCluster, m),
 		Log:    log.Log,
 	}
+	capimetrics.MachineNodeDrainAttempts.WithLabelValues(m.Name, m.Namespace, m.Spec.ClusterName, "failed").Inc()
+	capimetrics.MachineNodeDrainDuration.WithLabelValues(m.Name, m.Namespace, m.Spec.ClusterName).Set(1)
 	_, err := mr.Reconcile(reconcile.Request{NamespacedName: key})
 	g.Expect(err).ToNot(HaveOccurred())
 
 	g.Expect(mr.Client.Get(ctx, key, m)).To(Succeed())
 	g.Expect(m.ObjectMeta.Finalizers).To(Equal([]string{metav1.FinalizerDeleteDependents}))
+
+	// The node drain metrics of the Machine are deleted together with its finalizer.
+	mf, err := metrics.Registry.Gather()
+	g.Expect(err).ToNot(HaveOccurred())
+	for _, name := range []string{"capi_machine_node_drain_attempts_total", "capi_machine_node_drain_duration_seconds"} {
+		for _, metric := range getMetricFamily(mf, name).GetMetric() {
+			for _, l := range metric.GetLabel() {
+				g.Expect(l.GetName() == "machine" && l.GetValue() == m.Name).To(BeFalse())
+			}
+		}
+	}
 }
 
 func TestReconcileMetrics(t *testing.T) {
@@ -662,3 +680,240 @@ func TestReconcileMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeToMachine(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := client.ObjectKey{Namespace: "default", Name: "test-cluster"}
+	withNodeRef := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "with-noderef",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "node-1"},
+		},
+	}
+	withProviderID := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "with-providerid",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+		},
+		Spec: clusterv1.MachineSpec{
+			ProviderID: pointer.StringPtr("aws:///id-2"),
+		},
+	}
+	otherCluster := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-cluster",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "other-cluster"},
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "node-3"},
+		},
+	}
+
+	r := &MachineReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, withNodeRef, withProviderID, otherCluster),
+		Log:    log.Log,
+	}
+	mapper := r.nodeToMachine(cluster)
+
+	tests := []struct {
+		name     string
+		node     *corev1.Node
+		expected []reconcile.Request
+	}{
+		{
+			name: "maps a Node to the Machine referencing it",
+			node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			expected: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "with-noderef"}},
+			},
+		},
+		{
+			name: "maps a Node to the Machine with the same ProviderID",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+				Spec:       corev1.NodeSpec{ProviderID: "aws:///id-2"},
+			},
+			expected: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "with-providerid"}},
+			},
+		},
+		{
+			name: "ignores Machines of other clusters",
+			node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(mapper(handler.MapObject{Meta: tt.node, Object: tt.node})).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestIsNodeDrainTimeoutExceeded(t *testing.T) {
+	startTime := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+
+	tests := []struct {
+		name     string
+		machine  *clusterv1.Machine
+		expected bool
+	}{
+		{
+			name:     "no timeout",
+			machine:  &clusterv1.Machine{Status: clusterv1.MachineStatus{NodeDrainStartTime: &startTime}},
+			expected: false,
+		},
+		{
+			name: "zero timeout",
+			machine: &clusterv1.Machine{
+				Spec:   clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{}},
+				Status: clusterv1.MachineStatus{NodeDrainStartTime: &startTime},
+			},
+			expected: false,
+		},
+		{
+			name: "drain not started",
+			machine: &clusterv1.Machine{
+				Spec: clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{Duration: time.Minute}},
+			},
+			expected: false,
+		},
+		{
+			name: "timeout not exceeded",
+			machine: &clusterv1.Machine{
+				Spec:   clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{Duration: time.Hour}},
+				Status: clusterv1.MachineStatus{NodeDrainStartTime: &startTime},
+			},
+			expected: false,
+		},
+		{
+			name: "timeout exceeded",
+			machine: &clusterv1.Machine{
+				Spec:   clusterv1.MachineSpec{NodeDrainTimeout: &metav1.Duration{Duration: time.Minute}},
+				Status: clusterv1.MachineStatus{NodeDrainStartTime: &startTime},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(isNodeDrainTimeoutExceeded(tt.machine)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestReconcileDrainNodeTimeoutExceeded(t *testing.T) {
+	g := NewWithT(t)
+
+	startTime := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:      cluster.Name,
+			NodeDrainTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef:            &corev1.ObjectReference{Name: "node"},
+			NodeDrainStartTime: &startTime,
+		},
+	}
+
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme),
+		Log:      log.Log,
+		recorder: recorder,
+	}
+
+	// The drain is given up without contacting the workload cluster.
+	g.Expect(r.reconcileDrainNode(cluster, machine)).To(Succeed())
+	g.Expect(conditions.IsFalse(machine, clusterv1.DrainingSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machine, clusterv1.DrainingSucceededCondition)).To(Equal(clusterv1.DrainingTimeoutExceededReason))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("NodeDrainTimeoutExceeded")))
+
+	// The timeout is reported only once.
+	g.Expect(r.reconcileDrainNode(cluster, machine)).To(Succeed())
+	g.Expect(recorder.Events).NotTo(Receive())
+}
+
+func TestReconcileDeleteLifecycleHooks(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	dt := metav1.Now()
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+	}
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "delete-hooks",
+			Namespace:         "default",
+			Finalizers:        []string{clusterv1.MachineFinalizer},
+			DeletionTimestamp: &dt,
+			Annotations: map[string]string{
+				clusterv1.PreDrainDeleteHookAnnotationPrefix + "/lb-deregister":     "lb-controller",
+				clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/etcd-snapshot": "backup-controller",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+				Kind:       "InfrastructureConfig",
+				Name:       "infra-config1",
+			},
+			Bootstrap: clusterv1.Bootstrap{Data: pointer.StringPtr("data")},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, testCluster, m),
+		Log:      log.Log,
+		recorder: recorder,
+	}
+
+	// The deletion pauses until the pre-drain hooks are removed.
+	_, err := r.reconcileDelete(ctx, testCluster, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(m.Finalizers).To(ContainElement(clusterv1.MachineFinalizer))
+	g.Expect(conditions.IsFalse(m, clusterv1.PreDrainDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(m, clusterv1.PreDrainDeleteHookSucceededCondition)).To(Equal(clusterv1.WaitingExternalHookReason))
+	g.Expect(conditions.GetMessage(m, clusterv1.PreDrainDeleteHookSucceededCondition)).To(ContainSubstring("lb-deregister"))
+	g.Expect(conditions.Has(m, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(BeFalse())
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("WaitingForDeleteHooks")))
+
+	// Waiting on the same hooks does not emit further events.
+	_, err = r.reconcileDelete(ctx, testCluster, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).NotTo(Receive())
+
+	// Then it pauses until the pre-terminate hooks are removed.
+	delete(m.Annotations, clusterv1.PreDrainDeleteHookAnnotationPrefix+"/lb-deregister")
+	_, err = r.reconcileDelete(ctx, testCluster, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(m.Finalizers).To(ContainElement(clusterv1.MachineFinalizer))
+	g.Expect(conditions.IsTrue(m, clusterv1.PreDrainDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.IsFalse(m, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.GetMessage(m, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(ContainSubstring("etcd-snapshot"))
+
+	// Once all the hooks are removed, the deletion completes.
+	delete(m.Annotations, clusterv1.PreTerminateDeleteHookAnnotationPrefix+"/etcd-snapshot")
+	_, err = r.reconcileDelete(ctx, testCluster, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(conditions.IsTrue(m, clusterv1.PreTerminateDeleteHookSucceededCondition)).To(BeTrue())
+	g.Expect(m.Finalizers).NotTo(ContainElement(clusterv1.MachineFinalizer))
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -29,7 +30,9 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -110,6 +113,14 @@ func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result
 	}
 
 	defer func() {
+		// Always update the Ready condition with the summary of the MachineDeployment conditions.
+		conditions.SetSummary(deployment,
+			conditions.WithConditions(
+				clusterv1.MachineDeploymentAvailableCondition,
+				clusterv1.MachineDeploymentProgressingCondition,
+			),
+		)
+
 		// Always attempt to patch the object and status after each reconciliation.
 		if err := patchHelper.Patch(ctx, deployment); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
@@ -175,11 +186,26 @@ func (r *MachineDeploymentReconciler) reconcile(_ context.Context, cluster *clus
 		return ctrl.Result{}, r.sync(d, msList)
 	}
 
-	if d.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType {
-		return ctrl.Result{}, r.rolloutRolling(d, msList)
+	if _, ok := d.Annotations[mdutil.RollbackToAnnotation]; ok {
+		r.rollback(d, msList)
+		return ctrl.Result{}, nil
+	}
+
+	switch d.Spec.Strategy.Type {
+	case clusterv1.RollingUpdateMachineDeploymentStrategyType:
+		err = r.rolloutRolling(d, msList)
+	case clusterv1.OnDeleteMachineDeploymentStrategyType:
+		err = r.rolloutOnDelete(d, msList)
+	case clusterv1.RecreateMachineDeploymentStrategyType:
+		err = r.rolloutRecreate(d, msList)
+	default:
+		return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
+	return ctrl.Result{RequeueAfter: progressDeadlineRequeueAfter(d, time.Now())}, nil
 }
 
 // getMachineSetsForDeployment returns a list of MachineSets associated with a MachineDeployment.
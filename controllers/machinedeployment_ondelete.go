@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/utils/integer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+// rolloutOnDelete implements the logic for the OnDelete strategy: old machines are never deleted by the
+// controller during a rollout, the new machine set is scaled up as users delete the old machines.
+func (r *MachineDeploymentReconciler) rolloutOnDelete(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(d, msList, true)
+	if err != nil {
+		return err
+	}
+
+	// newMS can be nil in case there is already a MachineSet associated with this deployment,
+	// but there are only either changes in annotations or MinReadySeconds. Or in other words,
+	// this can be nil if there are changes, but no replacement of existing machines is needed.
+	if newMS == nil {
+		return nil
+	}
+
+	allMSs := append(oldMSs, newMS)
+
+	// Scale down, if machines were deleted.
+	if err := r.reconcileOldMachineSetsOnDelete(oldMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	// Scale up, if we can.
+	if err := r.reconcileNewMachineSet(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if mdutil.DeploymentComplete(d, &d.Status) {
+		if err := r.cleanupDeployment(oldMSs, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileOldMachineSetsOnDelete prevents the old machine sets from replacing the machines deleted by users,
+// and scales them down to the machines that are left. Machines exceeding the desired replicas, e.g. after the
+// deployment was scaled down, are removed from the oldest machine sets first.
+func (r *MachineDeploymentReconciler) reconcileOldMachineSetsOnDelete(oldMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+	if deployment.Spec.Replicas == nil {
+		return errors.Errorf("spec replicas for deployment %v is nil, this is unexpected", deployment.Name)
+	}
+
+	if newMS.Spec.Replicas == nil {
+		return errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", newMS.Name)
+	}
+
+	sort.Sort(mdutil.MachineSetsByCreationTimestamp(oldMSs))
+
+	// Calculate the size of each old machine set, once the machines deleted by users are taken into account;
+	// the status is used only if it reflects the latest spec of the machine set.
+	sizes := make([]int32, len(oldMSs))
+	excess := *(newMS.Spec.Replicas) - *(deployment.Spec.Replicas)
+	for i, oldMS := range oldMSs {
+		if oldMS.Spec.Replicas == nil {
+			return errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", oldMS.Name)
+		}
+
+		sizes[i] = *(oldMS.Spec.Replicas)
+		if oldMS.Status.ObservedGeneration >= oldMS.Generation && oldMS.Status.Replicas < sizes[i] {
+			sizes[i] = oldMS.Status.Replicas
+		}
+		excess += sizes[i]
+	}
+
+	for i, oldMS := range oldMSs {
+		if *(oldMS.Spec.Replicas) == 0 {
+			// Nothing left to scale down in this MachineSet.
+			continue
+		}
+
+		if excess > 0 {
+			scaleDownCount := integer.Int32Min(sizes[i], excess)
+			sizes[i] -= scaleDownCount
+			excess -= scaleDownCount
+		}
+
+		if err := r.disableMachineCreate(oldMS); err != nil {
+			return err
+		}
+
+		if err := r.scaleMachineSet(oldMS, sizes[i], deployment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// disableMachineCreate prevents the machine set from creating new machines.
+func (r *MachineDeploymentReconciler) disableMachineCreate(ms *clusterv1.MachineSet) error {
+	if ms.Annotations[clusterv1.DisableMachineCreateAnnotation] == "true" {
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(ms, r.Client)
+	if err != nil {
+		return err
+	}
+
+	if ms.Annotations == nil {
+		ms.Annotations = make(map[string]string)
+	}
+	ms.Annotations[clusterv1.DisableMachineCreateAnnotation] = "true"
+
+	return patchHelper.Patch(context.Background(), ms)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestReconcileOldMachineSetsOnDelete(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	newMachineSet := func(name string, age time.Duration, specReplicas, statusReplicas int32) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Replicas: pointer.Int32Ptr(specReplicas),
+			},
+			Status: clusterv1.MachineSetStatus{
+				Replicas: statusReplicas,
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		replicas       int32
+		oldMSs         []*clusterv1.MachineSet
+		newMS          *clusterv1.MachineSet
+		expectReplicas map[string]int32
+	}{
+		{
+			name:     "keeps old machine sets while no machine has been deleted",
+			replicas: 3,
+			oldMSs: []*clusterv1.MachineSet{
+				newMachineSet("old", 2*time.Hour, 3, 3),
+			},
+			newMS:          newMachineSet("new", time.Hour, 0, 0),
+			expectReplicas: map[string]int32{"old": 3},
+		},
+		{
+			name:     "scales old machine sets down to the machines left",
+			replicas: 3,
+			oldMSs: []*clusterv1.MachineSet{
+				newMachineSet("oldest", 3*time.Hour, 2, 1),
+				newMachineSet("old", 2*time.Hour, 1, 1),
+			},
+			newMS:          newMachineSet("new", time.Hour, 1, 1),
+			expectReplicas: map[string]int32{"oldest": 1, "old": 1},
+		},
+		{
+			name:     "removes excess machines from the oldest machine sets first",
+			replicas: 2,
+			oldMSs: []*clusterv1.MachineSet{
+				newMachineSet("old", 2*time.Hour, 2, 2),
+				newMachineSet("oldest", 3*time.Hour, 2, 2),
+			},
+			newMS:          newMachineSet("new", time.Hour, 0, 0),
+			expectReplicas: map[string]int32{"oldest": 0, "old": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "md",
+					Namespace: "default",
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(tt.replicas),
+					Strategy: &clusterv1.MachineDeploymentStrategy{
+						Type: clusterv1.OnDeleteMachineDeploymentStrategyType,
+					},
+				},
+			}
+
+			objs := []runtime.Object{tt.newMS.DeepCopy()}
+			for _, ms := range tt.oldMSs {
+				objs = append(objs, ms.DeepCopy())
+			}
+
+			r := &MachineDeploymentReconciler{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			g.Expect(r.reconcileOldMachineSetsOnDelete(tt.oldMSs, tt.newMS, d)).To(Succeed())
+
+			for name, replicas := range tt.expectReplicas {
+				ms := &clusterv1.MachineSet{}
+				g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, ms)).To(Succeed())
+				g.Expect(*ms.Spec.Replicas).To(Equal(replicas), "replicas of machine set %s", name)
+				g.Expect(ms.Annotations).To(HaveKeyWithValue(clusterv1.DisableMachineCreateAnnotation, "true"))
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// syncDeploymentProgress records the last time the MachineDeployment made progress and reports whether the
+// rollout exceeded Spec.ProgressDeadlineSeconds; d.Status must already contain the newly calculated status,
+// while oldStatus is the status observed at the beginning of the reconciliation.
+func syncDeploymentProgress(d *clusterv1.MachineDeployment, oldStatus *clusterv1.MachineDeploymentStatus, now time.Time) {
+	if !mdutil.HasProgressDeadline(d) {
+		conditions.Delete(d, clusterv1.MachineDeploymentProgressingCondition)
+		return
+	}
+
+	switch {
+	// A change to the spec, e.g. a new template or a different number of replicas, starts a new rollout;
+	// more Machines being updated, ready or available means the rollout is moving forward.
+	case d.Status.LastProgressTime == nil ||
+		oldStatus.ObservedGeneration < d.Generation ||
+		mdutil.DeploymentProgressing(oldStatus, &d.Status):
+		d.Status.LastProgressTime = &metav1.Time{Time: now}
+		conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+	case mdutil.DeploymentComplete(d, &d.Status):
+		conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+	case mdutil.DeploymentComplete(d, oldStatus):
+		// A complete MachineDeployment losing ready or available Machines, e.g. because a Machine failed,
+		// starts recovering from now on: the time it spent complete doesn't count towards the deadline.
+		d.Status.LastProgressTime = &metav1.Time{Time: now}
+		conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+	case d.Spec.Paused:
+		// A paused MachineDeployment is not expected to make progress; resuming it changes the spec,
+		// so it is given the whole deadline once the rollout continues.
+	case mdutil.DeploymentTimedOut(d, now):
+		conditions.MarkFalse(d, clusterv1.MachineDeploymentProgressingCondition, clusterv1.ProgressDeadlineExceededReason, clusterv1.ConditionSeverityError,
+			"MachineDeployment has not made progress for more than %ds", *d.Spec.ProgressDeadlineSeconds)
+	}
+
+	if conditions.GetReason(d, clusterv1.MachineDeploymentProgressingCondition) == clusterv1.ProgressDeadlineExceededReason {
+		d.Status.SetTypedPhase(clusterv1.MachineDeploymentPhaseFailed)
+	}
+}
+
+// progressDeadlineRequeueAfter returns how long to wait before checking again whether a rollout that is
+// still in progress exceeded its deadline; it returns zero if no check is required.
+func progressDeadlineRequeueAfter(d *clusterv1.MachineDeployment, now time.Time) time.Duration {
+	if !mdutil.HasProgressDeadline(d) || d.Spec.Paused || d.Status.LastProgressTime == nil ||
+		mdutil.DeploymentComplete(d, &d.Status) ||
+		!conditions.IsTrue(d, clusterv1.MachineDeploymentProgressingCondition) {
+		return 0
+	}
+
+	deadline := d.Status.LastProgressTime.Add(time.Duration(*d.Spec.ProgressDeadlineSeconds) * time.Second)
+	if after := deadline.Sub(now); after > 0 {
+		// Add a second to make sure the deadline is exceeded when the MachineDeployment is reconciled again.
+		return after + time.Second
+	}
+	return time.Second
+}
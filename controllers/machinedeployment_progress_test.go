@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestSyncDeploymentProgress(t *testing.T) {
+	now := time.Now()
+	lastProgressTime := metav1.NewTime(now.Add(-11 * time.Minute))
+
+	newDeployment := func(replicas, updated, available int32) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Generation: 1,
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				Replicas:                pointer.Int32Ptr(replicas),
+				ProgressDeadlineSeconds: pointer.Int32Ptr(600),
+			},
+			Status: clusterv1.MachineDeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           replicas,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  available,
+				LastProgressTime:   lastProgressTime.DeepCopy(),
+				Phase:              string(clusterv1.MachineDeploymentPhaseScalingUp),
+			},
+		}
+	}
+
+	tests := []struct {
+		name                   string
+		deployment             *clusterv1.MachineDeployment
+		oldStatus              func(d *clusterv1.MachineDeployment) clusterv1.MachineDeploymentStatus
+		expectProgressing      bool
+		expectLastProgressTime metav1.Time
+		expectPhase            clusterv1.MachineDeploymentPhase
+	}{
+		{
+			name:       "records progress when more machines become available",
+			deployment: newDeployment(3, 3, 2),
+			oldStatus: func(d *clusterv1.MachineDeployment) clusterv1.MachineDeploymentStatus {
+				status := d.Status
+				status.AvailableReplicas = 1
+				return status
+			},
+			expectProgressing:      true,
+			expectLastProgressTime: metav1.NewTime(now),
+			expectPhase:            clusterv1.MachineDeploymentPhaseScalingUp,
+		},
+		{
+			name: "records progress when the spec changes",
+			deployment: func() *clusterv1.MachineDeployment {
+				d := newDeployment(3, 3, 2)
+				d.Generation = 2
+				return d
+			}(),
+			expectProgressing:      true,
+			expectLastProgressTime: metav1.NewTime(now),
+			expectPhase:            clusterv1.MachineDeploymentPhaseScalingUp,
+		},
+		{
+			name:                   "does not time out a complete rollout",
+			deployment:             newDeployment(3, 3, 3),
+			expectProgressing:      true,
+			expectLastProgressTime: lastProgressTime,
+			expectPhase:            clusterv1.MachineDeploymentPhaseScalingUp,
+		},
+		{
+			name:       "restarts the deadline when a complete rollout loses an available machine",
+			deployment: newDeployment(3, 3, 2),
+			oldStatus: func(d *clusterv1.MachineDeployment) clusterv1.MachineDeploymentStatus {
+				status := d.Status
+				status.AvailableReplicas = 3
+				return status
+			},
+			expectProgressing:      true,
+			expectLastProgressTime: metav1.NewTime(now),
+			expectPhase:            clusterv1.MachineDeploymentPhaseScalingUp,
+		},
+		{
+			name: "does not time out a paused rollout",
+			deployment: func() *clusterv1.MachineDeployment {
+				d := newDeployment(3, 3, 2)
+				d.Spec.Paused = true
+				return d
+			}(),
+			expectProgressing:      true,
+			expectLastProgressTime: lastProgressTime,
+			expectPhase:            clusterv1.MachineDeploymentPhaseScalingUp,
+		},
+		{
+			name:                   "fails a rollout without progress within the deadline",
+			deployment:             newDeployment(3, 3, 2),
+			expectProgressing:      false,
+			expectLastProgressTime: lastProgressTime,
+			expectPhase:            clusterv1.MachineDeploymentPhaseFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			oldStatus := tt.deployment.Status
+			if tt.oldStatus != nil {
+				oldStatus = tt.oldStatus(tt.deployment)
+			}
+			if tt.deployment.Generation > oldStatus.ObservedGeneration {
+				tt.deployment.Status.ObservedGeneration = tt.deployment.Generation
+			}
+			conditions.MarkTrue(tt.deployment, clusterv1.MachineDeploymentProgressingCondition)
+
+			syncDeploymentProgress(tt.deployment, &oldStatus, now)
+
+			if tt.expectProgressing {
+				g.Expect(conditions.IsTrue(tt.deployment, clusterv1.MachineDeploymentProgressingCondition)).To(BeTrue())
+			} else {
+				g.Expect(conditions.IsFalse(tt.deployment, clusterv1.MachineDeploymentProgressingCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(tt.deployment, clusterv1.MachineDeploymentProgressingCondition)).To(Equal(clusterv1.ProgressDeadlineExceededReason))
+			}
+			g.Expect(tt.deployment.Status.LastProgressTime.Time).To(BeTemporally("==", tt.expectLastProgressTime.Time))
+			g.Expect(tt.deployment.Status.GetTypedPhase()).To(Equal(tt.expectPhase))
+		})
+	}
+}
+
+func TestSyncDeploymentProgressWithoutDeadline(t *testing.T) {
+	g := NewWithT(t)
+
+	d := &clusterv1.MachineDeployment{
+		Spec: clusterv1.MachineDeploymentSpec{
+			Replicas: pointer.Int32Ptr(3),
+		},
+	}
+	conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+
+	syncDeploymentProgress(d, d.Status.DeepCopy(), time.Now())
+	g.Expect(conditions.Has(d, clusterv1.MachineDeploymentProgressingCondition)).To(BeFalse())
+	g.Expect(d.Status.LastProgressTime).To(BeNil())
+}
+
+func TestProgressDeadlineRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	d := &clusterv1.MachineDeployment{
+		Spec: clusterv1.MachineDeploymentSpec{
+			Replicas:                pointer.Int32Ptr(3),
+			ProgressDeadlineSeconds: pointer.Int32Ptr(600),
+		},
+		Status: clusterv1.MachineDeploymentStatus{
+			Replicas:         3,
+			UpdatedReplicas:  1,
+			LastProgressTime: &metav1.Time{Time: now.Add(-5 * time.Minute)},
+		},
+	}
+	conditions.MarkTrue(d, clusterv1.MachineDeploymentProgressingCondition)
+	g.Expect(progressDeadlineRequeueAfter(d, now)).To(Equal(5*time.Minute + time.Second))
+
+	// A rollout that already exceeded the deadline is not checked again.
+	conditions.MarkFalse(d, clusterv1.MachineDeploymentProgressingCondition, clusterv1.ProgressDeadlineExceededReason, clusterv1.ConditionSeverityError, "")
+	g.Expect(progressDeadlineRequeueAfter(d, now)).To(BeZero())
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutRecreate implements the logic for the Recreate strategy: the old machine sets are scaled down to
+// zero, and the new machine set is created and scaled up only once all the old machines are gone.
+func (r *MachineDeploymentReconciler) rolloutRecreate(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+	// Don't create a new MS if not already existed, so that we avoid scaling up before scaling down.
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(d, msList, false)
+	if err != nil {
+		return err
+	}
+
+	allMSs := append(oldMSs, newMS)
+
+	// Scale down old machine sets.
+	scaledDown, err := r.scaleDownOldMachineSetsForRecreate(mdutil.FilterActiveMachineSets(oldMSs), d)
+	if err != nil {
+		return err
+	}
+	if scaledDown {
+		// Update DeploymentStatus.
+		return r.syncDeploymentStatus(allMSs, newMS, d)
+	}
+
+	// Do not create the new machines while old machines are still around.
+	oldMachinesExist, err := r.oldMachinesExist(oldMSs)
+	if err != nil {
+		return err
+	}
+	if oldMachinesExist {
+		return r.syncDeploymentStatus(allMSs, newMS, d)
+	}
+
+	// If we need to create a new MS, create it now.
+	if newMS == nil {
+		newMS, oldMSs, err = r.getAllMachineSetsAndSyncRevision(d, msList, true)
+		if err != nil {
+			return err
+		}
+
+		// newMS can be nil if the existing MachineSet had to be updated first, see rolloutRolling.
+		if newMS == nil {
+			return nil
+		}
+		allMSs = append(oldMSs, newMS)
+	}
+
+	// Scale up new machine set.
+	if err := r.scaleMachineSet(newMS, *(d.Spec.Replicas), d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if mdutil.DeploymentComplete(d, &d.Status) {
+		if err := r.cleanupDeployment(oldMSs, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scaleDownOldMachineSetsForRecreate scales down old machine sets when deployment strategy is "Recreate".
+func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRecreate(oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) (bool, error) {
+	scaled := false
+	for _, ms := range oldMSs {
+		if ms.Spec.Replicas == nil {
+			return scaled, errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", ms.Name)
+		}
+
+		// Scaling not required.
+		if *(ms.Spec.Replicas) == 0 {
+			continue
+		}
+
+		if err := r.scaleMachineSet(ms, 0, deployment); err != nil {
+			return scaled, err
+		}
+		scaled = true
+	}
+	return scaled, nil
+}
+
+// oldMachinesExist returns true if any of the old machine sets still controls a machine,
+// including the machines being deleted.
+func (r *MachineDeploymentReconciler) oldMachinesExist(oldMSs []*clusterv1.MachineSet) (bool, error) {
+	for _, ms := range oldMSs {
+		selector, err := metav1.LabelSelectorAsSelector(&ms.Spec.Selector)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get label selector for machine set %s", ms.Name)
+		}
+
+		machines := &clusterv1.MachineList{}
+		if err := r.Client.List(context.Background(), machines, client.InNamespace(ms.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return false, errors.Wrapf(err, "failed to list machines for machine set %s", ms.Name)
+		}
+
+		for i := range machines.Items {
+			if metav1.IsControlledBy(&machines.Items[i], ms) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func newRecreateMachineSet(name string, replicas int32) *clusterv1.MachineSet {
+	return &clusterv1.MachineSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("uid-" + name),
+		},
+		Spec: clusterv1.MachineSetSpec{
+			Replicas: pointer.Int32Ptr(replicas),
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"machineset": name},
+			},
+		},
+	}
+}
+
+func TestScaleDownOldMachineSetsForRecreate(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	d := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md",
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Replicas: pointer.Int32Ptr(3),
+			Strategy: &clusterv1.MachineDeploymentStrategy{
+				Type: clusterv1.RecreateMachineDeploymentStrategyType,
+			},
+		},
+	}
+
+	scaled := newRecreateMachineSet("scaled", 0)
+	running := newRecreateMachineSet("running", 2)
+
+	r := &MachineDeploymentReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, scaled.DeepCopy(), running.DeepCopy()),
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	changed, err := r.scaleDownOldMachineSetsForRecreate([]*clusterv1.MachineSet{scaled, running}, d)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+
+	ms := &clusterv1.MachineSet{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "running"}, ms)).To(Succeed())
+	g.Expect(*ms.Spec.Replicas).To(BeEquivalentTo(0))
+
+	// Once all the old machine sets are scaled down, there is nothing left to do.
+	changed, err = r.scaleDownOldMachineSetsForRecreate([]*clusterv1.MachineSet{scaled, ms}, d)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeFalse())
+}
+
+func TestOldMachinesExist(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	ms := newRecreateMachineSet("old", 0)
+	newMachine := func(name string, owner *clusterv1.MachineSet) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"machineset": "old"},
+			},
+		}
+		if owner != nil {
+			m.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, owner.GroupVersionKind())}
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		machines []runtime.Object
+		expect   bool
+	}{
+		{
+			name:   "no machines",
+			expect: false,
+		},
+		{
+			name:     "machine not controlled by the machine set",
+			machines: []runtime.Object{newMachine("orphan", nil)},
+			expect:   false,
+		},
+		{
+			name:     "machine controlled by the machine set",
+			machines: []runtime.Object{newMachine("owned", ms)},
+			expect:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineDeploymentReconciler{
+				Client:   fake.NewFakeClientWithScheme(scheme.Scheme, tt.machines...),
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			exist, err := r.oldMachinesExist([]*clusterv1.MachineSet{ms})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(exist).To(Equal(tt.expect))
+		})
+	}
+}
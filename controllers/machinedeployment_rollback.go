@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+)
+
+// rollback restores the machine template of the MachineSet with the revision requested by the
+// RollbackToAnnotation of the MachineDeployment, and removes the annotation. The MachineDeployment
+// is rolled out to the restored template once the change is applied.
+func (r *MachineDeploymentReconciler) rollback(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) {
+	logger := r.Log.WithValues("machinedeployment", d.Name, "namespace", d.Namespace)
+
+	// The annotation is removed whatever the outcome, a failed rollback must be requested again.
+	value := d.Annotations[mdutil.RollbackToAnnotation]
+	delete(d.Annotations, mdutil.RollbackToAnnotation)
+
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || revision < 0 {
+		r.recorder.Eventf(d, corev1.EventTypeWarning, "RollbackInvalidRevision", "Invalid revision %q to roll back to", value)
+		return
+	}
+
+	// If rollback revision is 0, rollback to the last revision
+	if revision == 0 {
+		if revision = mdutil.LastRevision(msList, logger); revision == 0 {
+			r.recorder.Eventf(d, corev1.EventTypeWarning, "RollbackRevisionNotFound", "Unable to find last revision")
+			return
+		}
+	}
+
+	for _, ms := range msList {
+		v, err := mdutil.Revision(ms)
+		if err != nil {
+			logger.V(4).Info("Unable to extract revision from machine set", "machineset", ms.Name, "error", err.Error())
+			continue
+		}
+		if v != revision {
+			continue
+		}
+
+		if mdutil.EqualIgnoreHash(&d.Spec.Template, &ms.Spec.Template) {
+			r.recorder.Eventf(d, corev1.EventTypeWarning, "RollbackTemplateUnchanged", "The rollback revision %d contains the same template as the current MachineDeployment", revision)
+			return
+		}
+
+		// Restore the machine template, without the hash label added to the MachineSet; the revision
+		// number of the MachineSet will be incremented when it becomes the new MachineSet again.
+		template := ms.Spec.Template.DeepCopy()
+		delete(template.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+		d.Spec.Template = *template
+
+		// Set the annotations of the MachineDeployment to the ones of the MachineSet, e.g. the change cause.
+		mdutil.SetDeploymentAnnotationsTo(d, ms)
+
+		logger.Info("Rolled back MachineDeployment", "revision", revision, "machineset", ms.Name)
+		r.recorder.Eventf(d, corev1.EventTypeNormal, "RollbackDone", "Rolled back MachineDeployment to revision %d", revision)
+		return
+	}
+
+	r.recorder.Eventf(d, corev1.EventTypeWarning, "RollbackRevisionNotFound", "Unable to find revision %d to roll back to", revision)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+)
+
+func TestMachineDeploymentRollback(t *testing.T) {
+	newMachineSet := func(name, revision, version string) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					mdutil.RevisionAnnotation: revision,
+					"change-cause":            "version " + version,
+				},
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					ObjectMeta: clusterv1.ObjectMeta{
+						Labels: map[string]string{
+							mdutil.DefaultMachineDeploymentUniqueLabelKey: name,
+							clusterv1.MachineDeploymentLabelName:          "md",
+						},
+					},
+					Spec: clusterv1.MachineSpec{
+						Version: pointer.StringPtr(version),
+					},
+				},
+			},
+		}
+	}
+	msList := []*clusterv1.MachineSet{
+		newMachineSet("ms1", "1", "v1.17.1"),
+		newMachineSet("ms2", "2", "v1.17.2"),
+		newMachineSet("ms3", "3", "v1.17.3"),
+	}
+
+	tests := []struct {
+		name            string
+		rollbackTo      string
+		expectVersion   string
+		expectEvent     string
+		expectAnnotated bool
+	}{
+		{
+			name:            "rolls back to the given revision",
+			rollbackTo:      "1",
+			expectVersion:   "v1.17.1",
+			expectEvent:     "RollbackDone",
+			expectAnnotated: true,
+		},
+		{
+			name:            "rolls back to the last revision",
+			rollbackTo:      "0",
+			expectVersion:   "v1.17.2",
+			expectEvent:     "RollbackDone",
+			expectAnnotated: true,
+		},
+		{
+			name:          "does not change the template when rolling back to the current revision",
+			rollbackTo:    "3",
+			expectVersion: "v1.17.3",
+			expectEvent:   "RollbackTemplateUnchanged",
+		},
+		{
+			name:          "does not change the template when the revision does not exist",
+			rollbackTo:    "4",
+			expectVersion: "v1.17.3",
+			expectEvent:   "RollbackRevisionNotFound",
+		},
+		{
+			name:          "does not change the template when the revision is invalid",
+			rollbackTo:    "last",
+			expectVersion: "v1.17.3",
+			expectEvent:   "RollbackInvalidRevision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "md",
+					Annotations: map[string]string{
+						mdutil.RevisionAnnotation:   "3",
+						mdutil.RollbackToAnnotation: tt.rollbackTo,
+					},
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Template: clusterv1.MachineTemplateSpec{
+						ObjectMeta: clusterv1.ObjectMeta{
+							Labels: map[string]string{clusterv1.MachineDeploymentLabelName: "md"},
+						},
+						Spec: clusterv1.MachineSpec{
+							Version: pointer.StringPtr("v1.17.3"),
+						},
+					},
+				},
+			}
+
+			recorder := record.NewFakeRecorder(32)
+			r := &MachineDeploymentReconciler{
+				Log:      log.Log,
+				recorder: recorder,
+			}
+			r.rollback(d, msList)
+
+			g.Expect(*d.Spec.Template.Spec.Version).To(Equal(tt.expectVersion))
+			g.Expect(d.Spec.Template.Labels).NotTo(HaveKey(mdutil.DefaultMachineDeploymentUniqueLabelKey))
+			g.Expect(d.Annotations).NotTo(HaveKey(mdutil.RollbackToAnnotation))
+			g.Expect(d.Annotations).To(HaveKeyWithValue(mdutil.RevisionAnnotation, "3"))
+			if tt.expectAnnotated {
+				g.Expect(d.Annotations).To(HaveKeyWithValue("change-cause", "version "+tt.expectVersion))
+			}
+			g.Expect(recorder.Events).To(Receive(ContainSubstring(tt.expectEvent)))
+		})
+	}
+}
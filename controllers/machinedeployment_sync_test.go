@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"reflect"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -212,7 +213,7 @@ func TestMachineDeploymentSyncStatus(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			actualStatus := calculateStatus(test.machineSets, test.newMachineSet, test.deployment)
-			if actualStatus != test.expectedStatus {
+			if !reflect.DeepEqual(actualStatus, test.expectedStatus) {
 				t.Errorf("Expected %+v but got %+v", test.expectedStatus, actualStatus)
 			}
 		})
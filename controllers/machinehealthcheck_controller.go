@@ -0,0 +1,405 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// EventRemediationRestricted is emitted in case when machine remediation
+	// is restricted by remediation circuit shorting logic
+	EventRemediationRestricted string = "RemediationRestricted"
+
+	// EventMachineRemediated is emitted when an unhealthy Machine owned by a MachineSet is deleted.
+	EventMachineRemediated string = "MachineRemediated"
+
+	// EventMachineMarkedUnhealthy is emitted when an unhealthy Machine which isn't owned by a MachineSet
+	// is marked with the unhealthy annotation.
+	EventMachineMarkedUnhealthy string = "MachineMarkedUnhealthy"
+)
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinehealthchecks;machinehealthchecks/status,verbs=get;list;watch;update;patch
+
+// MachineHealthCheckReconciler reconciles a MachineHealthCheck object
+type MachineHealthCheckReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// Tracker, if set, provides cached clients for the workload clusters and allows
+	// to watch their Nodes.
+	Tracker *remote.ClusterCacheTracker
+
+	controller   controller.Controller
+	recorder     record.EventRecorder
+	scheme       *runtime.Scheme
+	remoteClient func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
+}
+
+func (r *MachineHealthCheckReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	controller, err := ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1.MachineHealthCheck{}).
+		Watches(
+			&source.Kind{Type: &clusterv1.Cluster{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.clusterToMachineHealthCheck)},
+		).
+		Watches(
+			&source.Kind{Type: &clusterv1.Machine{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.machineToMachineHealthCheck)},
+		).
+		WithOptions(options).
+		Build(r)
+
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	r.controller = controller
+	r.recorder = mgr.GetEventRecorderFor("machinehealthcheck-controller")
+	r.scheme = mgr.GetScheme()
+	if r.remoteClient == nil {
+		r.remoteClient = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClient = r.Tracker.ClusterClient
+		}
+	}
+	return nil
+}
+
+func (r *MachineHealthCheckReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.Background()
+	logger := r.Log.WithValues("machinehealthcheck", req.Name, "namespace", req.Namespace)
+
+	// Fetch the MachineHealthCheck instance
+	m := &clusterv1.MachineHealthCheck{}
+	if err := r.Client.Get(ctx, req.NamespacedName, m); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return. Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return ctrl.Result{}, nil
+		}
+
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, m.Namespace, m.Spec.ClusterName)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get cluster %q for MachineHealthCheck %q in namespace %q",
+			m.Spec.ClusterName, m.Name, m.Namespace)
+	}
+
+	// Return early if the object or Cluster is paused.
+	if util.IsPaused(cluster, m) {
+		logger.V(3).Info("reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper
+	patchHelper, err := patch.NewHelper(m, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always attempt to patch the object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, m); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Reconcile labels.
+	if m.Labels == nil {
+		m.Labels = make(map[string]string)
+	}
+	m.Labels[clusterv1.ClusterLabelName] = m.Spec.ClusterName
+
+	result, err := r.reconcile(ctx, cluster, m)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile MachineHealthCheck")
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+	}
+	return result, err
+}
+
+func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) (ctrl.Result, error) {
+	logger := r.Log.WithValues("machinehealthcheck", m.Name, "namespace", m.Namespace, "cluster", cluster.Name)
+
+	// Ensure the MachineHealthCheck is owned by the Cluster it belongs to.
+	m.OwnerReferences = util.EnsureOwnerRef(m.OwnerReferences, metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	})
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// The Nodes can't be checked before the control plane is initialized.
+	if !cluster.Status.ControlPlaneInitialized {
+		logger.V(3).Info("Waiting for the control plane to be initialized")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.watchClusterNodes(ctx, cluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to watch the Nodes of the workload cluster")
+	}
+
+	remoteClient, err := r.remoteClient(r.Client, cluster, r.scheme)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create remote cluster client")
+	}
+
+	targets, err := r.getTargetsFromMHC(ctx, remoteClient, m)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	totalTargets := len(targets)
+	m.Status.ExpectedMachines = int32(totalTargets)
+
+	nodeStartupTimeout := clusterv1.DefaultNodeStartupTimeout.Duration
+	if m.Spec.NodeStartupTimeout != nil {
+		nodeStartupTimeout = m.Spec.NodeStartupTimeout.Duration
+	}
+
+	// Health check all the targets.
+	healthy, unhealthy, nextCheckTimes := healthCheckTargets(targets, logger, nodeStartupTimeout)
+	m.Status.CurrentHealthy = int32(len(healthy))
+	m.Status.ObservedGeneration = m.Generation
+
+	var errList []error
+	for _, t := range healthy {
+		if err := r.clearUnhealthyMark(ctx, t); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	// Short-circuit the remediation when too many Machines are unhealthy, it's more likely to be caused by
+	// a systemic failure than by the Machines themselves.
+	if !isAllowedRemediation(m) {
+		logger.V(3).Info("Short-circuiting remediation", "total target", totalTargets, "max unhealthy", m.Spec.MaxUnhealthy, "unhealthy targets", len(unhealthy))
+		r.recorder.Eventf(m, corev1.EventTypeWarning, EventRemediationRestricted,
+			"Remediation restricted due to exceeded number of unhealthy machines (total: %v, unhealthy: %v, maxUnhealthy: %v)",
+			totalTargets, len(unhealthy), m.Spec.MaxUnhealthy)
+	} else {
+		for _, t := range unhealthy {
+			if err := r.remediate(ctx, logger, m, t); err != nil {
+				errList = append(errList, err)
+			}
+		}
+	}
+	if len(errList) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errList)
+	}
+
+	if minNextCheck := minDuration(nextCheckTimes); minNextCheck > 0 {
+		logger.V(3).Info("Some targets might go unhealthy, ensuring a requeue happens", "requeueIn", minNextCheck.Truncate(time.Second).String())
+		return ctrl.Result{RequeueAfter: minNextCheck}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// remediate deletes an unhealthy Machine owned by a MachineSet, so that it gets replaced by its MachineSet. The other
+// Machines are only marked with the unhealthy annotation, it's up to their owner to remediate them.
+func (r *MachineHealthCheckReconciler) remediate(ctx context.Context, logger logr.Logger, m *clusterv1.MachineHealthCheck, t healthCheckTarget) error {
+	logger = logger.WithValues("machine", t.Machine.Name)
+
+	if !util.HasOwner(t.Machine.OwnerReferences, clusterv1.GroupVersion.String(), []string{"MachineSet"}) {
+		if _, ok := t.Machine.Annotations[clusterv1.MachineUnhealthyAnnotation]; ok {
+			return nil
+		}
+		logger.Info("Marking unhealthy Machine not owned by a MachineSet")
+		patch := client.MergeFrom(t.Machine.DeepCopy())
+		if t.Machine.Annotations == nil {
+			t.Machine.Annotations = make(map[string]string)
+		}
+		t.Machine.Annotations[clusterv1.MachineUnhealthyAnnotation] = ""
+		if err := r.Client.Patch(ctx, t.Machine, patch); err != nil {
+			return errors.Wrapf(err, "failed to mark Machine %s/%s as unhealthy", t.Machine.Namespace, t.Machine.Name)
+		}
+		r.recorder.Eventf(m, corev1.EventTypeNormal, EventMachineMarkedUnhealthy, "Machine %s has been marked as unhealthy", t.string())
+		return nil
+	}
+
+	logger.Info("Deleting unhealthy Machine")
+	if err := r.Client.Delete(ctx, t.Machine); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete unhealthy Machine %s/%s", t.Machine.Namespace, t.Machine.Name)
+	}
+	r.recorder.Eventf(m, corev1.EventTypeNormal, EventMachineRemediated, "Machine %s has been remediated", t.string())
+	return nil
+}
+
+// clearUnhealthyMark removes the unhealthy annotation from a Machine which became healthy again.
+func (r *MachineHealthCheckReconciler) clearUnhealthyMark(ctx context.Context, t healthCheckTarget) error {
+	if _, ok := t.Machine.Annotations[clusterv1.MachineUnhealthyAnnotation]; !ok {
+		return nil
+	}
+	patch := client.MergeFrom(t.Machine.DeepCopy())
+	delete(t.Machine.Annotations, clusterv1.MachineUnhealthyAnnotation)
+	if err := r.Client.Patch(ctx, t.Machine, patch); err != nil {
+		return errors.Wrapf(err, "failed to remove the unhealthy mark of Machine %s/%s", t.Machine.Namespace, t.Machine.Name)
+	}
+	return nil
+}
+
+// isAllowedRemediation returns true if the number of unhealthy Machines doesn't exceed MaxUnhealthy.
+func isAllowedRemediation(m *clusterv1.MachineHealthCheck) bool {
+	if m.Spec.MaxUnhealthy == nil {
+		return true
+	}
+	maxUnhealthy, err := intstr.GetValueFromIntOrPercent(m.Spec.MaxUnhealthy, int(m.Status.ExpectedMachines), false)
+	if err != nil {
+		return false
+	}
+	return int(m.Status.ExpectedMachines-m.Status.CurrentHealthy) <= maxUnhealthy
+}
+
+// watchClusterNodes starts watching the Nodes of the workload cluster through the cluster cache tracker,
+// so that the MachineHealthChecks of the cluster are reconciled as soon as the conditions of their Nodes change.
+func (r *MachineHealthCheckReconciler) watchClusterNodes(ctx context.Context, cluster *clusterv1.Cluster) error {
+	// Nodes can only be watched when running in a manager with a cluster cache tracker.
+	if r.controller == nil || r.Tracker == nil {
+		return nil
+	}
+
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	return r.Tracker.Watch(ctx, remote.WatchInput{
+		Name:         "machinehealthcheck-watchNodes",
+		Cluster:      key,
+		Watcher:      r.controller,
+		Kind:         &corev1.Node{},
+		EventHandler: &handler.EnqueueRequestsFromMapFunc{ToRequests: r.nodeToMachineHealthCheck(key)},
+	})
+}
+
+// clusterToMachineHealthCheck maps a Cluster to the MachineHealthChecks of the cluster.
+func (r *MachineHealthCheckReconciler) clusterToMachineHealthCheck(o handler.MapObject) []reconcile.Request {
+	c, ok := o.Object.(*clusterv1.Cluster)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Cluster but got a %T", o.Object), "failed to get MachineHealthChecks for Cluster")
+		return nil
+	}
+
+	mhcList := &clusterv1.MachineHealthCheckList{}
+	if err := r.Client.List(
+		context.Background(),
+		mhcList,
+		client.InNamespace(c.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: c.Name},
+	); err != nil {
+		r.Log.Error(err, "Unable to list MachineHealthChecks", "cluster", c.Name, "namespace", c.Namespace)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range mhcList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mhcList.Items[i].Namespace, Name: mhcList.Items[i].Name}})
+	}
+	return requests
+}
+
+// machineToMachineHealthCheck maps a Machine to the MachineHealthChecks selecting it.
+func (r *MachineHealthCheckReconciler) machineToMachineHealthCheck(o handler.MapObject) []reconcile.Request {
+	m, ok := o.Object.(*clusterv1.Machine)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Machine but got a %T", o.Object), "failed to get MachineHealthChecks for Machine")
+		return nil
+	}
+
+	mhcList := &clusterv1.MachineHealthCheckList{}
+	if err := r.Client.List(
+		context.Background(),
+		mhcList,
+		client.InNamespace(m.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: m.Spec.ClusterName},
+	); err != nil {
+		r.Log.Error(err, "Unable to list MachineHealthChecks", "machine", m.Name, "namespace", m.Namespace)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range mhcList.Items {
+		mhc := &mhcList.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(m.Labels)) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mhc.Namespace, Name: mhc.Name}})
+	}
+	return requests
+}
+
+// nodeToMachineHealthCheck returns a function mapping a Node of the given workload cluster to the MachineHealthChecks
+// selecting its Machine.
+func (r *MachineHealthCheckReconciler) nodeToMachineHealthCheck(cluster types.NamespacedName) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		node, ok := o.Object.(*corev1.Node)
+		if !ok {
+			r.Log.Error(errors.Errorf("expected a Node but got a %T", o.Object), "failed to get MachineHealthChecks for Node")
+			return nil
+		}
+
+		machineList := &clusterv1.MachineList{}
+		if err := r.Client.List(
+			context.Background(),
+			machineList,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+		); err != nil {
+			r.Log.Error(err, "Unable to list Machines", "cluster", cluster.Name, "namespace", cluster.Namespace)
+			return nil
+		}
+
+		for i := range machineList.Items {
+			m := &machineList.Items[i]
+			if m.Status.NodeRef != nil && m.Status.NodeRef.Name == node.Name {
+				return r.machineToMachineHealthCheck(handler.MapObject{Meta: m, Object: m})
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+var _ reconcile.Reconciler = &MachineHealthCheckReconciler{}
+
+func newTestMachineHealthCheck(name, clusterName string) *clusterv1.MachineHealthCheck {
+	maxUnhealthy := intstr.FromString("100%")
+	return &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName: clusterName,
+			},
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: clusterName,
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"role": "worker"},
+			},
+			UnhealthyConditions: []clusterv1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				},
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionFalse,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+			MaxUnhealthy: &maxUnhealthy,
+		},
+	}
+}
+
+func newTestHealthCheckedMachine(name, clusterName, nodeName string, ownedByMachineSet bool) *clusterv1.Machine {
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.Now(),
+			Labels: map[string]string{
+				"role":                     "worker",
+				clusterv1.ClusterLabelName: clusterName,
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: clusterName,
+		},
+	}
+	if nodeName != "" {
+		m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: nodeName}
+	}
+	if ownedByMachineSet {
+		m.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "MachineSet",
+				Name:       "test-machineset",
+			},
+		}
+	}
+	return m
+}
+
+func newTestNode(name string, ready corev1.ConditionStatus, since time.Duration) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             ready,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+				},
+			},
+		},
+	}
+}
+
+func TestMachineHealthCheckReconcile(t *testing.T) {
+	newCluster := func() *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+				UID:       "test-cluster-uid",
+			},
+			Status: clusterv1.ClusterStatus{
+				ControlPlaneInitialized: true,
+			},
+		}
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-mhc",
+			Namespace: "default",
+		},
+	}
+
+	newReconciler := func(objs []runtime.Object, nodes []runtime.Object, rec record.EventRecorder) *MachineHealthCheckReconciler {
+		remoteClient := fake.NewFakeClientWithScheme(scheme.Scheme, nodes...)
+		return &MachineHealthCheckReconciler{
+			Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+			Log:      log.Log,
+			recorder: rec,
+			remoteClient: func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error) {
+				return remoteClient, nil
+			},
+		}
+	}
+
+	t.Run("remediates the unhealthy machines", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		cluster := newCluster()
+		mhc := newTestMachineHealthCheck("test-mhc", cluster.Name)
+		healthy := newTestHealthCheckedMachine("healthy", cluster.Name, "healthy-node", true)
+		unhealthy := newTestHealthCheckedMachine("unhealthy", cluster.Name, "unhealthy-node", true)
+		unowned := newTestHealthCheckedMachine("unowned", cluster.Name, "unowned-node", false)
+		recovered := newTestHealthCheckedMachine("recovered", cluster.Name, "recovered-node", false)
+		recovered.Annotations = map[string]string{clusterv1.MachineUnhealthyAnnotation: ""}
+		notSelected := newTestHealthCheckedMachine("not-selected", cluster.Name, "not-selected-node", true)
+		notSelected.Labels["role"] = "control-plane"
+
+		rec := record.NewFakeRecorder(32)
+		r := newReconciler(
+			[]runtime.Object{cluster, mhc, healthy, unhealthy, unowned, recovered, notSelected},
+			[]runtime.Object{
+				newTestNode("healthy-node", corev1.ConditionTrue, time.Hour),
+				newTestNode("unhealthy-node", corev1.ConditionUnknown, 10*time.Minute),
+				newTestNode("unowned-node", corev1.ConditionFalse, 10*time.Minute),
+				newTestNode("recovered-node", corev1.ConditionTrue, time.Minute),
+				newTestNode("not-selected-node", corev1.ConditionUnknown, 10*time.Minute),
+			},
+			rec,
+		)
+
+		result, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(reconcile.Result{}))
+
+		// The unhealthy Machine owned by a MachineSet is deleted.
+		err = r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "unhealthy"}, &clusterv1.Machine{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		// The unhealthy Machine which isn't owned by a MachineSet is marked as unhealthy.
+		m := &clusterv1.Machine{}
+		g.Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "unowned"}, m)).To(Succeed())
+		g.Expect(m.Annotations).To(HaveKey(clusterv1.MachineUnhealthyAnnotation))
+
+		// The Machine which became healthy again isn't marked anymore.
+		m = &clusterv1.Machine{}
+		g.Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "recovered"}, m)).To(Succeed())
+		g.Expect(m.Annotations).NotTo(HaveKey(clusterv1.MachineUnhealthyAnnotation))
+
+		// The Machine not selected by the MachineHealthCheck is left alone.
+		g.Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "not-selected"}, &clusterv1.Machine{})).To(Succeed())
+
+		g.Expect(r.Client.Get(ctx, request.NamespacedName, mhc)).To(Succeed())
+		g.Expect(mhc.Status.ExpectedMachines).To(Equal(int32(4)))
+		g.Expect(mhc.Status.CurrentHealthy).To(Equal(int32(2)))
+		g.Expect(mhc.OwnerReferences).To(ContainElement(metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		}))
+		g.Expect(rec.Events).To(HaveLen(2))
+	})
+
+	t.Run("requeues when a machine might go unhealthy", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		cluster := newCluster()
+		mhc := newTestMachineHealthCheck("test-mhc", cluster.Name)
+		machine := newTestHealthCheckedMachine("machine", cluster.Name, "node", true)
+
+		r := newReconciler(
+			[]runtime.Object{cluster, mhc, machine},
+			[]runtime.Object{newTestNode("node", corev1.ConditionFalse, time.Minute)},
+			record.NewFakeRecorder(32),
+		)
+
+		result, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(BeNumerically("~", 4*time.Minute, time.Minute))
+
+		g.Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "machine"}, machine)).To(Succeed())
+	})
+
+	t.Run("short-circuits remediation when too many machines are unhealthy", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		cluster := newCluster()
+		mhc := newTestMachineHealthCheck("test-mhc", cluster.Name)
+		maxUnhealthy := intstr.FromInt(1)
+		mhc.Spec.MaxUnhealthy = &maxUnhealthy
+		healthy := newTestHealthCheckedMachine("healthy", cluster.Name, "healthy-node", true)
+		unhealthy1 := newTestHealthCheckedMachine("unhealthy-1", cluster.Name, "unhealthy-node-1", true)
+		unhealthy2 := newTestHealthCheckedMachine("unhealthy-2", cluster.Name, "unhealthy-node-2", true)
+
+		rec := record.NewFakeRecorder(32)
+		r := newReconciler(
+			[]runtime.Object{cluster, mhc, healthy, unhealthy1, unhealthy2},
+			[]runtime.Object{
+				newTestNode("healthy-node", corev1.ConditionTrue, time.Hour),
+				newTestNode("unhealthy-node-1", corev1.ConditionUnknown, 10*time.Minute),
+			},
+			rec,
+		)
+
+		_, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		machines := &clusterv1.MachineList{}
+		g.Expect(r.Client.List(ctx, machines)).To(Succeed())
+		g.Expect(machines.Items).To(HaveLen(3))
+
+		g.Expect(r.Client.Get(ctx, request.NamespacedName, mhc)).To(Succeed())
+		g.Expect(mhc.Status.ExpectedMachines).To(Equal(int32(3)))
+		g.Expect(mhc.Status.CurrentHealthy).To(Equal(int32(1)))
+		g.Expect(rec.Events).To(Receive(ContainSubstring(EventRemediationRestricted)))
+	})
+
+	t.Run("waits for the control plane to be initialized", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		cluster := newCluster()
+		cluster.Status.ControlPlaneInitialized = false
+		mhc := newTestMachineHealthCheck("test-mhc", cluster.Name)
+		machine := newTestHealthCheckedMachine("machine", cluster.Name, "node", true)
+
+		r := newReconciler([]runtime.Object{cluster, mhc, machine}, nil, record.NewFakeRecorder(32))
+
+		result, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(reconcile.Result{}))
+		g.Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "machine"}, machine)).To(Succeed())
+	})
+}
+
+func TestMachineHealthCheckMappings(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+	workers := newTestMachineHealthCheck("workers", cluster.Name)
+	controlPlane := newTestMachineHealthCheck("control-plane", cluster.Name)
+	controlPlane.Spec.Selector.MatchLabels = map[string]string{"role": "control-plane"}
+	otherCluster := newTestMachineHealthCheck("other-cluster", "other-cluster")
+	machine := newTestHealthCheckedMachine("machine", cluster.Name, "node", true)
+
+	r := &MachineHealthCheckReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, cluster, workers, controlPlane, otherCluster, machine),
+		Log:    log.Log,
+	}
+
+	requests := r.clusterToMachineHealthCheck(handler.MapObject{Meta: cluster, Object: cluster})
+	g.Expect(requests).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "workers"}},
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "control-plane"}},
+	))
+
+	requests = r.machineToMachineHealthCheck(handler.MapObject{Meta: machine, Object: machine})
+	g.Expect(requests).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "workers"}},
+	))
+
+	node := newTestNode("node", corev1.ConditionTrue, time.Hour)
+	requests = r.nodeToMachineHealthCheck(types.NamespacedName{Namespace: "default", Name: cluster.Name})(handler.MapObject{Meta: node, Object: node})
+	g.Expect(requests).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "workers"}},
+	))
+
+	otherNode := newTestNode("other-node", corev1.ConditionTrue, time.Hour)
+	g.Expect(r.nodeToMachineHealthCheck(types.NamespacedName{Namespace: "default", Name: cluster.Name})(handler.MapObject{Meta: otherNode, Object: otherNode})).To(BeEmpty())
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// healthCheckTarget contains the information required to perform a health check
+// on the node to determine if any remediation is required.
+type healthCheckTarget struct {
+	Machine     *clusterv1.Machine
+	Node        *corev1.Node
+	MHC         *clusterv1.MachineHealthCheck
+	nodeMissing bool
+}
+
+func (t *healthCheckTarget) string() string {
+	return fmt.Sprintf("%s/%s/%s/%s",
+		t.MHC.GetNamespace(),
+		t.MHC.GetName(),
+		t.Machine.GetName(),
+		t.nodeName(),
+	)
+}
+
+// Get the node name if the target has a node
+func (t *healthCheckTarget) nodeName() string {
+	if t.Node != nil {
+		return t.Node.GetName()
+	}
+	return ""
+}
+
+// needsRemediation determines whether the target needs remediation. When it doesn't, the returned duration is the
+// time after which the target might need remediation and should be checked again, or zero.
+func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachineToHaveNode time.Duration) (bool, time.Duration) {
+	var nextCheckTimes []time.Duration
+	now := time.Now()
+
+	if t.Machine.Status.FailureReason != nil || t.Machine.Status.FailureMessage != nil {
+		logger.V(3).Info("Target is unhealthy: machine has failed", "target", t.string())
+		return true, time.Duration(0)
+	}
+
+	// the node does not exist
+	if t.nodeMissing {
+		logger.V(3).Info("Target is unhealthy: node is missing", "target", t.string())
+		return true, time.Duration(0)
+	}
+
+	// the node has not been set yet
+	if t.Node == nil {
+		if t.Machine.CreationTimestamp.Add(timeoutForMachineToHaveNode).Before(now) {
+			logger.V(3).Info("Target is unhealthy: machine has no node", "target", t.string(), "timeout", timeoutForMachineToHaveNode.String())
+			return true, time.Duration(0)
+		}
+		durationUnhealthy := now.Sub(t.Machine.CreationTimestamp.Time)
+		nextCheck := timeoutForMachineToHaveNode - durationUnhealthy + time.Second
+		return false, nextCheck
+	}
+
+	// check conditions
+	for _, c := range t.MHC.Spec.UnhealthyConditions {
+		nodeCondition := getNodeCondition(t.Node, c.Type)
+
+		// Skip when current node condition is different from the one reported
+		// in the MachineHealthCheck.
+		if nodeCondition == nil || nodeCondition.Status != c.Status {
+			continue
+		}
+
+		// If the condition has been in the unhealthy state for longer than the
+		// timeout, return true with no requeue time.
+		if nodeCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
+			logger.V(3).Info("Target is unhealthy: condition is in state longer than allowed timeout",
+				"target", t.string(), "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
+			return true, time.Duration(0)
+		}
+
+		durationUnhealthy := now.Sub(nodeCondition.LastTransitionTime.Time)
+		nextCheck := c.Timeout.Duration - durationUnhealthy + time.Second
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
+	}
+	return false, minDuration(nextCheckTimes)
+}
+
+// getTargetsFromMHC uses the MachineHealthCheck's selector to fetch machines
+// and their nodes targeted by the health check, ready for health checking.
+func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, clusterClient client.Client, mhc *clusterv1.MachineHealthCheck) ([]healthCheckTarget, error) {
+	machines, err := r.getMachinesFromMHC(ctx, mhc)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting machines from MachineHealthCheck")
+	}
+	if len(machines) == 0 {
+		return nil, nil
+	}
+
+	targets := []healthCheckTarget{}
+	for k := range machines {
+		// Skip the Machines which are already being deleted.
+		if !machines[k].DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		target := healthCheckTarget{
+			MHC:     mhc,
+			Machine: &machines[k],
+		}
+		node, err := r.getNodeFromMachine(ctx, clusterClient, target.Machine)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, "error getting node")
+			}
+
+			// A node has been seen for this machine, but it no longer exists
+			target.nodeMissing = true
+			node = nil
+		}
+		target.Node = node
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// getMachinesFromMHC fetches Machines matched by the MachineHealthCheck's
+// label selector
+func (r *MachineHealthCheckReconciler) getMachinesFromMHC(ctx context.Context, mhc *clusterv1.MachineHealthCheck) ([]clusterv1.Machine, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build selector")
+	}
+	// An empty selector would select all the Machines of the namespace.
+	if selector.Empty() {
+		return nil, nil
+	}
+
+	// Only select the Machines of the cluster the MachineHealthCheck belongs to.
+	clusterRequirement, err := labels.NewRequirement(clusterv1.ClusterLabelName, selection.Equals, []string{mhc.Spec.ClusterName})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build selector")
+	}
+	selector = selector.Add(*clusterRequirement)
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(
+		ctx,
+		machineList,
+		client.MatchingLabelsSelector{Selector: selector},
+		client.InNamespace(mhc.GetNamespace()),
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list machines")
+	}
+	return machineList.Items, nil
+}
+
+// getNodeFromMachine fetches the node from a local or remote cluster for a
+// given machine.
+func (r *MachineHealthCheckReconciler) getNodeFromMachine(ctx context.Context, clusterClient client.Client, machine *clusterv1.Machine) (*corev1.Node, error) {
+	if machine.Status.NodeRef == nil {
+		return nil, nil
+	}
+
+	node := &corev1.Node{}
+	nodeKey := client.ObjectKey{
+		Name: machine.Status.NodeRef.Name,
+	}
+	err := clusterClient.Get(ctx, nodeKey, node)
+	return node, err
+}
+
+// healthCheckTargets health checks a slice of targets
+// and gives a data to measure the average health.
+func healthCheckTargets(targets []healthCheckTarget, logger logr.Logger, timeoutForMachineToHaveNode time.Duration) ([]healthCheckTarget, []healthCheckTarget, []time.Duration) {
+	var nextCheckTimes []time.Duration
+	var unhealthy []healthCheckTarget
+	var healthy []healthCheckTarget
+
+	for _, t := range targets {
+		needsRemediation, nextCheck := t.needsRemediation(logger, timeoutForMachineToHaveNode)
+
+		if needsRemediation {
+			unhealthy = append(unhealthy, t)
+			continue
+		}
+
+		if nextCheck > 0 {
+			logger.V(3).Info("Target is likely to go unhealthy", "target", t.string(), "timeUntilUnhealthy", nextCheck.Truncate(time.Second).String())
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+			continue
+		}
+
+		if t.Machine.DeletionTimestamp.IsZero() {
+			healthy = append(healthy, t)
+		}
+	}
+	return healthy, unhealthy, nextCheckTimes
+}
+
+// getNodeCondition returns node condition by type
+func getNodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) *corev1.NodeCondition {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == conditionType {
+			return &cond
+		}
+	}
+	return nil
+}
+
+// minDuration returns the smallest of the given durations, or zero if there are none.
+func minDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return time.Duration(0)
+	}
+
+	minDuration := durations[0]
+	// Ignore first element as that is already minDuration
+	for _, nc := range durations[1:] {
+		if nc < minDuration {
+			minDuration = nc
+		}
+	}
+	return minDuration
+}
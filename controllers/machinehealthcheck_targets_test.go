@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/cluster-api/errors"
+)
+
+func TestHealthCheckTargetNeedsRemediation(t *testing.T) {
+	mhc := newTestMachineHealthCheck("test-mhc", "test-cluster")
+	nodeStartupTimeout := 10 * time.Minute
+
+	failed := newTestHealthCheckedMachine("failed", "test-cluster", "node", true)
+	failureReason := errors.CreateMachineError
+	failed.Status.FailureReason = &failureReason
+	failed.Status.FailureMessage = pointer.StringPtr("failed to create")
+
+	withoutNode := newTestHealthCheckedMachine("without-node", "test-cluster", "", true)
+
+	withoutNodeTooLong := newTestHealthCheckedMachine("without-node-too-long", "test-cluster", "", true)
+	withoutNodeTooLong.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+
+	machine := newTestHealthCheckedMachine("machine", "test-cluster", "node", true)
+
+	tests := []struct {
+		name             string
+		target           healthCheckTarget
+		expectRemediate  bool
+		expectNextCheck  bool
+		expectedMaxCheck time.Duration
+	}{
+		{
+			name:   "healthy node",
+			target: healthCheckTarget{MHC: mhc, Machine: machine, Node: newTestNode("node", corev1.ConditionTrue, time.Hour)},
+		},
+		{
+			name:            "failed machine",
+			target:          healthCheckTarget{MHC: mhc, Machine: failed, Node: newTestNode("node", corev1.ConditionTrue, time.Hour)},
+			expectRemediate: true,
+		},
+		{
+			name:            "missing node",
+			target:          healthCheckTarget{MHC: mhc, Machine: machine, nodeMissing: true},
+			expectRemediate: true,
+		},
+		{
+			name:             "machine without node yet",
+			target:           healthCheckTarget{MHC: mhc, Machine: withoutNode},
+			expectNextCheck:  true,
+			expectedMaxCheck: nodeStartupTimeout + time.Second,
+		},
+		{
+			name:            "machine without node after the startup timeout",
+			target:          healthCheckTarget{MHC: mhc, Machine: withoutNodeTooLong},
+			expectRemediate: true,
+		},
+		{
+			name:             "unhealthy condition within its timeout",
+			target:           healthCheckTarget{MHC: mhc, Machine: machine, Node: newTestNode("node", corev1.ConditionUnknown, time.Minute)},
+			expectNextCheck:  true,
+			expectedMaxCheck: 4*time.Minute + time.Second,
+		},
+		{
+			name:            "unhealthy condition past its timeout",
+			target:          healthCheckTarget{MHC: mhc, Machine: machine, Node: newTestNode("node", corev1.ConditionFalse, 6*time.Minute)},
+			expectRemediate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			remediate, nextCheck := tt.target.needsRemediation(log.Log, nodeStartupTimeout)
+			g.Expect(remediate).To(Equal(tt.expectRemediate))
+			if tt.expectNextCheck {
+				g.Expect(nextCheck).To(BeNumerically(">", 0))
+				g.Expect(nextCheck).To(BeNumerically("<=", tt.expectedMaxCheck))
+			} else {
+				g.Expect(nextCheck).To(BeZero())
+			}
+		})
+	}
+}
+
+func TestMinDuration(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(minDuration(nil)).To(BeZero())
+	g.Expect(minDuration([]time.Duration{time.Minute, time.Second, time.Hour})).To(Equal(time.Second))
+}
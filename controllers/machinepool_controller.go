@@ -17,12 +17,25 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"sync"
+
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -34,15 +47,20 @@ import (
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch;create;update;patch;delete
 
-// MachinePoolReconciler reconciles a MachineSet object
+// MachinePoolReconciler reconciles a MachinePool object
 type MachinePoolReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
-	config     *rest.Config
-	controller controller.Controller
-	recorder   record.EventRecorder
-	scheme     *runtime.Scheme
+	// Tracker, if set, provides cached clients for the workload clusters.
+	Tracker *remote.ClusterCacheTracker
+
+	config           *rest.Config
+	controller       controller.Controller
+	recorder         record.EventRecorder
+	externalWatchers sync.Map
+	scheme           *runtime.Scheme
+	remoteClient     func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
 }
 
 func (r *MachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -59,11 +77,193 @@ func (r *MachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options contr
 	r.config = mgr.GetConfig()
 
 	r.scheme = mgr.GetScheme()
+	if r.remoteClient == nil {
+		r.remoteClient = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClient = r.Tracker.ClusterClient
+		}
+	}
 	return nil
 }
 
-func (r *MachinePoolReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	// TODO(juan-lee): Add machine pool implementation.
-	// TODO(vincepri): Add support for pause annotation and honoring Cluster.Spec.Paused.
+func (r *MachinePoolReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.Background()
+	logger := r.Log.WithValues("machinepool", req.Name, "namespace", req.Namespace)
+
+	// Fetch the MachinePool instance
+	mp := &clusterv1.MachinePool{}
+	if err := r.Client.Get(ctx, req.NamespacedName, mp); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return ctrl.Result{}, nil
+		}
+
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, mp.ObjectMeta.Namespace, mp.Spec.ClusterName)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get cluster %q for machinepool %q in namespace %q",
+			mp.Spec.ClusterName, mp.Name, mp.Namespace)
+	}
+
+	// Return early if the object or Cluster is paused.
+	if util.IsPaused(cluster, mp) {
+		logger.V(3).Info("reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper
+	patchHelper, err := patch.NewHelper(mp, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		r.reconcilePhase(mp)
+
+		// Always attempt to patch the object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, mp); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Reconcile labels.
+	if mp.Labels == nil {
+		mp.Labels = make(map[string]string)
+	}
+	mp.Labels[clusterv1.ClusterLabelName] = mp.Spec.ClusterName
+
+	// Handle deletion reconciliation loop.
+	if !mp.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, cluster, mp)
+	}
+
+	// Handle normal reconciliation loop.
+	return r.reconcile(ctx, cluster, mp)
+}
+
+func (r *MachinePoolReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, mp *clusterv1.MachinePool) (ctrl.Result, error) {
+	logger := r.Log.WithValues("machinepool", mp.Name, "namespace", mp.Namespace)
+	logger = logger.WithValues("cluster", cluster.Name)
+
+	// Ensure the MachinePool is owned by the Cluster it belongs to.
+	mp.OwnerReferences = util.EnsureOwnerRef(mp.OwnerReferences, metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	})
+
+	// If the MachinePool doesn't have a finalizer, add one.
+	if !util.Contains(mp.Finalizers, clusterv1.MachinePoolFinalizer) {
+		mp.Finalizers = append(mp.Finalizers, clusterv1.MachinePoolFinalizer)
+	}
+
+	// Call the inner reconciliation methods.
+	reconciliationErrors := []error{
+		r.reconcileBootstrap(ctx, mp),
+		r.reconcileInfrastructure(ctx, mp),
+		r.reconcileNodeRefs(ctx, cluster, mp),
+	}
+
+	// Parse the errors, making sure we record if there is a RequeueAfterError.
+	res := ctrl.Result{}
+	errs := []error{}
+	for _, err := range reconciliationErrors {
+		if requeueErr, ok := errors.Cause(err).(capierrors.HasRequeueAfterError); ok {
+			// Only record and log the first RequeueAfterError.
+			if !res.Requeue {
+				res.Requeue = true
+				res.RequeueAfter = requeueErr.GetRequeueAfter()
+				logger.Error(err, "Reconciliation for MachinePool asked to requeue")
+			}
+			continue
+		}
+
+		errs = append(errs, err)
+	}
+	return res, kerrors.NewAggregate(errs)
+}
+
+func (r *MachinePoolReconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Cluster, mp *clusterv1.MachinePool) (ctrl.Result, error) {
+	if ok, err := r.reconcileDeleteExternal(ctx, mp); !ok || err != nil {
+		// Return early and don't remove the finalizer if we got an error or
+		// the external reconciliation deletion isn't ready.
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileDeleteNodes(ctx, cluster, mp); err != nil {
+		// Return early and don't remove the finalizer if we got an error.
+		return ctrl.Result{}, err
+	}
+
+	mp.ObjectMeta.Finalizers = util.Filter(mp.ObjectMeta.Finalizers, clusterv1.MachinePoolFinalizer)
 	return ctrl.Result{}, nil
 }
+
+// reconcileDeleteNodes deletes the Nodes of the workload cluster backing the instances of the MachinePool.
+func (r *MachinePoolReconciler) reconcileDeleteNodes(ctx context.Context, cluster *clusterv1.Cluster, mp *clusterv1.MachinePool) error {
+	if len(mp.Status.NodeRefs) == 0 {
+		return nil
+	}
+
+	clusterClient, err := r.remoteClient(r.Client, cluster, r.scheme)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, nodeRef := range mp.Status.NodeRefs {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeRef.Name,
+			},
+		}
+		if err := clusterClient.Delete(ctx, node); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "error deleting node %s", nodeRef.Name))
+			continue
+		}
+		r.recorder.Eventf(mp, corev1.EventTypeNormal, "SuccessfulDeleteNode", "Deleted MachinePool's node %q", nodeRef.Name)
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// reconcileDeleteExternal tries to delete external references, returning true if it cannot find any.
+func (r *MachinePoolReconciler) reconcileDeleteExternal(ctx context.Context, mp *clusterv1.MachinePool) (bool, error) {
+	objects := []*unstructured.Unstructured{}
+	references := []*corev1.ObjectReference{
+		mp.Spec.Template.Spec.Bootstrap.ConfigRef,
+		&mp.Spec.Template.Spec.InfrastructureRef,
+	}
+
+	// Loop over the references and try to retrieve it with the client.
+	for _, ref := range references {
+		if ref == nil {
+			continue
+		}
+
+		obj, err := external.Get(ctx, r.Client, ref, mp.Namespace)
+		if err != nil && !apierrors.IsNotFound(errors.Cause(err)) {
+			return false, errors.Wrapf(err, "failed to get %s %q for MachinePool %q in namespace %q",
+				ref.GroupVersionKind(), ref.Name, mp.Name, mp.Namespace)
+		}
+		if obj != nil {
+			objects = append(objects, obj)
+		}
+	}
+
+	// Issue a delete request for any object that has been found.
+	for _, obj := range objects {
+		if err := r.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return false, errors.Wrapf(err,
+				"failed to delete %v %q for MachinePool %q in namespace %q",
+				obj.GroupVersionKind(), obj.GetName(), mp.Name, mp.Namespace)
+		}
+	}
+
+	// Return true if there are no more external objects.
+	return len(objects) == 0, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	apicorev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getNodeReferencesResult holds the Nodes matching the ProviderIDs of a MachinePool.
+type getNodeReferencesResult struct {
+	references []apicorev1.ObjectReference
+	available  int
+	ready      int
+}
+
+func (r *MachinePoolReconciler) reconcileNodeRefs(ctx context.Context, cluster *clusterv1.Cluster, mp *clusterv1.MachinePool) error {
+	logger := r.Log.WithValues("machinepool", mp.Name, "namespace", mp.Namespace)
+	// Check that the MachinePool hasn't been deleted or in the process.
+	if !mp.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	// Check that Cluster isn't nil.
+	if cluster == nil {
+		logger.V(2).Info("MachinePool doesn't have a linked cluster, won't assign NodeRef")
+		return nil
+	}
+
+	logger = logger.WithValues("cluster", cluster.Name)
+
+	// Check that the MachinePool has valid ProviderIDs.
+	if len(mp.Spec.ProviderIDs) == 0 {
+		logger.V(2).Info("MachinePool doesn't have any ProviderIDs yet")
+		return nil
+	}
+
+	clusterClient, err := r.remoteClient(r.Client, cluster, r.scheme)
+	if err != nil {
+		return err
+	}
+
+	nodeRefsResult, err := r.getNodeReferences(ctx, clusterClient, mp.Spec.ProviderIDs, mp.Spec.MinReadySeconds)
+	if err != nil {
+		logger.Error(err, "Failed to get node references")
+		r.recorder.Event(mp, apicorev1.EventTypeWarning, "FailedSetNodeRefs", err.Error())
+		return err
+	}
+
+	if len(nodeRefsResult.references) != len(mp.Status.NodeRefs) {
+		logger.Info("Set MachinePool's NodeRefs", "noderefs", len(nodeRefsResult.references))
+		r.recorder.Eventf(mp, apicorev1.EventTypeNormal, "SuccessfulSetNodeRefs", "%d node(s) found", len(nodeRefsResult.references))
+	}
+
+	mp.Status.ReadyReplicas = int32(nodeRefsResult.ready)
+	mp.Status.AvailableReplicas = int32(nodeRefsResult.available)
+	mp.Status.UnavailableReplicas = mp.Status.Replicas - mp.Status.AvailableReplicas
+	mp.Status.NodeRefs = nodeRefsResult.references
+
+	if len(mp.Status.NodeRefs) != len(mp.Spec.ProviderIDs) || mp.Status.ReadyReplicas != mp.Status.Replicas {
+		return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 10 * time.Second},
+			"NodeRefs for MachinePool %q in namespace %q are not ready yet, %d of %d found", mp.Name, mp.Namespace,
+			len(mp.Status.NodeRefs), len(mp.Spec.ProviderIDs))
+	}
+	return nil
+}
+
+// getNodeReferences returns the references to the Nodes of the workload cluster matching the given ProviderIDs,
+// as well as the number of ready and available Nodes among them.
+func (r *MachinePoolReconciler) getNodeReferences(ctx context.Context, c client.Client, providerIDList []string, minReadySeconds *int32) (getNodeReferencesResult, error) {
+	logger := r.Log.WithValues("providerIDList", len(providerIDList))
+
+	var ready, available int
+	nodeRefsMap := make(map[string]apicorev1.Node)
+	nodeList := apicorev1.NodeList{}
+	for {
+		if err := c.List(ctx, &nodeList, client.Continue(nodeList.Continue)); err != nil {
+			return getNodeReferencesResult{}, errors.Wrapf(err, "failed to List nodes")
+		}
+
+		for _, node := range nodeList.Items {
+			nodeProviderID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
+			if err != nil {
+				logger.V(2).Info("Failed to parse ProviderID, skipping", "err", err, "providerID", node.Spec.ProviderID)
+				continue
+			}
+
+			nodeRefsMap[nodeProviderID.ID()] = node
+		}
+
+		if nodeList.Continue == "" {
+			break
+		}
+	}
+
+	var minReady int32
+	if minReadySeconds != nil {
+		minReady = *minReadySeconds
+	}
+	now := metav1.Now()
+
+	var nodeRefs []apicorev1.ObjectReference
+	for _, providerID := range providerIDList {
+		pid, err := noderefutil.NewProviderID(providerID)
+		if err != nil {
+			logger.V(2).Info("Failed to parse ProviderID, skipping", "err", err, "providerID", providerID)
+			continue
+		}
+		if node, ok := nodeRefsMap[pid.ID()]; ok {
+			if noderefutil.IsNodeReady(&node) {
+				ready++
+			}
+			if noderefutil.IsNodeAvailable(&node, minReady, now) {
+				available++
+			}
+			nodeRefs = append(nodeRefs, apicorev1.ObjectReference{
+				Kind:       node.Kind,
+				APIVersion: node.APIVersion,
+				Name:       node.Name,
+				UID:        node.UID,
+			})
+		}
+	}
+
+	return getNodeReferencesResult{nodeRefs, available, ready}, nil
+}
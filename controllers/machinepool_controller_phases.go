@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func (r *MachinePoolReconciler) reconcilePhase(mp *clusterv1.MachinePool) {
+	// Set the phase to "pending" if nil.
+	if mp.Status.Phase == "" {
+		mp.Status.SetTypedPhase(clusterv1.MachinePoolPhasePending)
+	}
+
+	// Set the phase to "provisioning" if bootstrap is ready and the infrastructure isn't.
+	if mp.Status.BootstrapReady && !mp.Status.InfrastructureReady {
+		mp.Status.SetTypedPhase(clusterv1.MachinePoolPhaseProvisioning)
+	}
+
+	// Set the phase to "provisioned" if the infrastructure is ready.
+	if mp.Status.InfrastructureReady {
+		mp.Status.SetTypedPhase(clusterv1.MachinePoolPhaseProvisioned)
+	}
+
+	// Set the phase to "running" if the infrastructure is ready and all the desired replicas are backed by ready Nodes.
+	if mp.Status.InfrastructureReady && mp.Spec.Replicas != nil && *mp.Spec.Replicas == mp.Status.ReadyReplicas &&
+		len(mp.Status.NodeRefs) == int(mp.Status.ReadyReplicas) {
+		mp.Status.SetTypedPhase(clusterv1.MachinePoolPhaseRunning)
+	}
+
+	// Set the phase to "failed" if any of Status.FailureReason or Status.FailureMessage is not-nil.
+	if mp.Status.FailureReason != nil || mp.Status.FailureMessage != nil {
+		mp.Status.SetTypedPhase(clusterv1.MachinePoolPhaseFailed)
+	}
+
+	// Set the phase to "deleting" if the deletion timestamp is set.
+	if !mp.DeletionTimestamp.IsZero() {
+		mp.Status.SetTypedPhase(clusterv1.MachinePoolPhaseDeleting)
+	}
+}
+
+// reconcileExternal handles generic unstructured objects referenced by a MachinePool.
+func (r *MachinePoolReconciler) reconcileExternal(ctx context.Context, mp *clusterv1.MachinePool, ref *corev1.ObjectReference) (*unstructured.Unstructured, error) {
+	logger := r.Log.WithValues("machinepool", mp.Name, "namespace", mp.Namespace)
+
+	obj, err := external.Get(ctx, r.Client, ref, mp.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil, errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: externalReadyWait},
+				"could not find %v %q for MachinePool %q in namespace %q, requeuing",
+				ref.GroupVersionKind(), ref.Name, mp.Name, mp.Namespace)
+		}
+		return nil, err
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(obj, r.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set external object OwnerReference to the MachinePool.
+	ownerRef := metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "MachinePool",
+		Name:       mp.Name,
+		UID:        mp.UID,
+	}
+
+	// Add ownerRef to object.
+	obj.SetOwnerReferences(util.EnsureOwnerRef(obj.GetOwnerReferences(), ownerRef))
+
+	// Set the Cluster label.
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[clusterv1.ClusterLabelName] = mp.Spec.ClusterName
+	obj.SetLabels(labels)
+
+	// Always attempt to Patch the external object.
+	if err := patchHelper.Patch(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	// Add watcher for external object, if there isn't one already.
+	_, loaded := r.externalWatchers.LoadOrStore(obj.GroupVersionKind().String(), struct{}{})
+	if !loaded && r.controller != nil {
+		logger.Info("Adding watcher on external object", "gvk", obj.GroupVersionKind())
+		err := r.controller.Watch(
+			&source.Kind{Type: obj},
+			&handler.EnqueueRequestForOwner{OwnerType: &clusterv1.MachinePool{}},
+		)
+		if err != nil {
+			r.externalWatchers.Delete(obj.GroupVersionKind().String())
+			return nil, errors.Wrapf(err, "failed to add watcher on external object %q", obj.GroupVersionKind())
+		}
+	}
+
+	// Set failure reason and message, if any.
+	failureReason, failureMessage, err := external.FailuresFrom(obj)
+	if err != nil {
+		return nil, err
+	}
+	if failureReason != "" {
+		machinePoolStatusError := capierrors.MachinePoolStatusError(failureReason)
+		mp.Status.FailureReason = &machinePoolStatusError
+	}
+	if failureMessage != "" {
+		mp.Status.FailureMessage = pointer.StringPtr(
+			fmt.Sprintf("Failure detected from referenced resource %v with name %q: %s",
+				obj.GroupVersionKind(), obj.GetName(), failureMessage),
+		)
+	}
+
+	return obj, nil
+}
+
+// reconcileBootstrap reconciles the Spec.Template.Spec.Bootstrap.ConfigRef object on a MachinePool.
+func (r *MachinePoolReconciler) reconcileBootstrap(ctx context.Context, mp *clusterv1.MachinePool) error {
+	// Call generic external reconciler if we have an external reference.
+	var bootstrapConfig *unstructured.Unstructured
+	if mp.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
+		var err error
+		bootstrapConfig, err = r.reconcileExternal(ctx, mp, mp.Spec.Template.Spec.Bootstrap.ConfigRef)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If the bootstrap data is populated, set ready and return.
+	if mp.Spec.Template.Spec.Bootstrap.Data != nil || mp.Spec.Template.Spec.Bootstrap.DataSecretName != nil {
+		mp.Status.BootstrapReady = true
+		return nil
+	}
+
+	// If there is no bootstrap config to wait for, return early.
+	if bootstrapConfig == nil {
+		return nil
+	}
+
+	// If the bootstrap config is being deleted, return early.
+	if !bootstrapConfig.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+
+	// Determine if the bootstrap provider is ready.
+	ready, err := external.IsReady(bootstrapConfig)
+	if err != nil {
+		return err
+	} else if !ready {
+		return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: externalReadyWait},
+			"Bootstrap provider for MachinePool %q in namespace %q is not ready, requeuing", mp.Name, mp.Namespace)
+	}
+
+	// Get and set the name of the secret containing the bootstrap data.
+	secretName, _, err := unstructured.NestedString(bootstrapConfig.Object, "status", "dataSecretName")
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve dataSecretName from bootstrap provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	} else if secretName == "" {
+		return errors.Errorf("retrieved empty dataSecretName from bootstrap provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+
+	mp.Spec.Template.Spec.Bootstrap.DataSecretName = pointer.StringPtr(secretName)
+	mp.Status.BootstrapReady = true
+	return nil
+}
+
+// reconcileInfrastructure reconciles the Spec.Template.Spec.InfrastructureRef object on a MachinePool.
+func (r *MachinePoolReconciler) reconcileInfrastructure(ctx context.Context, mp *clusterv1.MachinePool) error {
+	// Call generic external reconciler.
+	infraConfig, err := r.reconcileExternal(ctx, mp, &mp.Spec.Template.Spec.InfrastructureRef)
+	if infraConfig == nil && err == nil {
+		return nil
+	} else if err != nil {
+		if mp.Status.InfrastructureReady && strings.Contains(err.Error(), "could not find") {
+			// Infra object went missing after the machine pool was up and running
+			r.Log.Error(err, "MachinePool infrastructure reference has been deleted after being ready, setting failure state")
+			mp.Status.FailureReason = capierrors.MachinePoolStatusErrorPtr(capierrors.InvalidConfigurationMachinePoolError)
+			mp.Status.FailureMessage = pointer.StringPtr(fmt.Sprintf("MachinePool infrastructure resource %v with name %q has been deleted after being ready",
+				mp.Spec.Template.Spec.InfrastructureRef.GroupVersionKind(), mp.Spec.Template.Spec.InfrastructureRef.Name))
+		}
+		return err
+	}
+
+	if !infraConfig.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+
+	// Determine if the infrastructure provider is ready.
+	ready, err := external.IsReady(infraConfig)
+	if err != nil {
+		return err
+	}
+	mp.Status.InfrastructureReady = ready
+	if !ready {
+		return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: externalReadyWait},
+			"Infrastructure provider for MachinePool %q in namespace %q is not ready, requeuing", mp.Name, mp.Namespace,
+		)
+	}
+
+	// Get Spec.ProviderIDList from the infrastructure provider.
+	var providerIDList []string
+	if err := util.UnstructuredUnmarshalField(infraConfig, &providerIDList, "spec", "providerIDList"); err != nil && err != util.ErrUnstructuredFieldNotFound {
+		return errors.Wrapf(err, "failed to retrieve Spec.ProviderIDList from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+
+	// Get and set Status.Replicas from the infrastructure provider.
+	err = util.UnstructuredUnmarshalField(infraConfig, &mp.Status.Replicas, "status", "replicas")
+	if err != nil {
+		if err != util.ErrUnstructuredFieldNotFound {
+			return errors.Wrapf(err, "failed to retrieve replicas from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+		}
+		mp.Status.Replicas = int32(len(providerIDList))
+	}
+
+	mp.Spec.ProviderIDs = providerIDList
+	return nil
+}
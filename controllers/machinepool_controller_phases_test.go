@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+func TestReconcileMachinePoolPhases(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	readyBootstrap := func() *unstructured.Unstructured {
+		bootstrapConfig := newTestMachinePoolExternal("bootstrap.cluster.x-k8s.io/v1alpha3", "BootstrapConfig", "bootstrap-config1")
+		bootstrapConfig.Object["status"] = map[string]interface{}{
+			"ready":          true,
+			"dataSecretName": "secret-data",
+		}
+		return bootstrapConfig
+	}
+
+	readyInfra := func() *unstructured.Unstructured {
+		infraConfig := newTestMachinePoolExternal("infrastructure.cluster.x-k8s.io/v1alpha3", "InfrastructureConfig", "infra-config1")
+		infraConfig.Object["spec"] = map[string]interface{}{
+			"providerIDList": []interface{}{
+				"aws:///us-east-1a/id-1",
+				"aws:///us-east-1a/id-2",
+			},
+		}
+		infraConfig.Object["status"] = map[string]interface{}{
+			"ready":    true,
+			"replicas": int64(2),
+		}
+		return infraConfig
+	}
+
+	readyNode := func(name, providerID string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{ProviderID: providerID},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	t.Run("should set `Pending` with a new MachinePool", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		r := newTestMachinePoolReconciler([]runtime.Object{
+			cluster,
+			mp,
+			newTestMachinePoolExternal("bootstrap.cluster.x-k8s.io/v1alpha3", "BootstrapConfig", "bootstrap-config1"),
+			newTestMachinePoolExternal("infrastructure.cluster.x-k8s.io/v1alpha3", "InfrastructureConfig", "infra-config1"),
+		}, nil)
+
+		res, err := r.reconcile(context.Background(), cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(res.Requeue).To(BeTrue())
+
+		r.reconcilePhase(mp)
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhasePending))
+	})
+
+	t.Run("should set `Provisioning` when bootstrap is ready", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		r := newTestMachinePoolReconciler([]runtime.Object{
+			cluster,
+			mp,
+			readyBootstrap(),
+			newTestMachinePoolExternal("infrastructure.cluster.x-k8s.io/v1alpha3", "InfrastructureConfig", "infra-config1"),
+		}, nil)
+
+		res, err := r.reconcile(context.Background(), cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(res.Requeue).To(BeTrue())
+
+		r.reconcilePhase(mp)
+		g.Expect(mp.Status.BootstrapReady).To(BeTrue())
+		g.Expect(mp.Spec.Template.Spec.Bootstrap.DataSecretName).To(Equal(pointer.StringPtr("secret-data")))
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhaseProvisioning))
+	})
+
+	t.Run("should set `Provisioned` when infrastructure is ready but the nodes aren't", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		r := newTestMachinePoolReconciler(
+			[]runtime.Object{cluster, mp, readyBootstrap(), readyInfra()},
+			[]runtime.Object{readyNode("node-1", "aws:///us-east-1a/id-1")},
+		)
+
+		res, err := r.reconcile(context.Background(), cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(res.Requeue).To(BeTrue())
+
+		r.reconcilePhase(mp)
+		g.Expect(mp.Status.InfrastructureReady).To(BeTrue())
+		g.Expect(mp.Spec.ProviderIDs).To(ConsistOf("aws:///us-east-1a/id-1", "aws:///us-east-1a/id-2"))
+		g.Expect(mp.Status.Replicas).To(Equal(int32(2)))
+		g.Expect(mp.Status.ReadyReplicas).To(Equal(int32(1)))
+		g.Expect(mp.Status.UnavailableReplicas).To(Equal(int32(1)))
+		g.Expect(mp.Status.NodeRefs).To(HaveLen(1))
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhaseProvisioned))
+	})
+
+	t.Run("should set `Running` when all the nodes are ready", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		r := newTestMachinePoolReconciler(
+			[]runtime.Object{cluster, mp, readyBootstrap(), readyInfra()},
+			[]runtime.Object{
+				readyNode("node-1", "aws:///us-east-1a/id-1"),
+				readyNode("node-2", "aws:///us-east-1a/id-2"),
+				readyNode("other-node", "aws:///us-east-1a/id-3"),
+			},
+		)
+
+		res, err := r.reconcile(context.Background(), cluster, mp)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(res.Requeue).To(BeFalse())
+
+		r.reconcilePhase(mp)
+		g.Expect(mp.Status.ReadyReplicas).To(Equal(int32(2)))
+		g.Expect(mp.Status.AvailableReplicas).To(Equal(int32(2)))
+		g.Expect(mp.Status.UnavailableReplicas).To(BeZero())
+		g.Expect(mp.Status.NodeRefs).To(HaveLen(2))
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhaseRunning))
+	})
+
+	t.Run("should set `Failed` when the infrastructure has failed", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		infraConfig := readyInfra()
+		infraConfig.Object["status"].(map[string]interface{})["failureReason"] = "InvalidConfiguration"
+		infraConfig.Object["status"].(map[string]interface{})["failureMessage"] = "invalid instance type"
+
+		mp := newTestMachinePool()
+		r := newTestMachinePoolReconciler([]runtime.Object{cluster, mp, readyBootstrap(), infraConfig}, nil)
+
+		_, _ = r.reconcile(context.Background(), cluster, mp)
+
+		r.reconcilePhase(mp)
+		g.Expect(mp.Status.FailureReason).To(Equal(capierrors.MachinePoolStatusErrorPtr(capierrors.InvalidConfigurationMachinePoolError)))
+		g.Expect(*mp.Status.FailureMessage).To(ContainSubstring("invalid instance type"))
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhaseFailed))
+	})
+
+	t.Run("should set failure when the infrastructure is deleted after being ready", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		mp.Status.InfrastructureReady = true
+		r := newTestMachinePoolReconciler([]runtime.Object{cluster, mp, readyBootstrap()}, nil)
+
+		err := r.reconcileInfrastructure(context.Background(), mp)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(mp.Status.FailureReason).NotTo(BeNil())
+		g.Expect(mp.Status.FailureMessage).NotTo(BeNil())
+	})
+
+	t.Run("should not fail when there is neither a bootstrap config nor bootstrap data", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		mp.Spec.Template.Spec.Bootstrap.ConfigRef = nil
+		r := newTestMachinePoolReconciler([]runtime.Object{cluster, mp}, nil)
+
+		g.Expect(r.reconcileBootstrap(context.Background(), mp)).To(Succeed())
+		g.Expect(mp.Status.BootstrapReady).To(BeFalse())
+	})
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+var _ reconcile.Reconciler = &MachinePoolReconciler{}
+
+func newTestMachinePool() *clusterv1.MachinePool {
+	return &clusterv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machinepool-test",
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachinePoolSpec{
+			ClusterName: "test-cluster",
+			Replicas:    pointer.Int32Ptr(2),
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test-cluster",
+					Bootstrap: clusterv1.Bootstrap{
+						ConfigRef: &corev1.ObjectReference{
+							APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3",
+							Kind:       "BootstrapConfig",
+							Name:       "bootstrap-config1",
+						},
+					},
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+						Kind:       "InfrastructureConfig",
+						Name:       "infra-config1",
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestMachinePoolExternal(apiVersion, kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       kind,
+			"apiVersion": apiVersion,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"spec":   map[string]interface{}{},
+			"status": map[string]interface{}{},
+		},
+	}
+}
+
+func newTestMachinePoolReconciler(objs []runtime.Object, nodes []runtime.Object) *MachinePoolReconciler {
+	remoteClient := fake.NewFakeClientWithScheme(scheme.Scheme, nodes...)
+	return &MachinePoolReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error) {
+			return remoteClient, nil
+		},
+	}
+}
+
+func TestMachinePoolReconcile(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			UID:       "test-cluster-uid",
+		},
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "machinepool-test",
+			Namespace: "default",
+		},
+	}
+
+	t.Run("adds the finalizer, owner reference and cluster label", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		r := newTestMachinePoolReconciler([]runtime.Object{
+			cluster,
+			newTestMachinePool(),
+			newTestMachinePoolExternal("bootstrap.cluster.x-k8s.io/v1alpha3", "BootstrapConfig", "bootstrap-config1"),
+			newTestMachinePoolExternal("infrastructure.cluster.x-k8s.io/v1alpha3", "InfrastructureConfig", "infra-config1"),
+		}, nil)
+
+		result, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.Requeue).To(BeTrue())
+
+		mp := &clusterv1.MachinePool{}
+		g.Expect(r.Client.Get(ctx, request.NamespacedName, mp)).To(Succeed())
+		g.Expect(mp.Finalizers).To(ContainElement(clusterv1.MachinePoolFinalizer))
+		g.Expect(mp.Labels[clusterv1.ClusterLabelName]).To(Equal(cluster.Name))
+		g.Expect(mp.OwnerReferences).To(ContainElement(metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		}))
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhasePending))
+
+		// The external objects are owned by the MachinePool.
+		infraConfig := &unstructured.Unstructured{}
+		infraConfig.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha3")
+		infraConfig.SetKind("InfrastructureConfig")
+		g.Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "infra-config1"}, infraConfig)).To(Succeed())
+		g.Expect(infraConfig.GetOwnerReferences()).To(HaveLen(1))
+		g.Expect(infraConfig.GetOwnerReferences()[0].Kind).To(Equal("MachinePool"))
+		g.Expect(infraConfig.GetLabels()[clusterv1.ClusterLabelName]).To(Equal(cluster.Name))
+	})
+
+	t.Run("does nothing when the cluster is paused", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		pausedCluster := cluster.DeepCopy()
+		pausedCluster.Spec.Paused = true
+
+		r := newTestMachinePoolReconciler([]runtime.Object{pausedCluster, newTestMachinePool()}, nil)
+
+		result, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(reconcile.Result{}))
+
+		mp := &clusterv1.MachinePool{}
+		g.Expect(r.Client.Get(ctx, request.NamespacedName, mp)).To(Succeed())
+		g.Expect(mp.Finalizers).To(BeEmpty())
+		g.Expect(mp.Status.Phase).To(BeEmpty())
+	})
+
+	t.Run("deletes the external objects and the nodes before removing the finalizer", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+		mp := newTestMachinePool()
+		deletionTimestamp := metav1.Now()
+		mp.DeletionTimestamp = &deletionTimestamp
+		mp.Finalizers = []string{clusterv1.MachinePoolFinalizer}
+		mp.Status.NodeRefs = []corev1.ObjectReference{{Kind: "Node", Name: "node-1"}}
+
+		r := newTestMachinePoolReconciler(
+			[]runtime.Object{
+				cluster,
+				mp,
+				newTestMachinePoolExternal("bootstrap.cluster.x-k8s.io/v1alpha3", "BootstrapConfig", "bootstrap-config1"),
+				newTestMachinePoolExternal("infrastructure.cluster.x-k8s.io/v1alpha3", "InfrastructureConfig", "infra-config1"),
+			},
+			[]runtime.Object{&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}},
+		)
+
+		// The first reconciliation deletes the external objects.
+		_, err := r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(r.Client.Get(ctx, request.NamespacedName, mp)).To(Succeed())
+		g.Expect(mp.Finalizers).To(ContainElement(clusterv1.MachinePoolFinalizer))
+		g.Expect(mp.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePoolPhaseDeleting))
+
+		infraConfig := &unstructured.Unstructured{}
+		infraConfig.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha3")
+		infraConfig.SetKind("InfrastructureConfig")
+		err = r.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "infra-config1"}, infraConfig)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		// The second one deletes the nodes and removes the finalizer.
+		_, err = r.Reconcile(request)
+		g.Expect(err).NotTo(HaveOccurred())
+		mp = &clusterv1.MachinePool{}
+		g.Expect(r.Client.Get(ctx, request.NamespacedName, mp)).To(Succeed())
+		g.Expect(mp.Finalizers).NotTo(ContainElement(clusterv1.MachinePoolFinalizer))
+
+		remoteClient, err := r.remoteClient(r.Client, cluster, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		err = remoteClient.Get(ctx, types.NamespacedName{Name: "node-1"}, &corev1.Node{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -38,6 +39,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -69,8 +71,12 @@ type MachineSetReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
-	recorder record.EventRecorder
-	scheme   *runtime.Scheme
+	// Tracker, if set, provides cached clients for the workload clusters.
+	Tracker *remote.ClusterCacheTracker
+
+	recorder     record.EventRecorder
+	scheme       *runtime.Scheme
+	remoteClient func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
 }
 
 func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -90,6 +96,12 @@ func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options contro
 
 	r.recorder = mgr.GetEventRecorderFor("machineset-controller")
 	r.scheme = mgr.GetScheme()
+	if r.remoteClient == nil {
+		r.remoteClient = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClient = r.Tracker.ClusterClient
+		}
+	}
 	return nil
 }
 
@@ -219,9 +231,11 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		filteredMachines = append(filteredMachines, machine)
 	}
 
-	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
-
+	// Work on a copy of the MachineSet, so the conditions changed while syncing the replicas
+	// are detected when patching the status.
 	ms := machineSet.DeepCopy()
+	syncErr := r.syncReplicas(ctx, ms, filteredMachines)
+
 	newStatus, err := r.calculateStatus(ctx, cluster, ms, filteredMachines)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to calculate MachineSet's Status")
@@ -307,6 +321,10 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 
 	if diff < 0 {
 		diff *= -1
+		if ms.Annotations[clusterv1.DisableMachineCreateAnnotation] == "true" {
+			logger.V(2).Info("Too few replicas, but the creation of new machines is disabled", "need", *(ms.Spec.Replicas), "missing", diff)
+			return nil
+		}
 		logger.Info("Too few replicas", "need", *(ms.Spec.Replicas), "creating", diff)
 
 		var machineList []*clusterv1.Machine
@@ -326,6 +344,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 			if machine.Spec.Bootstrap.ConfigRef != nil {
 				bootstrapRef, err = external.CloneTemplate(ctx, r.Client, machine.Spec.Bootstrap.ConfigRef, machine.Namespace, machine.Spec.ClusterName, nil)
 				if err != nil {
+					conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.MachineCreationFailedReason, clusterv1.ConditionSeverityError, err.Error())
 					return errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 				}
 				machine.Spec.Bootstrap.ConfigRef = bootstrapRef
@@ -333,6 +352,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 
 			infraRef, err = external.CloneTemplate(ctx, r.Client, &machine.Spec.InfrastructureRef, machine.Namespace, machine.Spec.ClusterName, nil)
 			if err != nil {
+				conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.MachineCreationFailedReason, clusterv1.ConditionSeverityError, err.Error())
 				return errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 			}
 			machine.Spec.InfrastructureRef = *infraRef
@@ -368,9 +388,11 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		}
 
 		if len(errstrings) > 0 {
+			conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.MachineCreationFailedReason, clusterv1.ConditionSeverityError, strings.Join(errstrings, "; "))
 			return errors.New(strings.Join(errstrings, "; "))
 		}
 
+		conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 		return r.waitForMachineCreation(machineList)
 	} else if diff > 0 {
 		logger.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
@@ -643,6 +665,34 @@ func (r *MachineSetReconciler) calculateStatus(ctx context.Context, cluster *clu
 	newStatus.FullyLabeledReplicas = int32(fullyLabeledReplicasCount)
 	newStatus.ReadyReplicas = int32(readyReplicasCount)
 	newStatus.AvailableReplicas = int32(availableReplicasCount)
+
+	// Report whether the MachineSet is resizing and whether all its Machines are ready.
+	var replicas int32
+	if ms.Spec.Replicas != nil {
+		replicas = *ms.Spec.Replicas
+	}
+	switch {
+	case newStatus.Replicas < replicas:
+		conditions.MarkFalse(ms, clusterv1.ResizedCondition, clusterv1.ScalingUpReason, clusterv1.ConditionSeverityInfo, "Scaling up MachineSet to %d replicas (actual %d)", replicas, newStatus.Replicas)
+	case newStatus.Replicas > replicas:
+		conditions.MarkFalse(ms, clusterv1.ResizedCondition, clusterv1.ScalingDownReason, clusterv1.ConditionSeverityInfo, "Scaling down MachineSet to %d replicas (actual %d)", replicas, newStatus.Replicas)
+	default:
+		conditions.MarkTrue(ms, clusterv1.ResizedCondition)
+	}
+	if newStatus.ReadyReplicas < newStatus.Replicas {
+		conditions.MarkFalse(ms, clusterv1.MachinesReadyCondition, clusterv1.MachinesNotReadyReason, clusterv1.ConditionSeverityWarning, "%d of %d machines are not ready", newStatus.Replicas-newStatus.ReadyReplicas, newStatus.Replicas)
+	} else {
+		conditions.MarkTrue(ms, clusterv1.MachinesReadyCondition)
+	}
+	conditions.SetSummary(ms,
+		conditions.WithConditions(
+			clusterv1.MachinesCreatedCondition,
+			clusterv1.ResizedCondition,
+			clusterv1.MachinesReadyCondition,
+		),
+	)
+	newStatus.Conditions = ms.Status.Conditions
+
 	return newStatus, nil
 }
 
@@ -657,6 +707,7 @@ func (r *MachineSetReconciler) patchMachineSetStatus(ctx context.Context, ms *cl
 		ms.Status.FullyLabeledReplicas == newStatus.FullyLabeledReplicas &&
 		ms.Status.ReadyReplicas == newStatus.ReadyReplicas &&
 		ms.Status.AvailableReplicas == newStatus.AvailableReplicas &&
+		equality.Semantic.DeepEqual(ms.Status.Conditions, newStatus.Conditions) &&
 		ms.Generation == ms.Status.ObservedGeneration {
 		return ms, nil
 	}
@@ -697,7 +748,7 @@ func (r *MachineSetReconciler) patchMachineSetStatus(ctx context.Context, ms *cl
 }
 
 func (r *MachineSetReconciler) getMachineNode(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) (*corev1.Node, error) {
-	c, err := remote.NewClusterClient(r.Client, cluster, r.scheme)
+	c, err := r.remoteClient(r.Client, cluster, r.scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -21,8 +21,10 @@ import (
 	"sort"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 type (
@@ -33,9 +35,12 @@ type (
 const (
 	// DeleteNodeAnnotation marks nodes that will be given priority for deletion
 	// when a machineset scales down. This annotation is given top priority on all delete policies.
+	//
+	// Deprecated: use clusterv1.DeleteMachineAnnotation instead.
 	DeleteNodeAnnotation = "cluster.k8s.io/delete-machine"
 
 	mustDelete    deletePriority = 100.0
+	shouldDelete  deletePriority = 75.0
 	betterDelete  deletePriority = 50.0
 	couldDelete   deletePriority = 20.0
 	mustNotDelete deletePriority = 0.0
@@ -43,16 +48,16 @@ const (
 	secondsPerTenDays float64 = 864000
 )
 
-// maps the creation timestamp onto the 0-100 priority range
+// maps the creation timestamp onto the 0-50 priority range
 func oldestDeletePriority(machine *clusterv1.Machine) deletePriority {
 	if !machine.DeletionTimestamp.IsZero() {
 		return mustDelete
 	}
-	if machine.ObjectMeta.Annotations != nil && machine.ObjectMeta.Annotations[DeleteNodeAnnotation] != "" {
-		return mustDelete
+	if isMachineMarkedForDeletion(machine) {
+		return shouldDelete
 	}
-	if machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil {
-		return mustDelete
+	if !isMachineHealthy(machine) {
+		return betterDelete
 	}
 	if machine.ObjectMeta.CreationTimestamp.Time.IsZero() {
 		return mustNotDelete
@@ -61,35 +66,59 @@ func oldestDeletePriority(machine *clusterv1.Machine) deletePriority {
 	if d.Seconds() < 0 {
 		return mustNotDelete
 	}
-	return deletePriority(float64(mustDelete) * (1.0 - math.Exp(-d.Seconds()/secondsPerTenDays)))
+	return deletePriority(float64(betterDelete) * (1.0 - math.Exp(-d.Seconds()/secondsPerTenDays)))
 }
 
 func newestDeletePriority(machine *clusterv1.Machine) deletePriority {
 	if !machine.DeletionTimestamp.IsZero() {
 		return mustDelete
 	}
-	if machine.ObjectMeta.Annotations != nil && machine.ObjectMeta.Annotations[DeleteNodeAnnotation] != "" {
-		return mustDelete
+	if isMachineMarkedForDeletion(machine) {
+		return shouldDelete
 	}
-	if machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil {
-		return mustDelete
+	if !isMachineHealthy(machine) {
+		return betterDelete
 	}
-	return mustDelete - oldestDeletePriority(machine)
+	return betterDelete - oldestDeletePriority(machine)
 }
 
 func randomDeletePolicy(machine *clusterv1.Machine) deletePriority {
 	if !machine.DeletionTimestamp.IsZero() {
 		return mustDelete
 	}
-	if machine.ObjectMeta.Annotations != nil && machine.ObjectMeta.Annotations[DeleteNodeAnnotation] != "" {
-		return betterDelete
+	if isMachineMarkedForDeletion(machine) {
+		return shouldDelete
 	}
-	if machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil {
+	if !isMachineHealthy(machine) {
 		return betterDelete
 	}
 	return couldDelete
 }
 
+// isMachineMarkedForDeletion returns true if the machine has been annotated
+// to be deleted first when the machineset scales down.
+func isMachineMarkedForDeletion(machine *clusterv1.Machine) bool {
+	if _, ok := machine.ObjectMeta.Annotations[clusterv1.DeleteMachineAnnotation]; ok {
+		return true
+	}
+	return machine.ObjectMeta.Annotations[DeleteNodeAnnotation] != ""
+}
+
+// isMachineHealthy returns false if the machine has failed, has not been assigned a node yet,
+// or its node is not healthy.
+func isMachineHealthy(machine *clusterv1.Machine) bool {
+	if machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil {
+		return false
+	}
+	if machine.Status.NodeRef == nil {
+		return false
+	}
+	if c := conditions.Get(machine, clusterv1.NodeHealthyCondition); c != nil && c.Status != corev1.ConditionTrue {
+		return false
+	}
+	return true
+}
+
 type sortableMachines struct {
 	machines []*clusterv1.Machine
 	priority deletePriorityFunc
@@ -20,10 +20,12 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestMachineToDelete(t *testing.T) {
@@ -31,7 +33,18 @@ func TestMachineToDelete(t *testing.T) {
 	now := metav1.Now()
 	mustDeleteMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}
 	betterDeleteMachine := &clusterv1.Machine{Status: clusterv1.MachineStatus{FailureMessage: &msg}}
-	deleteMeMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DeleteNodeAnnotation: "yes"}}}
+	deleteMeMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: "yes"}}}
+	deprecatedDeleteMeMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DeleteNodeAnnotation: "yes"}}}
+	healthyMachine := &clusterv1.Machine{Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "some-node"}}}
+	nodeRefUnsetMachine := &clusterv1.Machine{}
+	nodeUnhealthyMachine := &clusterv1.Machine{
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "some-node"},
+			Conditions: clusterv1.Conditions{
+				*conditions.FalseCondition(clusterv1.NodeHealthyCondition, clusterv1.NodeConditionsFailedReason, clusterv1.ConditionSeverityWarning, ""),
+			},
+		},
+	}
 
 	tests := []struct {
 		desc     string
@@ -43,7 +56,7 @@ func TestMachineToDelete(t *testing.T) {
 			desc: "func=randomDeletePolicy, diff=0",
 			diff: 0,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 			},
 			expect: []*clusterv1.Machine{},
 		},
@@ -51,30 +64,30 @@ func TestMachineToDelete(t *testing.T) {
 			desc: "func=randomDeletePolicy, diff>len(machines)",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 			},
 			expect: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 			},
 		},
 		{
 			desc: "func=randomDeletePolicy, diff>betterDelete",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				betterDeleteMachine,
-				{},
+				healthyMachine,
 			},
 			expect: []*clusterv1.Machine{
 				betterDeleteMachine,
-				{},
+				healthyMachine,
 			},
 		},
 		{
 			desc: "func=randomDeletePolicy, diff<betterDelete",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				betterDeleteMachine,
 				betterDeleteMachine,
 				betterDeleteMachine,
@@ -88,7 +101,7 @@ func TestMachineToDelete(t *testing.T) {
 			desc: "func=randomDeletePolicy, diff<=mustDelete",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				mustDeleteMachine,
 				betterDeleteMachine,
 				mustDeleteMachine,
@@ -102,9 +115,9 @@ func TestMachineToDelete(t *testing.T) {
 			desc: "func=randomDeletePolicy, diff<=mustDelete+betterDelete",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				mustDeleteMachine,
-				{},
+				healthyMachine,
 				betterDeleteMachine,
 			},
 			expect: []*clusterv1.Machine{
@@ -116,40 +129,89 @@ func TestMachineToDelete(t *testing.T) {
 			desc: "func=randomDeletePolicy, diff<=mustDelete+betterDelete+couldDelete",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				mustDeleteMachine,
-				{},
+				healthyMachine,
 			},
 			expect: []*clusterv1.Machine{
 				mustDeleteMachine,
-				{},
+				healthyMachine,
 			},
 		},
 		{
 			desc: "func=randomDeletePolicy, diff>betterDelete",
 			diff: 2,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				betterDeleteMachine,
-				{},
+				healthyMachine,
 			},
 			expect: []*clusterv1.Machine{
 				betterDeleteMachine,
-				{},
+				healthyMachine,
 			},
 		},
 		{
 			desc: "func=randomDeletePolicy, annotated, diff=1",
 			diff: 1,
 			machines: []*clusterv1.Machine{
-				{},
+				healthyMachine,
 				deleteMeMachine,
-				{},
+				healthyMachine,
 			},
 			expect: []*clusterv1.Machine{
 				deleteMeMachine,
 			},
-		}}
+		},
+		{
+			desc: "func=randomDeletePolicy, annotated with the deprecated annotation, diff=1",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				healthyMachine,
+				deprecatedDeleteMeMachine,
+				healthyMachine,
+			},
+			expect: []*clusterv1.Machine{
+				deprecatedDeleteMeMachine,
+			},
+		},
+		{
+			desc: "func=randomDeletePolicy, annotated and unhealthy, diff=1",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				betterDeleteMachine,
+				deleteMeMachine,
+				healthyMachine,
+			},
+			expect: []*clusterv1.Machine{
+				deleteMeMachine,
+			},
+		},
+		{
+			desc: "func=randomDeletePolicy, NodeRef not set, diff=1",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				healthyMachine,
+				nodeRefUnsetMachine,
+				healthyMachine,
+			},
+			expect: []*clusterv1.Machine{
+				nodeRefUnsetMachine,
+			},
+		},
+		{
+			desc: "func=randomDeletePolicy, node unhealthy, diff=1",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				healthyMachine,
+				nodeUnhealthyMachine,
+				healthyMachine,
+			},
+			expect: []*clusterv1.Machine{
+				nodeUnhealthyMachine,
+			},
+		},
+	}
 
 	for _, test := range tests {
 		g := NewWithT(t)
@@ -163,12 +225,14 @@ func TestMachineNewestDelete(t *testing.T) {
 
 	currentTime := metav1.Now()
 	statusError := capierrors.MachineStatusError("I'm unhealthy!")
+	nodeRef := &corev1.ObjectReference{Name: "some-node"}
 	mustDeleteMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &currentTime}}
-	newest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -1))}}
-	new := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -5))}}
-	old := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
-	oldest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
-	annotatedMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DeleteNodeAnnotation: "yes"}, CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
+	newest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -1))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	new := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -5))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	old := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	oldest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	annotatedMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: "yes"}, CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	nodeRefUnsetMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
 	unhealthyMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{FailureReason: &statusError}}
 
 	tests := []struct {
@@ -217,6 +281,14 @@ func TestMachineNewestDelete(t *testing.T) {
 			},
 			expect: []*clusterv1.Machine{unhealthyMachine},
 		},
+		{
+			desc: "func=newestDeletePriority, diff=2 (annotated and NodeRef not set)",
+			diff: 2,
+			machines: []*clusterv1.Machine{
+				new, nodeRefUnsetMachine, oldest, old, newest, annotatedMachine,
+			},
+			expect: []*clusterv1.Machine{annotatedMachine, nodeRefUnsetMachine},
+		},
 	}
 
 	for _, test := range tests {
@@ -231,12 +303,14 @@ func TestMachineOldestDelete(t *testing.T) {
 
 	currentTime := metav1.Now()
 	statusError := capierrors.MachineStatusError("I'm unhealthy!")
-	empty := &clusterv1.Machine{}
-	newest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -1))}}
-	new := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -5))}}
-	old := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
-	oldest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
-	annotatedMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DeleteNodeAnnotation: "yes"}, CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
+	nodeRef := &corev1.ObjectReference{Name: "some-node"}
+	empty := &clusterv1.Machine{Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	newest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -1))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	new := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -5))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	old := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	oldest := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	annotatedMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: "yes"}, CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	nodeRefUnsetMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}}
 	unhealthyMachine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(currentTime.Time.AddDate(0, 0, -10))}, Status: clusterv1.MachineStatus{FailureReason: &statusError}}
 
 	tests := []struct {
@@ -293,6 +367,14 @@ func TestMachineOldestDelete(t *testing.T) {
 			},
 			expect: []*clusterv1.Machine{unhealthyMachine},
 		},
+		{
+			desc: "func=oldestDeletePriority, diff=1 (NodeRef not set)",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				empty, new, oldest, old, newest, nodeRefUnsetMachine,
+			},
+			expect: []*clusterv1.Machine{nodeRefUnsetMachine},
+		},
 	}
 
 	for _, test := range tests {
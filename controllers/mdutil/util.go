@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-logr/logr"
@@ -51,6 +53,10 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.k8s.io/max-replicas"
+	// RollbackToAnnotation can be set on a machine deployment to roll it back to the machine template of the
+	// machine set with the given revision, as recorded by RevisionAnnotation; "0" rolls back to the last revision.
+	// The annotation is removed once the rollback has been processed.
+	RollbackToAnnotation = "machinedeployment.clusters.k8s.io/rollback-to"
 
 	// FailedMSCreateReason is added in a machine deployment when it cannot create a new machine set.
 	FailedMSCreateReason = "MachineSetCreateError"
@@ -138,6 +144,24 @@ func MaxRevision(allMSs []*clusterv1.MachineSet, logger logr.Logger) int64 {
 	return max
 }
 
+// LastRevision finds the second max revision number in all machine sets (the last revision)
+func LastRevision(allMSs []*clusterv1.MachineSet, logger logr.Logger) int64 {
+	max, secMax := int64(0), int64(0)
+	for _, ms := range allMSs {
+		if v, err := Revision(ms); err != nil {
+			// Skip the machine sets when it failed to parse their revision information
+			logger.Error(err, "Couldn't parse revision for machine set, deployment controller will skip it when reconciling revisions",
+				"machineset", ms.Name)
+		} else if v >= max {
+			secMax = max
+			max = v
+		} else if v > secMax {
+			secMax = v
+		}
+	}
+	return secMax
+}
+
 // Revision returns the revision number of the input object.
 func Revision(obj runtime.Object) (int64, error) {
 	acc, err := meta.Accessor(obj)
@@ -157,6 +181,7 @@ var annotationsToSkip = map[string]bool{
 	RevisionHistoryAnnotation:      true,
 	DesiredReplicasAnnotation:      true,
 	MaxReplicasAnnotation:          true,
+	RollbackToAnnotation:           true,
 }
 
 // skipCopyAnnotation returns true if we should skip copying the annotation with the given annotation key
@@ -187,6 +212,29 @@ func copyDeploymentAnnotationsToMachineSet(deployment *clusterv1.MachineDeployme
 	return msAnnotationsChanged
 }
 
+// SetDeploymentAnnotationsTo sets deployment's annotations as given machine set's annotations.
+// This action should be done if and only if the deployment is rolling back to this machine set.
+// Note that apply and revision annotations are not changed.
+func SetDeploymentAnnotationsTo(deployment *clusterv1.MachineDeployment, rollbackToMS *clusterv1.MachineSet) {
+	deployment.Annotations = getSkippedAnnotations(deployment.Annotations)
+	for k, v := range rollbackToMS.Annotations {
+		if !skipCopyAnnotation(k) {
+			deployment.Annotations[k] = v
+		}
+	}
+}
+
+// getSkippedAnnotations returns the annotations that are never copied between deployments and machine sets.
+func getSkippedAnnotations(annotations map[string]string) map[string]string {
+	skippedAnnotations := make(map[string]string)
+	for k, v := range annotations {
+		if skipCopyAnnotation(k) {
+			skippedAnnotations[k] = v
+		}
+	}
+	return skippedAnnotations
+}
+
 func getMaxReplicasAnnotation(ms *clusterv1.MachineSet, logger logr.Logger) (int32, bool) {
 	return getIntFromAnnotation(ms, MaxReplicasAnnotation, logger)
 }
@@ -500,6 +548,39 @@ func DeploymentComplete(deployment *clusterv1.MachineDeployment, newStatus *clus
 		newStatus.ObservedGeneration >= deployment.Generation
 }
 
+// HasProgressDeadline checks if the MachineDeployment d is expected to surface the reason
+// "ProgressDeadlineExceeded" when the MachineDeployment progress takes longer than expected time.
+func HasProgressDeadline(d *clusterv1.MachineDeployment) bool {
+	return d.Spec.ProgressDeadlineSeconds != nil && *d.Spec.ProgressDeadlineSeconds != math.MaxInt32
+}
+
+// DeploymentProgressing reports progress for a deployment. Progress is estimated by comparing the
+// old with the new status of the deployment that the controller is observing. More specifically,
+// when new machines are scaled up or become ready or available, or old machines are scaled down, then
+// we consider the deployment is progressing.
+func DeploymentProgressing(oldStatus, newStatus *clusterv1.MachineDeploymentStatus) bool {
+	// Old replicas that need to be scaled down
+	oldStatusOldReplicas := oldStatus.Replicas - oldStatus.UpdatedReplicas
+	newStatusOldReplicas := newStatus.Replicas - newStatus.UpdatedReplicas
+
+	return (newStatus.UpdatedReplicas > oldStatus.UpdatedReplicas) ||
+		(newStatusOldReplicas < oldStatusOldReplicas) ||
+		newStatus.ReadyReplicas > oldStatus.ReadyReplicas ||
+		newStatus.AvailableReplicas > oldStatus.AvailableReplicas
+}
+
+// DeploymentTimedOut considers a deployment to have timed out once its Status.LastProgressTime is older
+// than Spec.ProgressDeadlineSeconds. Deployments without a progress deadline or without a recorded
+// progress time never time out.
+func DeploymentTimedOut(deployment *clusterv1.MachineDeployment, now time.Time) bool {
+	if !HasProgressDeadline(deployment) || deployment.Status.LastProgressTime == nil {
+		return false
+	}
+
+	deadline := deployment.Status.LastProgressTime.Add(time.Duration(*deployment.Spec.ProgressDeadlineSeconds) * time.Second)
+	return deadline.Before(now)
+}
+
 // NewMSNewReplicas calculates the number of replicas a deployment's new MS should have.
 // When one of the following is true, we're rolling out the deployment; otherwise, we're scaling it.
 // 1) The new MS is saturated: newMS's replicas == deployment's replicas
@@ -524,6 +605,16 @@ func NewMSNewReplicas(deployment *clusterv1.MachineDeployment, allMSs []*cluster
 		// Do not exceed the number of desired replicas.
 		scaleUpCount = integer.Int32Min(scaleUpCount, *(deployment.Spec.Replicas)-*(newMS.Spec.Replicas))
 		return *(newMS.Spec.Replicas) + scaleUpCount, nil
+	case clusterv1.OnDeleteMachineDeploymentStrategyType:
+		// Old machines are only removed when deleted by users, scale up to replace the missing machines.
+		currentMachineCount := GetReplicaCountForMachineSets(allMSs)
+		if currentMachineCount >= *(deployment.Spec.Replicas) {
+			// Cannot scale up.
+			return *(newMS.Spec.Replicas), nil
+		}
+		return *(newMS.Spec.Replicas) + *(deployment.Spec.Replicas) - currentMachineCount, nil
+	case clusterv1.RecreateMachineDeploymentStrategyType:
+		return *(deployment.Spec.Replicas), nil
 	default:
 		// Check if we can scale up.
 		maxSurge, err := intstrutil.GetValueFromIntOrPercent(deployment.Spec.Strategy.RollingUpdate.MaxSurge, int(*(deployment.Spec.Replicas)), true)
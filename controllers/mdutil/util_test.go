@@ -18,6 +18,7 @@ package mdutil
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
 	"sort"
@@ -454,6 +455,21 @@ func TestNewMSNewReplicas(t *testing.T) {
 			clusterv1.RollingUpdateMachineDeploymentStrategyType,
 			6, 2, 10, 6,
 		},
+		{
+			"on delete: scale up - to replace deleted machines",
+			clusterv1.OnDeleteMachineDeploymentStrategyType,
+			6, 0, 0, 1,
+		},
+		{
+			"on delete: can not scale up - to newMSReplicas",
+			clusterv1.OnDeleteMachineDeploymentStrategyType,
+			3, 2, 0, 2,
+		},
+		{
+			"recreate: scale up - to depReplicas",
+			clusterv1.RecreateMachineDeploymentStrategyType,
+			4, 0, 0, 4,
+		},
 	}
 	newDeployment := generateDeployment("nginx")
 	newRC := generateMS(newDeployment)
@@ -563,6 +579,134 @@ func TestDeploymentComplete(t *testing.T) {
 	}
 }
 
+func TestDeploymentProgressing(t *testing.T) {
+	status := func(current, updated, ready, available int32) *clusterv1.MachineDeploymentStatus {
+		return &clusterv1.MachineDeploymentStatus{
+			Replicas:          current,
+			UpdatedReplicas:   updated,
+			ReadyReplicas:     ready,
+			AvailableReplicas: available,
+		}
+	}
+
+	tests := []struct {
+		name string
+
+		oldStatus *clusterv1.MachineDeploymentStatus
+		newStatus *clusterv1.MachineDeploymentStatus
+
+		expected bool
+	}{
+		{
+			name: "progressing: updated machines",
+
+			oldStatus: status(2, 1, 2, 2),
+			newStatus: status(2, 2, 2, 2),
+			expected:  true,
+		},
+		{
+			name: "progressing: old machines removed",
+
+			oldStatus: status(3, 1, 3, 3),
+			newStatus: status(2, 1, 2, 2),
+			expected:  true,
+		},
+		{
+			name: "progressing: ready machines",
+
+			oldStatus: status(2, 2, 1, 1),
+			newStatus: status(2, 2, 2, 1),
+			expected:  true,
+		},
+		{
+			name: "progressing: available machines",
+
+			oldStatus: status(2, 2, 2, 1),
+			newStatus: status(2, 2, 2, 2),
+			expected:  true,
+		},
+		{
+			name: "not progressing",
+
+			oldStatus: status(2, 1, 1, 1),
+			newStatus: status(2, 1, 1, 1),
+			expected:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, exp := DeploymentProgressing(test.oldStatus, test.newStatus), test.expected; got != exp {
+				t.Errorf("expected progressing: %t, got: %t", exp, got)
+			}
+		})
+	}
+}
+
+func TestDeploymentTimedOut(t *testing.T) {
+	now := time.Now()
+
+	deployment := func(progressDeadlineSeconds *int32, lastProgressTime *metav1.Time) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			Spec: clusterv1.MachineDeploymentSpec{
+				ProgressDeadlineSeconds: progressDeadlineSeconds,
+			},
+			Status: clusterv1.MachineDeploymentStatus{
+				LastProgressTime: lastProgressTime,
+			},
+		}
+	}
+	deadline := func(i int32) *int32 { return &i }
+	before := func(d time.Duration) *metav1.Time { return &metav1.Time{Time: now.Add(-d)} }
+
+	tests := []struct {
+		name string
+
+		d *clusterv1.MachineDeployment
+
+		expected bool
+	}{
+		{
+			name: "timed out",
+
+			d:        deployment(deadline(600), before(11*time.Minute)),
+			expected: true,
+		},
+		{
+			name: "not timed out: within the deadline",
+
+			d:        deployment(deadline(600), before(9*time.Minute)),
+			expected: false,
+		},
+		{
+			name: "not timed out: no progress deadline",
+
+			d:        deployment(nil, before(11*time.Minute)),
+			expected: false,
+		},
+		{
+			name: "not timed out: progress deadline disabled",
+
+			d:        deployment(deadline(math.MaxInt32), before(11*time.Minute)),
+			expected: false,
+		},
+		{
+			name: "not timed out: no progress recorded",
+
+			d:        deployment(deadline(600), nil),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, exp := DeploymentTimedOut(test.d, now), test.expected; got != exp {
+				t.Errorf("expected timed out: %t, got: %t", exp, got)
+			}
+		})
+	}
+}
+
 func TestMaxUnavailable(t *testing.T) {
 	deployment := func(replicas int32, maxUnavailable intstr.IntOrString) clusterv1.MachineDeployment {
 		return clusterv1.MachineDeployment{
@@ -690,6 +834,70 @@ func TestAnnotationUtils(t *testing.T) {
 	//Tear Down
 }
 
+func TestLastRevision(t *testing.T) {
+	newMS := func(revision string) *clusterv1.MachineSet {
+		ms := generateMS(generateDeployment("nginx"))
+		ms.Annotations = map[string]string{RevisionAnnotation: revision}
+		return &ms
+	}
+
+	tests := []struct {
+		name     string
+		allMSs   []*clusterv1.MachineSet
+		expected int64
+	}{
+		{
+			name:     "no machine sets",
+			expected: 0,
+		},
+		{
+			name:     "a single revision",
+			allMSs:   []*clusterv1.MachineSet{newMS("1")},
+			expected: 0,
+		},
+		{
+			name:     "the second max revision",
+			allMSs:   []*clusterv1.MachineSet{newMS("2"), newMS("5"), newMS("3")},
+			expected: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, exp := LastRevision(test.allMSs, klogr.New()), test.expected; got != exp {
+				t.Errorf("expected last revision: %d, got: %d", exp, got)
+			}
+		})
+	}
+}
+
+func TestSetDeploymentAnnotationsTo(t *testing.T) {
+	deployment := generateDeployment("nginx")
+	deployment.Annotations = map[string]string{
+		RevisionAnnotation:   "3",
+		RollbackToAnnotation: "1",
+		"change-cause":       "upgrade",
+		"owner":              "team-a",
+	}
+	ms := generateMS(deployment)
+	ms.Annotations = map[string]string{
+		RevisionAnnotation:        "1",
+		RevisionHistoryAnnotation: "0",
+		"change-cause":            "initial",
+	}
+
+	SetDeploymentAnnotationsTo(&deployment, &ms)
+
+	expected := map[string]string{
+		RevisionAnnotation:   "3",
+		RollbackToAnnotation: "1",
+		"change-cause":       "initial",
+	}
+	if !reflect.DeepEqual(deployment.Annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, deployment.Annotations)
+	}
+}
+
 func TestReplicasAnnotationsNeedUpdate(t *testing.T) {
 
 	desiredReplicas := fmt.Sprintf("%d", int32(10))
@@ -93,6 +93,26 @@ var (
 		},
 		[]string{"machine", "namespace", "cluster"},
 	)
+
+	// MachineNodeDrainAttempts is a metric that counts the attempts to drain
+	// the node of a machine being deleted, by result.
+	MachineNodeDrainAttempts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capi_machine_node_drain_attempts_total",
+			Help: "Number of attempts to drain the Machine node, partitioned by result (succeeded, failed or timeout).",
+		},
+		[]string{"machine", "namespace", "cluster", "result"},
+	)
+
+	// MachineNodeDrainDuration is a metric that is set to the number of seconds
+	// spent draining the node of a machine, once the drain completes or times out.
+	MachineNodeDrainDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machine_node_drain_duration_seconds",
+			Help: "Time spent draining the Machine node, in seconds.",
+		},
+		[]string{"machine", "namespace", "cluster"},
+	)
 )
 
 func init() {
@@ -104,5 +124,7 @@ func init() {
 		MachineBootstrapReady,
 		MachineInfrastructureReady,
 		MachineNodeReady,
+		MachineNodeDrainAttempts,
+		MachineNodeDrainDuration,
 	)
 }
@@ -0,0 +1,277 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kcfg "sigs.k8s.io/cluster-api/util/kubeconfig"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// ClusterCacheTracker manages a cached client and a set of informers for each workload cluster,
+// so that they can be shared across reconcilers and reconciliations.
+type ClusterCacheTracker struct {
+	log    logr.Logger
+	client client.Client
+	scheme *runtime.Scheme
+
+	lock             sync.RWMutex
+	clusterAccessors map[client.ObjectKey]*clusterAccessor
+}
+
+// NewClusterCacheTracker creates a new ClusterCacheTracker using the client and the scheme of the given manager.
+func NewClusterCacheTracker(log logr.Logger, manager ctrl.Manager) (*ClusterCacheTracker, error) {
+	if manager == nil {
+		return nil, errors.New("manager cannot be nil")
+	}
+	return &ClusterCacheTracker{
+		log:              log,
+		client:           manager.GetClient(),
+		scheme:           manager.GetScheme(),
+		clusterAccessors: make(map[client.ObjectKey]*clusterAccessor),
+	}, nil
+}
+
+// clusterAccessor holds the cache, the client and the watches of a single workload cluster.
+type clusterAccessor struct {
+	cache      *stoppableCache
+	client     client.Client
+	kubeconfig []byte
+	watches    sets.String
+}
+
+// stoppableCache embeds a cache.Cache and adds the ability to stop its informers.
+type stoppableCache struct {
+	cache.Cache
+
+	lock    sync.Mutex
+	stopped bool
+	stop    chan struct{}
+}
+
+// Stop stops the informers of the cache; it is safe to call Stop more than once.
+func (c *stoppableCache) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stopped {
+		return
+	}
+	c.stopped = true
+	close(c.stop)
+}
+
+// GetClient returns a client for the given workload cluster. Reads are served by the informers of the
+// tracker, while writes go straight to the workload cluster API server.
+// If the kubeconfig of the workload cluster changed since the client was created, the cache, the client
+// and the watches of the cluster are discarded and a new client is created.
+func (t *ClusterCacheTracker) GetClient(ctx context.Context, cluster client.ObjectKey) (client.Client, error) {
+	accessor, err := t.getClusterAccessor(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return accessor.client, nil
+}
+
+// ClusterClient returns a client for the given Cluster; its signature matches NewClusterClient,
+// so the tracker can be used wherever reconcilers expect a function creating workload cluster clients.
+func (t *ClusterCacheTracker) ClusterClient(_ client.Client, cluster *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+	return t.GetClient(context.Background(), client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name})
+}
+
+// getClusterAccessor returns the clusterAccessor of the given workload cluster, creating it if it does not
+// exist yet or if it was created using an outdated kubeconfig.
+func (t *ClusterCacheTracker) getClusterAccessor(ctx context.Context, cluster client.ObjectKey) (*clusterAccessor, error) {
+	kubeconfig, err := kcfg.FromSecret(t.client, &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name}})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	t.lock.RLock()
+	accessor, ok := t.clusterAccessors[cluster]
+	t.lock.RUnlock()
+	if ok && bytes.Equal(accessor.kubeconfig, kubeconfig) {
+		return accessor, nil
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Check again while holding the write lock, another goroutine could have created the accessor in the meantime.
+	if accessor, ok := t.clusterAccessors[cluster]; ok {
+		if bytes.Equal(accessor.kubeconfig, kubeconfig) {
+			return accessor, nil
+		}
+		t.log.V(2).Info("Kubeconfig changed, discarding the cached client", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		t.deleteAccessorLocked(cluster)
+	}
+
+	accessor, err = t.newClusterAccessor(cluster, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	t.clusterAccessors[cluster] = accessor
+	return accessor, nil
+}
+
+// newClusterAccessor creates a new clusterAccessor, starting the cache of the workload cluster.
+func (t *ClusterCacheTracker) newClusterAccessor(cluster client.ObjectKey, kubeconfig []byte) (*clusterAccessor, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create REST configuration for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	mapper, err := apiutil.NewDynamicRESTMapper(config, apiutil.WithLazyDiscovery)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create a DynamicRESTMapper for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	c, err := client.New(config, client.Options{Scheme: t.scheme, Mapper: mapper})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	remoteCache, err := cache.New(config, cache.Options{Scheme: t.scheme, Mapper: mapper})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create cache for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	stoppable := &stoppableCache{
+		Cache: remoteCache,
+		stop:  make(chan struct{}),
+	}
+	go func() {
+		if err := stoppable.Start(stoppable.stop); err != nil {
+			t.log.Error(err, "Failed to start the workload cluster cache", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		}
+	}()
+	if !stoppable.WaitForCacheSync(stoppable.stop) {
+		stoppable.Stop()
+		return nil, errors.Errorf("failed waiting for the cache of Cluster %s/%s to sync", cluster.Namespace, cluster.Name)
+	}
+
+	delegatingClient := &client.DelegatingClient{
+		Reader: &client.DelegatingReader{
+			CacheReader:  stoppable,
+			ClientReader: c,
+		},
+		Writer:       c,
+		StatusClient: c,
+	}
+
+	return &clusterAccessor{
+		cache:      stoppable,
+		client:     delegatingClient,
+		kubeconfig: kubeconfig,
+		watches:    sets.NewString(),
+	}, nil
+}
+
+// deleteAccessor stops the cache and removes the clusterAccessor of the given workload cluster, if any.
+func (t *ClusterCacheTracker) deleteAccessor(cluster client.ObjectKey) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.deleteAccessorLocked(cluster)
+}
+
+// deleteAccessorLocked is like deleteAccessor, but it expects the caller to hold the lock.
+func (t *ClusterCacheTracker) deleteAccessorLocked(cluster client.ObjectKey) {
+	accessor, ok := t.clusterAccessors[cluster]
+	if !ok {
+		return
+	}
+
+	t.log.V(2).Info("Deleting the cached client and the informers", "cluster", cluster.Name, "namespace", cluster.Namespace)
+	accessor.cache.Stop()
+	delete(t.clusterAccessors, cluster)
+}
+
+// Watcher is a scoped-down interface from Controller that only knows how to watch.
+type Watcher interface {
+	// Watch watches src for changes, sending events to eventHandler if they pass predicates.
+	Watch(src source.Source, eventHandler handler.EventHandler, predicates ...predicate.Predicate) error
+}
+
+// WatchInput specifies the parameters used to establish a new watch for a workload cluster.
+type WatchInput struct {
+	// Name represents a unique watch request for the specified Cluster.
+	Name string
+
+	// Cluster is the key for the remote cluster.
+	Cluster client.ObjectKey
+
+	// Watcher is the watcher (controller) whose Reconcile() function will be called for events.
+	Watcher Watcher
+
+	// Kind is the type of resource to watch.
+	Kind runtime.Object
+
+	// EventHandler contains the event handlers to invoke for resource events.
+	EventHandler handler.EventHandler
+
+	// Predicates is used to filter resource events.
+	Predicates []predicate.Predicate
+}
+
+// Watch watches a remote cluster for resource events. If the watch already exists based on input.Name,
+// this is a no-op.
+func (t *ClusterCacheTracker) Watch(ctx context.Context, input WatchInput) error {
+	if input.Name == "" {
+		return errors.New("input.Name is required")
+	}
+
+	accessor, err := t.getClusterAccessor(ctx, input.Cluster)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %T watch on cluster %s", input.Kind, input.Cluster)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if accessor.watches.Has(input.Name) {
+		t.log.V(6).Info("Watch already exists", "namespace", input.Cluster.Namespace, "cluster", input.Cluster.Name, "name", input.Name)
+		return nil
+	}
+
+	informer, err := accessor.cache.GetInformer(input.Kind)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the %T informer of cluster %s", input.Kind, input.Cluster)
+	}
+	if err := input.Watcher.Watch(&source.Informer{Informer: informer}, input.EventHandler, input.Predicates...); err != nil {
+		return errors.Wrapf(err, "failed to add %T watch on cluster %s", input.Kind, input.Cluster)
+	}
+
+	accessor.watches.Insert(input.Name)
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func newTestClusterCacheTracker(c client.Client, s *runtime.Scheme) *ClusterCacheTracker {
+	return &ClusterCacheTracker{
+		log:              log.Log,
+		client:           c,
+		scheme:           s,
+		clusterAccessors: make(map[client.ObjectKey]*clusterAccessor),
+	}
+}
+
+func TestClusterCacheTrackerGetClient(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	NewWithT(t).Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+
+	key := client.ObjectKey{Namespace: clusterWithValidKubeConfig.Namespace, Name: clusterWithValidKubeConfig.Name}
+
+	t.Run("returns the same client until the kubeconfig changes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewFakeClientWithScheme(testScheme, validSecret.DeepCopy())
+		tracker := newTestClusterCacheTracker(c, testScheme)
+
+		first, err := tracker.GetClient(context.Background(), key)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(first).NotTo(BeNil())
+
+		second, err := tracker.ClusterClient(nil, clusterWithValidKubeConfig, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(second).To(BeIdenticalTo(first))
+
+		oldCache := tracker.clusterAccessors[key].cache
+
+		// Rotate the kubeconfig.
+		updated := &corev1.Secret{}
+		g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "test", Name: "test1-kubeconfig"}, updated)).To(Succeed())
+		updated.Data[secret.KubeconfigDataName] = []byte(strings.Replace(validKubeConfig, "6443", "443", 1))
+		g.Expect(c.Update(context.Background(), updated)).To(Succeed())
+
+		third, err := tracker.GetClient(context.Background(), key)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(third).NotTo(BeIdenticalTo(first))
+		g.Expect(oldCache.stopped).To(BeTrue())
+		g.Expect(tracker.clusterAccessors).To(HaveLen(1))
+	})
+
+	t.Run("fails when the kubeconfig does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewFakeClientWithScheme(testScheme)
+		tracker := newTestClusterCacheTracker(c, testScheme)
+
+		_, err := tracker.GetClient(context.Background(), client.ObjectKey{Namespace: "test", Name: "test3"})
+		g.Expect(err).To(MatchError(ContainSubstring("not found")))
+		g.Expect(tracker.clusterAccessors).To(BeEmpty())
+	})
+
+	t.Run("fails when the kubeconfig is invalid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewFakeClientWithScheme(testScheme, invalidSecret.DeepCopy())
+		tracker := newTestClusterCacheTracker(c, testScheme)
+
+		_, err := tracker.GetClient(context.Background(), client.ObjectKey{Namespace: "test", Name: "test2"})
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(tracker.clusterAccessors).To(BeEmpty())
+	})
+}
+
+func TestClusterCacheReconciler(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	NewWithT(t).Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+	NewWithT(t).Expect(clusterv1.AddToScheme(testScheme)).To(Succeed())
+
+	key := client.ObjectKey{Namespace: clusterWithValidKubeConfig.Namespace, Name: clusterWithValidKubeConfig.Name}
+
+	deletionTime := metav1.Now()
+	deletingCluster := clusterWithValidKubeConfig.DeepCopy()
+	deletingCluster.DeletionTimestamp = &deletionTime
+
+	tests := []struct {
+		name           string
+		objs           []runtime.Object
+		expectAccessor bool
+	}{
+		{
+			name:           "keeps the cached client of an existing Cluster",
+			objs:           []runtime.Object{clusterWithValidKubeConfig.DeepCopy()},
+			expectAccessor: true,
+		},
+		{
+			name:           "removes the cached client of a Cluster being deleted",
+			objs:           []runtime.Object{deletingCluster},
+			expectAccessor: false,
+		},
+		{
+			name:           "removes the cached client of a Cluster that does not exist",
+			expectAccessor: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewFakeClientWithScheme(testScheme, append(tt.objs, validSecret.DeepCopy())...)
+			tracker := newTestClusterCacheTracker(c, testScheme)
+			_, err := tracker.GetClient(context.Background(), key)
+			g.Expect(err).NotTo(HaveOccurred())
+			accessorCache := tracker.clusterAccessors[key].cache
+
+			r := &ClusterCacheReconciler{
+				Log:     log.Log,
+				Client:  c,
+				Tracker: tracker,
+			}
+			_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			_, ok := tracker.clusterAccessors[key]
+			g.Expect(ok).To(Equal(tt.expectAccessor))
+			g.Expect(accessorCache.stopped).To(Equal(!tt.expectAccessor))
+		})
+	}
+}
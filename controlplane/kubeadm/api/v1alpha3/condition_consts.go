@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+
+// Conditions and condition Reasons for the KubeadmControlPlane object
+
+const (
+	// AvailableCondition documents that the first control plane instance has completed the kubeadm init operation
+	// and so the control plane is available and an API server instance is ready for processing requests.
+	AvailableCondition clusterv1.ConditionType = "Available"
+
+	// WaitingForControlPlaneEndpointReason (Severity=Info) documents a KubeadmControlPlane waiting for the
+	// Cluster to have a control plane endpoint.
+	WaitingForControlPlaneEndpointReason = "WaitingForControlPlaneEndpoint"
+
+	// WaitingForKubeadmInitReason (Severity=Info) documents a KubeadmControlPlane object waiting for the first
+	// control plane instance to complete the kubeadm init operation.
+	WaitingForKubeadmInitReason = "WaitingForKubeadmInit"
+)
+
+const (
+	// CertificatesAvailableCondition documents that cluster certificates were generated as part of the
+	// processing of a KubeadmControlPlane object.
+	CertificatesAvailableCondition clusterv1.ConditionType = "CertificatesAvailable"
+
+	// CertificatesGenerationFailedReason (Severity=Warning) documents a KubeadmControlPlane controller detecting
+	// an error while generating certificates; those kind of errors are usually temporary and the controller
+	// automatically recover from them.
+	CertificatesGenerationFailedReason = "CertificatesGenerationFailed"
+
+	// CertificatesMissingReason (Severity=Warning) documents a KubeadmControlPlane controller detecting that
+	// user supplied certificates, like the ones of an external etcd cluster, are missing.
+	CertificatesMissingReason = "CertificatesMissing"
+)
+
+const (
+	// KubeconfigAvailableCondition documents that the admin kubeconfig of the cluster was generated as part of the
+	// processing of a KubeadmControlPlane object.
+	KubeconfigAvailableCondition clusterv1.ConditionType = "KubeconfigAvailable"
+
+	// KubeconfigGenerationFailedReason (Severity=Warning) documents a KubeadmControlPlane controller detecting
+	// an error while generating the kubeconfig, for example because the cluster CA is not available yet.
+	KubeconfigGenerationFailedReason = "KubeconfigGenerationFailed"
+)
+
+const (
+	// MachinesCreatedCondition documents that the machines controlled by the KubeadmControlPlane are created.
+	// When this condition is false, it indicates that there was an error when cloning the infrastructure/bootstrap
+	// template or when generating the machine object.
+	MachinesCreatedCondition clusterv1.ConditionType = "MachinesCreated"
+
+	// InfrastructureTemplateCloningFailedReason (Severity=Error) documents a KubeadmControlPlane failing to
+	// clone the infrastructure template.
+	InfrastructureTemplateCloningFailedReason = "InfrastructureTemplateCloningFailed"
+
+	// BootstrapTemplateCloningFailedReason (Severity=Error) documents a KubeadmControlPlane failing to
+	// clone the bootstrap template.
+	BootstrapTemplateCloningFailedReason = "BootstrapTemplateCloningFailed"
+
+	// MachineGenerationFailedReason (Severity=Error) documents a KubeadmControlPlane failing to
+	// generate a machine object.
+	MachineGenerationFailedReason = "MachineGenerationFailed"
+)
+
+const (
+	// MachinesReadyCondition documents that all the machines controlled by the KubeadmControlPlane have a
+	// ready Node.
+	MachinesReadyCondition clusterv1.ConditionType = "MachinesReady"
+
+	// MachinesNotReadyReason (Severity=Warning) documents that some of the machines controlled by the
+	// KubeadmControlPlane don't have a ready Node.
+	MachinesNotReadyReason = "MachinesNotReady"
+)
+
+const (
+	// EtcdClusterHealthyCondition documents the overall etcd cluster's health, including the quorum and the
+	// match between the etcd members and the control plane machines.
+	EtcdClusterHealthyCondition clusterv1.ConditionType = "EtcdClusterHealthy"
+
+	// EtcdClusterUnhealthyReason (Severity=Warning) is set when the etcd cluster is unhealthy.
+	EtcdClusterUnhealthyReason = "EtcdClusterUnhealthy"
+)
+
+const (
+	// ControlPlaneComponentsHealthyCondition documents the overall health of the control plane components,
+	// that is the kube-apiserver, kube-controller-manager and kube-scheduler static pods.
+	ControlPlaneComponentsHealthyCondition clusterv1.ConditionType = "ControlPlaneComponentsHealthy"
+
+	// ControlPlaneComponentsUnhealthyReason (Severity=Warning) is set when any of the control plane components
+	// is unhealthy.
+	ControlPlaneComponentsUnhealthyReason = "ControlPlaneComponentsUnhealthy"
+)
+
+const (
+	// MachinesSpecUpToDateCondition documents that the spec of the machines controlled by the KubeadmControlPlane
+	// is up to date. When this condition is false, the KubeadmControlPlane is executing a rolling upgrade.
+	MachinesSpecUpToDateCondition clusterv1.ConditionType = "MachinesSpecUpToDate"
+
+	// RollingUpdateInProgressReason (Severity=Warning) documents a KubeadmControlPlane object executing a
+	// rolling upgrade for aligning the machines spec to the desired state.
+	RollingUpdateInProgressReason = "RollingUpdateInProgress"
+)
+
+const (
+	// ResizedCondition documents a KubeadmControlPlane that is resizing the set of controlled machines.
+	ResizedCondition clusterv1.ConditionType = "Resized"
+
+	// ScalingUpReason (Severity=Info) documents a KubeadmControlPlane that is increasing the number of replicas.
+	ScalingUpReason = "ScalingUp"
+
+	// ScalingDownReason (Severity=Info) documents a KubeadmControlPlane that is decreasing the number of replicas.
+	ScalingDownReason = "ScalingDown"
+)
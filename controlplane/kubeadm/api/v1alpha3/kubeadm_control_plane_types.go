@@ -19,13 +19,23 @@ package v1alpha3
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	cabpkv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/errors"
 )
 
 const (
 	KubeadmControlPlaneFinalizer = "kubeadm.controlplane.cluster.x-k8s.io"
+
+	// KubeadmControlPlaneHashLabelKey is the label set on the Machines and KubeadmConfigs generated by a
+	// KubeadmControlPlane with the hash of the spec they were generated from.
+	KubeadmControlPlaneHashLabelKey = "kubeadm.controlplane.cluster.x-k8s.io/hash"
+
+	// KubeadmControlPlaneLastRemediationAnnotation is the annotation set on a KubeadmControlPlane with the time,
+	// in RFC3339 format, of the last remediation of one of its Machines.
+	KubeadmControlPlaneLastRemediationAnnotation = "kubeadm.controlplane.cluster.x-k8s.io/last-remediation"
 )
 
 // KubeadmControlPlaneSpec defines the desired state of KubeadmControlPlane.
@@ -47,6 +57,72 @@ type KubeadmControlPlaneSpec struct {
 	// KubeadmConfigSpec is a KubeadmConfigSpec
 	// to use for initializing and joining machines to the control plane.
 	KubeadmConfigSpec cabpkv1.KubeadmConfigSpec `json:"kubeadmConfigSpec"`
+
+	// RolloutBefore describes the criteria which trigger the replacement of
+	// the control plane machines before they become unusable.
+	// +optional
+	RolloutBefore *RolloutBefore `json:"rolloutBefore,omitempty"`
+
+	// UpgradeAfter is a field to indicate an upgrade should be performed
+	// after the specified time even if no changes have been made to the
+	// KubeadmControlPlane: all the control plane machines created before
+	// it are replaced.
+	// +optional
+	UpgradeAfter *metav1.Time `json:"upgradeAfter,omitempty"`
+
+	// RolloutStrategy is the strategy used to replace the control plane
+	// machines with new ones.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// Paused indicates that the control plane machines are not scaled,
+	// upgraded or remediated. The status keeps being reported.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RolloutBefore describes when the control plane machines should be replaced.
+type RolloutBefore struct {
+	// CertificatesExpiryDays triggers the replacement of a control plane machine
+	// when its certificates expire within the given number of days.
+	// +kubebuilder:validation:Minimum:=7
+	// +optional
+	CertificatesExpiryDays *int32 `json:"certificatesExpiryDays,omitempty"`
+}
+
+// RolloutStrategyType defines the rollout strategies for a KubeadmControlPlane.
+type RolloutStrategyType string
+
+const (
+	// RollingUpdateStrategyType replaces the old control plane machines by new ones one at a time.
+	RollingUpdateStrategyType RolloutStrategyType = "RollingUpdate"
+)
+
+// RolloutStrategy describes how to replace existing control plane machines
+// with new ones.
+type RolloutStrategy struct {
+	// Type of rollout. Currently the only supported strategy is
+	// "RollingUpdate".
+	// Default is RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate
+	// +optional
+	Type RolloutStrategyType `json:"type,omitempty"`
+
+	// Rolling update config params. Present only if
+	// RolloutStrategyType = RollingUpdate.
+	// +optional
+	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdate is used to control the desired behavior of rolling update.
+type RollingUpdate struct {
+	// The maximum number of control plane machines that can be scheduled above the
+	// desired number of machines during a rollout, either 0 or 1.
+	// With 0, an old machine is deleted before its replacement is created, which
+	// requires at least 3 replicas.
+	// Defaults to 1.
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 }
 
 // KubeadmControlPlaneStatus defines the observed state of KubeadmControlPlane.
@@ -91,6 +167,15 @@ type KubeadmControlPlaneStatus struct {
 	// +optional
 	Ready bool `json:"ready"`
 
+	// Healthy denotes that the etcd members and the control plane components
+	// of all the control plane machines passed the last health check.
+	// +optional
+	Healthy bool `json:"healthy"`
+
+	// HealthCheckMessage describes why the last health check failed, if it did.
+	// +optional
+	HealthCheckMessage *string `json:"healthCheckMessage,omitempty"`
+
 	// FailureReason indicates that there is a terminal problem reconciling the
 	// state, and will be set to a token value suitable for
 	// programmatic interpretation.
@@ -101,6 +186,10 @@ type KubeadmControlPlaneStatus struct {
 	// state, and will be set to a descriptive error message.
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions defines current service state of the KubeadmControlPlane.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -118,6 +207,16 @@ type KubeadmControlPlane struct {
 	Status KubeadmControlPlaneStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (in *KubeadmControlPlane) GetConditions() clusterv1.Conditions {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (in *KubeadmControlPlane) SetConditions(conditions clusterv1.Conditions) {
+	in.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // KubeadmControlPlaneList contains a list of KubeadmControlPlane.
@@ -17,13 +17,19 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"fmt"
 	"reflect"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	cabpkv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 )
 
 func (r *KubeadmControlPlane) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -35,6 +41,10 @@ func (r *KubeadmControlPlane) SetupWebhookWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-controlplane-cluster-x-k8s-io-v1alpha3-kubeadmcontrolplane,mutating=true,failurePolicy=fail,groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,versions=v1alpha3,name=default.kubeadmcontrolplane.controlplane.cluster.x-k8s.io
 // +kubebuilder:webhook:verbs=create;update,path=/validate-controlplane-cluster-x-k8s-io-v1alpha3-kubeadmcontrolplane,mutating=false,failurePolicy=fail,groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,versions=v1alpha3,name=validation.kubeadmcontrolplane.controlplane.cluster.x-k8s.io
 
+// minCertificatesExpiryDays is the minimum number of days before the expiry of the certificates
+// at which the control plane Machines can be replaced.
+const minCertificatesExpiryDays = 7
+
 var _ webhook.Defaulter = &KubeadmControlPlane{}
 var _ webhook.Validator = &KubeadmControlPlane{}
 
@@ -48,6 +58,20 @@ func (r *KubeadmControlPlane) Default() {
 	if r.Spec.InfrastructureTemplate.Namespace == "" {
 		r.Spec.InfrastructureTemplate.Namespace = r.Namespace
 	}
+
+	if r.Spec.RolloutStrategy == nil {
+		r.Spec.RolloutStrategy = &RolloutStrategy{}
+	}
+	if r.Spec.RolloutStrategy.Type == "" {
+		r.Spec.RolloutStrategy.Type = RollingUpdateStrategyType
+	}
+	if r.Spec.RolloutStrategy.RollingUpdate == nil {
+		r.Spec.RolloutStrategy.RollingUpdate = &RollingUpdate{}
+	}
+	if r.Spec.RolloutStrategy.RollingUpdate.MaxSurge == nil {
+		maxSurge := intstr.FromInt(1)
+		r.Spec.RolloutStrategy.RollingUpdate.MaxSurge = &maxSurge
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
@@ -81,6 +105,11 @@ func (r *KubeadmControlPlane) ValidateCreate() error {
 			externalEtcd = true
 		}
 	}
+	if r.Spec.KubeadmConfigSpec.ClusterConfiguration != nil {
+		if r.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External != nil {
+			externalEtcd = true
+		}
+	}
 
 	if !externalEtcd {
 		if r.Spec.Replicas != nil && *r.Spec.Replicas%2 == 0 {
@@ -105,6 +134,10 @@ func (r *KubeadmControlPlane) ValidateCreate() error {
 		)
 	}
 
+	allErrs = append(allErrs, r.validateExternalEtcd()...)
+	allErrs = append(allErrs, r.validateRolloutBefore()...)
+	allErrs = append(allErrs, r.validateRolloutStrategy()...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -117,16 +150,22 @@ func (r *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 	var allErrs field.ErrorList
 
 	oldKubeadmControlPlane := old.(*KubeadmControlPlane)
-	if !reflect.DeepEqual(r.Spec.KubeadmConfigSpec, oldKubeadmControlPlane.Spec.KubeadmConfigSpec) {
+	if !reflect.DeepEqual(withoutMutableFields(&r.Spec.KubeadmConfigSpec), withoutMutableFields(&oldKubeadmControlPlane.Spec.KubeadmConfigSpec)) {
 		allErrs = append(
 			allErrs,
 			field.Forbidden(
 				field.NewPath("spec", "kubeadmConfigSpec"),
-				"cannot be modified",
+				"cannot be modified, except for clusterConfiguration.apiServer, clusterConfiguration.controllerManager, "+
+					"clusterConfiguration.scheduler, clusterConfiguration.imageRepository, the DNS and local etcd images, "+
+					"the nodeRegistration options, files, preKubeadmCommands, postKubeadmCommands, users and ntp",
 			),
 		)
 	}
 
+	allErrs = append(allErrs, r.validateVersionUpgrade(oldKubeadmControlPlane.Spec.Version)...)
+	allErrs = append(allErrs, r.validateRolloutBefore()...)
+	allErrs = append(allErrs, r.validateRolloutStrategy()...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -134,7 +173,138 @@ func (r *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), r.Name, allErrs)
 }
 
+// withoutMutableFields returns a copy of the given KubeadmConfigSpec where all the fields which can be
+// changed, and are rolled out by replacing the control plane Machines, are cleared.
+func withoutMutableFields(spec *cabpkv1.KubeadmConfigSpec) *cabpkv1.KubeadmConfigSpec {
+	immutable := spec.DeepCopy()
+	immutable.Files = nil
+	immutable.PreKubeadmCommands = nil
+	immutable.PostKubeadmCommands = nil
+	immutable.Users = nil
+	immutable.NTP = nil
+
+	if c := immutable.ClusterConfiguration; c != nil {
+		c.APIServer = kubeadmv1beta1.APIServer{}
+		c.ControllerManager = kubeadmv1beta1.ControlPlaneComponent{}
+		c.Scheduler = kubeadmv1beta1.ControlPlaneComponent{}
+		c.ImageRepository = ""
+		c.DNS.ImageMeta = kubeadmv1beta1.ImageMeta{}
+		if c.Etcd.Local != nil {
+			c.Etcd.Local.ImageMeta = kubeadmv1beta1.ImageMeta{}
+		}
+		// Allow to start customizing the cluster configuration of a control plane created without one.
+		if reflect.DeepEqual(*c, kubeadmv1beta1.ClusterConfiguration{}) {
+			immutable.ClusterConfiguration = nil
+		}
+	}
+	if immutable.InitConfiguration != nil {
+		immutable.InitConfiguration.NodeRegistration = kubeadmv1beta1.NodeRegistrationOptions{}
+	}
+	if immutable.JoinConfiguration != nil {
+		immutable.JoinConfiguration.NodeRegistration = kubeadmv1beta1.NodeRegistrationOptions{}
+	}
+	return immutable
+}
+
+// validateVersionUpgrade makes sure that a change of version is supported by kubeadm, which can only
+// upgrade a control plane one minor version at a time and can't downgrade it.
+func (r *KubeadmControlPlane) validateVersionUpgrade(oldVersion string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.Version == oldVersion {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec", "version")
+	newVersion, err := version.ParseSemantic(r.Spec.Version)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, r.Spec.Version, "must be a valid semantic version"))
+	}
+	previousVersion, err := version.ParseSemantic(oldVersion)
+	if err != nil {
+		// The previous version can't be compared, only the new one can be validated.
+		return nil
+	}
+
+	switch {
+	case newVersion.Major() != previousVersion.Major():
+		allErrs = append(allErrs, field.Forbidden(fldPath, "cannot change the major version"))
+	case newVersion.Minor() < previousVersion.Minor():
+		allErrs = append(allErrs, field.Forbidden(fldPath, "cannot downgrade the minor version"))
+	case newVersion.Minor() > previousVersion.Minor()+1:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "cannot skip minor versions, upgrade one minor version at a time"))
+	}
+	return allErrs
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *KubeadmControlPlane) ValidateDelete() error {
 	return nil
 }
+
+// validateExternalEtcd makes sure that the endpoints of an external etcd cluster are set, along with the paths
+// where the etcd client certificates are mounted on the control plane Machines.
+func (r *KubeadmControlPlane) validateExternalEtcd() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.KubeadmConfigSpec.ClusterConfiguration == nil || r.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec", "kubeadmConfigSpec", "clusterConfiguration", "etcd", "external")
+	external := r.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External
+	if len(external.Endpoints) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("endpoints"), "is required when using external etcd"))
+	}
+	if external.CAFile == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("caFile"), "is required when using external etcd"))
+	}
+	if external.CertFile == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("certFile"), "is required when using external etcd"))
+	}
+	if external.KeyFile == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("keyFile"), "is required when using external etcd"))
+	}
+	return allErrs
+}
+
+// validateRolloutBefore makes sure that the certificates expiry threshold leaves enough time to replace
+// the control plane Machines.
+func (r *KubeadmControlPlane) validateRolloutBefore() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.RolloutBefore != nil && r.Spec.RolloutBefore.CertificatesExpiryDays != nil {
+		if *r.Spec.RolloutBefore.CertificatesExpiryDays < minCertificatesExpiryDays {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "rolloutBefore", "certificatesExpiryDays"),
+					*r.Spec.RolloutBefore.CertificatesExpiryDays,
+					fmt.Sprintf("must be greater than or equal to %d", minCertificatesExpiryDays),
+				),
+			)
+		}
+	}
+
+	return allErrs
+}
+
+// validateRolloutStrategy makes sure that at most one control plane Machine is added during a rollout, and that
+// enough Machines remain to keep the etcd quorum when the old Machines are deleted first.
+func (r *KubeadmControlPlane) validateRolloutStrategy() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.RolloutStrategy == nil || r.Spec.RolloutStrategy.RollingUpdate == nil || r.Spec.RolloutStrategy.RollingUpdate.MaxSurge == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec", "rolloutStrategy", "rollingUpdate", "maxSurge")
+	maxSurge := r.Spec.RolloutStrategy.RollingUpdate.MaxSurge
+	switch {
+	case *maxSurge != intstr.FromInt(0) && *maxSurge != intstr.FromInt(1):
+		allErrs = append(allErrs, field.Invalid(fldPath, maxSurge.String(), "must be either 0 or 1"))
+	case *maxSurge == intstr.FromInt(0) && r.Spec.Replicas != nil && *r.Spec.Replicas < 3:
+		allErrs = append(allErrs, field.Forbidden(fldPath, "cannot be 0 with less than 3 replicas, the control plane would be unavailable during a rollout"))
+	}
+	return allErrs
+}
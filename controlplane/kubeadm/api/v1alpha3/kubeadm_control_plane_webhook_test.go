@@ -18,10 +18,12 @@ package v1alpha3
 
 import (
 	"testing"
+	"time"
 
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
@@ -42,6 +44,8 @@ func TestKubeadmControlPlaneDefault(t *testing.T) {
 	kcp.Default()
 
 	g.Expect(kcp.Spec.InfrastructureTemplate.Namespace).To(gomega.Equal(kcp.Namespace))
+	g.Expect(kcp.Spec.RolloutStrategy.Type).To(gomega.Equal(RollingUpdateStrategyType))
+	g.Expect(kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntValue()).To(gomega.Equal(1))
 }
 
 func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
@@ -81,6 +85,48 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 		},
 	}
 
+	evenReplicasExternalEtcdClusterConfiguration := evenReplicas.DeepCopy()
+	evenReplicasExternalEtcdClusterConfiguration.Spec.KubeadmConfigSpec = bootstrapv1.KubeadmConfigSpec{
+		ClusterConfiguration: &kubeadmv1beta1.ClusterConfiguration{
+			Etcd: kubeadmv1beta1.Etcd{
+				External: &kubeadmv1beta1.ExternalEtcd{
+					Endpoints: []string{"https://etcd:2379"},
+					CAFile:    "/etc/kubernetes/pki/etcd/ca.crt",
+					CertFile:  "/etc/kubernetes/pki/apiserver-etcd-client.crt",
+					KeyFile:   "/etc/kubernetes/pki/apiserver-etcd-client.key",
+				},
+			},
+		},
+	}
+
+	externalEtcdWithoutEndpoints := evenReplicasExternalEtcdClusterConfiguration.DeepCopy()
+	externalEtcdWithoutEndpoints.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.Endpoints = nil
+
+	externalEtcdWithoutClientCertificate := evenReplicasExternalEtcdClusterConfiguration.DeepCopy()
+	externalEtcdWithoutClientCertificate.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.CertFile = ""
+	externalEtcdWithoutClientCertificate.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.KeyFile = ""
+
+	validCertificatesExpiryDays := valid.DeepCopy()
+	validCertificatesExpiryDays.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(21)}
+
+	invalidCertificatesExpiryDays := valid.DeepCopy()
+	invalidCertificatesExpiryDays.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(1)}
+
+	maxSurgeZero := intstr.FromInt(0)
+	validMaxSurgeZero := valid.DeepCopy()
+	validMaxSurgeZero.Spec.Replicas = pointer.Int32Ptr(3)
+	validMaxSurgeZero.Spec.RolloutStrategy = &RolloutStrategy{
+		Type:          RollingUpdateStrategyType,
+		RollingUpdate: &RollingUpdate{MaxSurge: &maxSurgeZero},
+	}
+
+	invalidMaxSurgeZeroSingleReplica := validMaxSurgeZero.DeepCopy()
+	invalidMaxSurgeZeroSingleReplica.Spec.Replicas = pointer.Int32Ptr(1)
+
+	maxSurgeTwo := intstr.FromInt(2)
+	invalidMaxSurge := validMaxSurgeZero.DeepCopy()
+	invalidMaxSurge.Spec.RolloutStrategy.RollingUpdate.MaxSurge = &maxSurgeTwo
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -116,6 +162,46 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: false,
 			kcp:       evenReplicasExternalEtcd,
 		},
+		{
+			name:      "should allow even replicas when using external etcd in the cluster configuration",
+			expectErr: false,
+			kcp:       evenReplicasExternalEtcdClusterConfiguration,
+		},
+		{
+			name:      "should return error when the external etcd endpoints are missing",
+			expectErr: true,
+			kcp:       externalEtcdWithoutEndpoints,
+		},
+		{
+			name:      "should return error when the external etcd client certificate is missing",
+			expectErr: true,
+			kcp:       externalEtcdWithoutClientCertificate,
+		},
+		{
+			name:      "should succeed when the certificates expiry threshold is long enough",
+			expectErr: false,
+			kcp:       validCertificatesExpiryDays,
+		},
+		{
+			name:      "should return error when the certificates expiry threshold is too short",
+			expectErr: true,
+			kcp:       invalidCertificatesExpiryDays,
+		},
+		{
+			name:      "should succeed when maxSurge is 0 with 3 replicas",
+			expectErr: false,
+			kcp:       validMaxSurgeZero,
+		},
+		{
+			name:      "should return error when maxSurge is 0 with a single replica",
+			expectErr: true,
+			kcp:       invalidMaxSurgeZeroSingleReplica,
+		},
+		{
+			name:      "should return error when maxSurge is greater than 1",
+			expectErr: true,
+			kcp:       invalidMaxSurge,
+		},
 	}
 
 	for _, tt := range tests {
@@ -145,6 +231,7 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 				Name:      "infraTemplate",
 			},
 			Replicas:          pointer.Int32Ptr(1),
+			Version:           "v1.16.6",
 			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{},
 		},
 	}
@@ -157,6 +244,61 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	validUpdate.Spec.InfrastructureTemplate.Name = "orange"
 	validUpdate.Spec.Replicas = pointer.Int32Ptr(5)
 
+	validPatchUpgrade := before.DeepCopy()
+	validPatchUpgrade.Spec.Version = "v1.16.8"
+
+	validMinorUpgrade := before.DeepCopy()
+	validMinorUpgrade.Spec.Version = "v1.17.0"
+
+	skipMinorUpgrade := before.DeepCopy()
+	skipMinorUpgrade.Spec.Version = "v1.18.0"
+
+	minorDowngrade := before.DeepCopy()
+	minorDowngrade.Spec.Version = "v1.15.9"
+
+	invalidVersion := before.DeepCopy()
+	invalidVersion.Spec.Version = "latest"
+
+	validAPIServerUpdate := before.DeepCopy()
+	validAPIServerUpdate.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1beta1.ClusterConfiguration{
+		APIServer: kubeadmv1beta1.APIServer{
+			ControlPlaneComponent: kubeadmv1beta1.ControlPlaneComponent{
+				ExtraArgs: map[string]string{"audit-log-maxage": "30"},
+			},
+		},
+	}
+
+	validFilesUpdate := before.DeepCopy()
+	validFilesUpdate.Spec.KubeadmConfigSpec.Files = []bootstrapv1.File{{Path: "/etc/foo", Content: "bar"}}
+	validFilesUpdate.Spec.KubeadmConfigSpec.PreKubeadmCommands = []string{"echo foo"}
+
+	validRolloutBeforeUpdate := before.DeepCopy()
+	validRolloutBeforeUpdate.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(30)}
+
+	validRolloutControlsUpdate := before.DeepCopy()
+	validRolloutControlsUpdate.Spec.Paused = true
+	validRolloutControlsUpdate.Spec.UpgradeAfter = &metav1.Time{Time: time.Now()}
+
+	validDNSUpdate := before.DeepCopy()
+	validDNSUpdate.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1beta1.ClusterConfiguration{
+		DNS: kubeadmv1beta1.DNS{
+			ImageMeta: kubeadmv1beta1.ImageMeta{
+				ImageRepository: "registry.example.com",
+				ImageTag:        "1.6.5",
+			},
+		},
+	}
+
+	invalidDNSTypeUpdate := before.DeepCopy()
+	invalidDNSTypeUpdate.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1beta1.ClusterConfiguration{
+		DNS: kubeadmv1beta1.DNS{Type: kubeadmv1beta1.KubeDNS},
+	}
+
+	invalidNetworkingUpdate := before.DeepCopy()
+	invalidNetworkingUpdate.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1beta1.ClusterConfiguration{
+		Networking: kubeadmv1beta1.Networking{PodSubnet: "10.0.0.0/16"},
+	}
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -172,6 +314,66 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidUpdate,
 		},
+		{
+			name:      "should succeed when upgrading to a new patch version",
+			expectErr: false,
+			kcp:       validPatchUpgrade,
+		},
+		{
+			name:      "should succeed when upgrading to the next minor version",
+			expectErr: false,
+			kcp:       validMinorUpgrade,
+		},
+		{
+			name:      "should return error when skipping a minor version",
+			expectErr: true,
+			kcp:       skipMinorUpgrade,
+		},
+		{
+			name:      "should return error when downgrading the minor version",
+			expectErr: true,
+			kcp:       minorDowngrade,
+		},
+		{
+			name:      "should return error when the version is not a valid semantic version",
+			expectErr: true,
+			kcp:       invalidVersion,
+		},
+		{
+			name:      "should succeed when changing the apiServer configuration",
+			expectErr: false,
+			kcp:       validAPIServerUpdate,
+		},
+		{
+			name:      "should succeed when changing files and commands",
+			expectErr: false,
+			kcp:       validFilesUpdate,
+		},
+		{
+			name:      "should succeed when changing the certificates expiry threshold",
+			expectErr: false,
+			kcp:       validRolloutBeforeUpdate,
+		},
+		{
+			name:      "should succeed when pausing the control plane or forcing a rollout",
+			expectErr: false,
+			kcp:       validRolloutControlsUpdate,
+		},
+		{
+			name:      "should succeed when changing the DNS image",
+			expectErr: false,
+			kcp:       validDNSUpdate,
+		},
+		{
+			name:      "should return error when changing the DNS type",
+			expectErr: true,
+			kcp:       invalidDNSTypeUpdate,
+		},
+		{
+			name:      "should return error when changing the networking configuration",
+			expectErr: true,
+			kcp:       invalidNetworkingUpdate,
+		},
 	}
 
 	for _, tt := range tests {
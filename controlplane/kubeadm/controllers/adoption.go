@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+// getAdoptableMachines returns the control plane Machines of the cluster which aren't controlled by anything,
+// for example because they were created before the cluster was managed by a KubeadmControlPlane.
+func (r *KubeadmControlPlaneReconciler) getAdoptableMachines(ctx context.Context, clusterName types.NamespacedName) ([]*clusterv1.Machine, error) {
+	allMachines, err := r.getMachines(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var adoptableMachines []*clusterv1.Machine
+	for i := range allMachines.Items {
+		m := allMachines.Items[i]
+		if metav1.GetControllerOf(&m) == nil && machineIsNotDeleting(&m) {
+			adoptableMachines = append(adoptableMachines, &m)
+		}
+	}
+
+	return adoptableMachines, nil
+}
+
+// adoptMachines takes ownership of the given Machines, along with their KubeadmConfigs and the certificate
+// Secrets generated by those. All the Machines are validated first, so that either all of them are adopted
+// or none is.
+func (r *KubeadmControlPlaneReconciler) adoptMachines(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, machines []*clusterv1.Machine) error {
+	configs := make([]*bootstrapv1.KubeadmConfig, 0, len(machines))
+	for _, m := range machines {
+		config, err := r.validateAdoption(ctx, kcp, m)
+		if err != nil {
+			return errors.Wrapf(err, "cannot adopt Machine %q", m.Name)
+		}
+		configs = append(configs, config)
+	}
+
+	ownerRef := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
+	specHash := hash.Compute(&kcp.Spec)
+	for i, m := range machines {
+		if err := r.adoptOwnedSecrets(ctx, m.Spec.ClusterName, configs[i], ownerRef); err != nil {
+			return errors.Wrapf(err, "failed to adopt the Secrets of KubeadmConfig %q", configs[i].Name)
+		}
+		if err := r.adoptObject(ctx, configs[i], ownerRef, nil); err != nil {
+			return errors.Wrapf(err, "failed to adopt KubeadmConfig %q", configs[i].Name)
+		}
+		// Machines already matching the KubeadmControlPlane are labeled as if it had created them, so that
+		// adopting a control plane doesn't trigger a rollout; the others are replaced by the next upgrade.
+		var labels map[string]string
+		if machineMatchesKubeadmControlPlane(kcp, m, configs[i]) {
+			labels = map[string]string{controlplanev1.KubeadmControlPlaneHashLabelKey: specHash}
+		}
+		if err := r.adoptObject(ctx, m, ownerRef, labels); err != nil {
+			return errors.Wrapf(err, "failed to adopt Machine %q", m.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateAdoption checks the given Machine can be managed by the KubeadmControlPlane and returns its KubeadmConfig.
+func (r *KubeadmControlPlaneReconciler) validateAdoption(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, machine *clusterv1.Machine) (*bootstrapv1.KubeadmConfig, error) {
+	if machine.Spec.Version == nil {
+		return nil, errors.New("the Machine has no version")
+	}
+	machineVersion, err := version.ParseSemantic(*machine.Spec.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the version %q of the Machine", *machine.Spec.Version)
+	}
+	kcpVersion, err := version.ParseSemantic(kcp.Spec.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the version %q of the KubeadmControlPlane", kcp.Spec.Version)
+	}
+	// The Machine must be replaceable through a regular upgrade, so it can be at most one minor version behind.
+	if kcpVersion.LessThan(machineVersion) {
+		return nil, errors.Errorf("the Machine version %s is newer than the KubeadmControlPlane version %s", machineVersion, kcpVersion)
+	}
+	if machineVersion.Major() != kcpVersion.Major() || machineVersion.Minor()+1 < kcpVersion.Minor() {
+		return nil, errors.Errorf("the Machine version %s is more than one minor version behind the KubeadmControlPlane version %s", machineVersion, kcpVersion)
+	}
+
+	ref := machine.Spec.Bootstrap.ConfigRef
+	if ref == nil {
+		return nil, errors.New("the Machine has no bootstrap configuration reference")
+	}
+	if ref.Kind != "KubeadmConfig" || ref.GroupVersionKind().Group != bootstrapv1.GroupVersion.Group {
+		return nil, errors.Errorf("the Machine bootstrap configuration is a %s, not a KubeadmConfig", ref.GroupVersionKind())
+	}
+
+	config := &bootstrapv1.KubeadmConfig{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: ref.Name}, config); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve KubeadmConfig %q", ref.Name)
+	}
+	if controllerRef := metav1.GetControllerOf(config); controllerRef != nil && !isControlledByKubeadmControlPlane(config, kcp) {
+		return nil, errors.Errorf("KubeadmConfig %q is already controlled by %s %q", config.Name, controllerRef.Kind, controllerRef.Name)
+	}
+	isControlPlaneConfig := config.Spec.InitConfiguration != nil || config.Spec.ClusterConfiguration != nil ||
+		(config.Spec.JoinConfiguration != nil && config.Spec.JoinConfiguration.ControlPlane != nil)
+	if !isControlPlaneConfig {
+		return nil, errors.Errorf("KubeadmConfig %q doesn't configure a control plane node", config.Name)
+	}
+
+	return config, nil
+}
+
+// machineMatchesKubeadmControlPlane returns true if the given Machine runs the version of the KubeadmControlPlane and
+// was bootstrapped with the same configuration. The init and join configurations are specific to each node, and
+// the cluster configuration is only set on some of them, so they are not compared.
+func machineMatchesKubeadmControlPlane(kcp *controlplanev1.KubeadmControlPlane, machine *clusterv1.Machine, config *bootstrapv1.KubeadmConfig) bool {
+	if machine.Spec.Version == nil || *machine.Spec.Version != kcp.Spec.Version {
+		return false
+	}
+
+	machineSpec := config.Spec.DeepCopy()
+	kcpSpec := kcp.Spec.KubeadmConfigSpec.DeepCopy()
+	for _, spec := range []*bootstrapv1.KubeadmConfigSpec{machineSpec, kcpSpec} {
+		spec.InitConfiguration = nil
+		spec.JoinConfiguration = nil
+	}
+	if machineSpec.ClusterConfiguration == nil || kcpSpec.ClusterConfiguration == nil ||
+		reflect.DeepEqual(*machineSpec.ClusterConfiguration, kubeadmv1.ClusterConfiguration{}) {
+		machineSpec.ClusterConfiguration = nil
+		kcpSpec.ClusterConfiguration = nil
+	}
+	return reflect.DeepEqual(machineSpec, kcpSpec)
+}
+
+// adoptOwnedSecrets moves the cluster Secrets controlled by the given KubeadmConfig, that is the certificates generated
+// when initializing the control plane, under the given owner so they outlive the Machine.
+func (r *KubeadmControlPlaneReconciler) adoptOwnedSecrets(ctx context.Context, clusterName string, config *bootstrapv1.KubeadmConfig, ownerRef metav1.OwnerReference) error {
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secrets, client.InNamespace(config.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return errors.Wrap(err, "failed to list cluster Secrets")
+	}
+
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		controllerRef := metav1.GetControllerOf(s)
+		if controllerRef == nil || controllerRef.Kind != "KubeadmConfig" || controllerRef.Name != config.Name {
+			continue
+		}
+		if err := r.adoptObject(ctx, s, ownerRef, nil); err != nil {
+			return errors.Wrapf(err, "failed to adopt Secret %q", s.Name)
+		}
+	}
+
+	return nil
+}
+
+// adoptObject replaces the controller reference of the given object, if any, with the given owner reference,
+// and adds the given labels to the object.
+func (r *KubeadmControlPlaneReconciler) adoptObject(ctx context.Context, obj adoptableObject, ownerRef metav1.OwnerReference, labels map[string]string) error {
+	patchHelper, err := patch.NewHelper(obj, r.Client)
+	if err != nil {
+		return err
+	}
+
+	if len(labels) > 0 {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = map[string]string{}
+		}
+		for k, v := range labels {
+			objLabels[k] = v
+		}
+		obj.SetLabels(objLabels)
+	}
+
+	ownerRefs := []metav1.OwnerReference{ownerRef}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Controller == nil || !*ref.Controller {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	obj.SetOwnerReferences(ownerRefs)
+
+	return patchHelper.Patch(ctx, obj)
+}
+
+// adoptableObject is an object whose ownership can be taken by a KubeadmControlPlane.
+type adoptableObject interface {
+	metav1.Object
+	runtime.Object
+}
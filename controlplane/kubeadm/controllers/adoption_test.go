@@ -0,0 +1,256 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// createUnownedMachine returns a control plane Machine of the given Cluster which is not owned by a
+// KubeadmControlPlane, along with its KubeadmConfig and the cluster CA Secret generated by that config.
+func createUnownedMachine(cluster *clusterv1.Cluster, machineVersion string) (*clusterv1.Machine, *bootstrapv1.KubeadmConfig, *corev1.Secret) {
+	config := &bootstrapv1.KubeadmConfig{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KubeadmConfig",
+			APIVersion: bootstrapv1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "control-plane-0",
+			Namespace: cluster.Namespace,
+		},
+		Spec: bootstrapv1.KubeadmConfigSpec{
+			ClusterConfiguration: &kubeadmv1.ClusterConfiguration{},
+			InitConfiguration:    &kubeadmv1.InitConfiguration{},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "control-plane-0",
+			Namespace: cluster.Namespace,
+			Labels:    generateKubeadmControlPlaneLabels(cluster.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String(), Name: cluster.Name},
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			Version:     pointer.StringPtr(machineVersion),
+			Bootstrap: clusterv1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					Kind:       "KubeadmConfig",
+					APIVersion: bootstrapv1.GroupVersion.String(),
+					Name:       config.Name,
+					Namespace:  config.Namespace,
+				},
+			},
+		},
+	}
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secret.Name(cluster.Name, secret.ClusterCA),
+			Namespace:       cluster.Namespace,
+			Labels:          map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(config, bootstrapv1.GroupVersion.WithKind("KubeadmConfig"))},
+		},
+	}
+	return machine, config, caSecret
+}
+
+func TestKubeadmControlPlaneReconciler_adoptMachines(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0")
+	kcp := f.kcp
+	machine, config, caSecret := createUnownedMachine(f.cluster, "v1.16.3")
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), machine, config, caSecret)...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	clusterName := types.NamespacedName{Namespace: kcp.Namespace, Name: "foo"}
+	adoptableMachines, err := r.getAdoptableMachines(context.Background(), clusterName)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(adoptableMachines).To(gomega.HaveLen(1))
+	g.Expect(adoptableMachines[0].Name).To(gomega.Equal(machine.Name))
+
+	g.Expect(r.adoptMachines(context.Background(), kcp, adoptableMachines)).To(gomega.Succeed())
+
+	ownedMachines, err := r.getOwnedMachines(context.Background(), kcp, clusterName)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ownedMachines).To(gomega.HaveLen(2))
+
+	adoptedMachine := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: machine.Name}, adoptedMachine)).To(gomega.Succeed())
+	g.Expect(isControlledByKubeadmControlPlane(adoptedMachine, kcp)).To(gomega.BeTrue())
+	// Other owners are preserved.
+	g.Expect(adoptedMachine.OwnerReferences).To(gomega.HaveLen(2))
+	// The outdated Machine is replaced by the next upgrade.
+	g.Expect(adoptedMachine.Labels).NotTo(gomega.HaveKey(controlplanev1.KubeadmControlPlaneHashLabelKey))
+
+	adoptedConfig := &bootstrapv1.KubeadmConfig{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: config.Name}, adoptedConfig)).To(gomega.Succeed())
+	g.Expect(isControlledByKubeadmControlPlane(adoptedConfig, kcp)).To(gomega.BeTrue())
+
+	adoptedSecret := &corev1.Secret{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: caSecret.Name}, adoptedSecret)).To(gomega.Succeed())
+	g.Expect(adoptedSecret.OwnerReferences).To(gomega.HaveLen(1))
+	g.Expect(isControlledByKubeadmControlPlane(adoptedSecret, kcp)).To(gomega.BeTrue())
+
+	adoptableMachines, err = r.getAdoptableMachines(context.Background(), clusterName)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(adoptableMachines).To(gomega.BeEmpty())
+}
+
+func TestKubeadmControlPlaneReconciler_adoptMachinesUpToDate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures()
+	kcp := f.kcp
+	machine, config, caSecret := createUnownedMachine(f.cluster, kcp.Spec.Version)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), machine, config, caSecret)...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	g.Expect(r.adoptMachines(context.Background(), kcp, []*clusterv1.Machine{machine})).To(gomega.Succeed())
+
+	adoptedMachine := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: machine.Name}, adoptedMachine)).To(gomega.Succeed())
+	g.Expect(adoptedMachine.Labels).To(gomega.HaveKeyWithValue(controlplanev1.KubeadmControlPlaneHashLabelKey, hash.Compute(&kcp.Spec)))
+
+	// Adopting a Machine matching the KubeadmControlPlane doesn't trigger a rollout.
+	ownedMachines, err := r.getOwnedMachines(context.Background(), kcp, types.NamespacedName{Namespace: kcp.Namespace, Name: "foo"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ownedMachines).To(gomega.HaveLen(1))
+	g.Expect(filterMachines(ownedMachines, machineNeedsRollout(kcp))).To(gomega.BeEmpty())
+
+	// A different bootstrap configuration does.
+	changedKCP := kcp.DeepCopy()
+	changedKCP.Spec.KubeadmConfigSpec.PreKubeadmCommands = []string{"echo changed"}
+	g.Expect(machineMatchesKubeadmControlPlane(changedKCP, adoptedMachine, config)).To(gomega.BeFalse())
+}
+
+func TestKubeadmControlPlaneReconciler_adoptMachinesValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(machine *clusterv1.Machine, config *bootstrapv1.KubeadmConfig)
+	}{
+		{
+			name: "Machine without version",
+			mutate: func(machine *clusterv1.Machine, _ *bootstrapv1.KubeadmConfig) {
+				machine.Spec.Version = nil
+			},
+		},
+		{
+			name: "Machine newer than the KubeadmControlPlane",
+			mutate: func(machine *clusterv1.Machine, _ *bootstrapv1.KubeadmConfig) {
+				machine.Spec.Version = pointer.StringPtr("v1.18.0")
+			},
+		},
+		{
+			name: "Machine more than one minor version behind",
+			mutate: func(machine *clusterv1.Machine, _ *bootstrapv1.KubeadmConfig) {
+				machine.Spec.Version = pointer.StringPtr("v1.15.3")
+			},
+		},
+		{
+			name: "Machine not bootstrapped by kubeadm",
+			mutate: func(machine *clusterv1.Machine, _ *bootstrapv1.KubeadmConfig) {
+				machine.Spec.Bootstrap.ConfigRef.Kind = "OtherConfig"
+			},
+		},
+		{
+			name: "KubeadmConfig for a worker node",
+			mutate: func(_ *clusterv1.Machine, config *bootstrapv1.KubeadmConfig) {
+				config.Spec.ClusterConfiguration = nil
+				config.Spec.InitConfiguration = nil
+				config.Spec.JoinConfiguration = &kubeadmv1.JoinConfiguration{}
+			},
+		},
+		{
+			name: "KubeadmConfig controlled by something else",
+			mutate: func(_ *clusterv1.Machine, config *bootstrapv1.KubeadmConfig) {
+				config.OwnerReferences = []metav1.OwnerReference{{Kind: "Other", Name: "other", Controller: pointer.BoolPtr(true)}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			f := createControlPlaneFixtures()
+			kcp := f.kcp
+			machine, config, caSecret := createUnownedMachine(f.cluster, "v1.17.0")
+			tt.mutate(machine, config)
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), machine, config, caSecret)...)
+
+			r := &KubeadmControlPlaneReconciler{
+				Client:   fakeClient,
+				Log:      log.Log,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			g.Expect(r.adoptMachines(context.Background(), kcp, []*clusterv1.Machine{machine})).NotTo(gomega.Succeed())
+
+			// Nothing is adopted.
+			notAdopted := &clusterv1.Machine{}
+			g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: machine.Name}, notAdopted)).To(gomega.Succeed())
+			g.Expect(metav1.GetControllerOf(notAdopted)).To(gomega.BeNil())
+			notAdoptedSecret := &corev1.Secret{}
+			g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: caSecret.Name}, notAdoptedSecret)).To(gomega.Succeed())
+			g.Expect(isControlledByKubeadmControlPlane(notAdoptedSecret, kcp)).To(gomega.BeFalse())
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+// reconcileCertificateExpiries records the expiry date of the certificates of the control plane Machines in their
+// status. The certificates of a Machine are generated once, when its Node joins the cluster, so the date is only
+// looked up for the Machines which don't have one yet.
+func (r *KubeadmControlPlaneReconciler) reconcileCertificateExpiries(ctx context.Context, cluster *clusterv1.Cluster, machines []*clusterv1.Machine) error {
+	var workloadCluster internal.WorkloadCluster
+	for _, m := range filterMachines(machines, machineIsNotDeleting, machineHasNode) {
+		if m.Status.CertificatesExpiryDate != nil {
+			continue
+		}
+
+		if workloadCluster == nil {
+			var err error
+			workloadCluster, err = r.managementCluster.GetWorkloadCluster(ctx, cluster)
+			if err != nil {
+				return errors.Wrap(err, "failed to create client to workload cluster")
+			}
+		}
+
+		expiry, err := workloadCluster.GetAPIServerCertificateExpiry(ctx, m.Status.NodeRef.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the certificates expiry date of Machine %q", m.Name)
+		}
+
+		patchHelper, err := patch.NewHelper(m, r.Client)
+		if err != nil {
+			return err
+		}
+		m.Status.CertificatesExpiryDate = &metav1.Time{Time: *expiry}
+		if err := patchHelper.Patch(ctx, m); err != nil {
+			return errors.Wrapf(err, "failed to record the certificates expiry date of Machine %q", m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+)
+
+func TestKubeadmControlPlaneReconciler_reconcileCertificateExpiries(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kcp-foo",
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	// The expiry date of the first Machine is already known, the second one has no Node yet.
+	knownExpiry := metav1.NewTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	recorded, _ := createMachineNodePair("recorded", cluster, kcp, true)
+	recorded.Status.CertificatesExpiryDate = &knownExpiry
+	withoutNode, _ := createMachineNodePair("without-node", cluster, kcp, false)
+	withoutNode.Status.NodeRef = nil
+	missing, _ := createMachineNodePair("missing", cluster, kcp, true)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, recorded.DeepCopy(), withoutNode.DeepCopy(), missing.DeepCopy())
+
+	expiry := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	workload := &fakeWorkloadCluster{
+		CertificatesExpiryDates: map[string]time.Time{
+			"recorded": time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			"missing":  expiry,
+		},
+	}
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		Log:               log.Log,
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	machines := []*clusterv1.Machine{recorded, withoutNode, missing}
+	g.Expect(r.reconcileCertificateExpiries(context.Background(), cluster, machines)).To(gomega.Succeed())
+
+	updated := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: cluster.Namespace, Name: "missing"}, updated)).To(gomega.Succeed())
+	g.Expect(updated.Status.CertificatesExpiryDate).NotTo(gomega.BeNil())
+	g.Expect(updated.Status.CertificatesExpiryDate.Time).To(gomega.BeTemporally("==", expiry))
+
+	unchanged := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: cluster.Namespace, Name: "recorded"}, unchanged)).To(gomega.Succeed())
+	g.Expect(unchanged.Status.CertificatesExpiryDate.Time).To(gomega.BeTemporally("==", knownExpiry.Time))
+
+	notUpdated := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: cluster.Namespace, Name: "without-node"}, notUpdated)).To(gomega.Succeed())
+	g.Expect(notUpdated.Status.CertificatesExpiryDate).To(gomega.BeNil())
+}
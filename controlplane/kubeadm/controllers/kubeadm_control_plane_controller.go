@@ -48,13 +48,29 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
 
+const (
+	// deleteRequeueAfter is how long to wait before checking again to see if
+	// a control plane Machine selected for deletion has gone away.
+	deleteRequeueAfter = 30 * time.Second
+
+	// healthCheckRequeueAfter is how long to wait before checking again whether the control plane
+	// became healthy enough to add or remove a Machine.
+	healthCheckRequeueAfter = 20 * time.Second
+
+	// addonsRequeueAfter is how long to wait before trying again to update the add-ons in the workload cluster.
+	addonsRequeueAfter = 30 * time.Second
+)
+
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io;controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
@@ -67,13 +83,23 @@ type KubeadmControlPlaneReconciler struct {
 	Log    logr.Logger
 	scheme *runtime.Scheme
 
+	// Tracker, if set, provides cached clients for the workload clusters.
+	Tracker *remote.ClusterCacheTracker
+
 	// for testing
-	remoteClient func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
+	remoteClient      func(client.Client, *clusterv1.Cluster, *runtime.Scheme) (client.Client, error)
+	managementCluster managementCluster
 
 	controller controller.Controller
 	recorder   record.EventRecorder
 }
 
+// managementCluster defines the operations the controller needs to perform
+// against the management cluster in order to reach a workload cluster.
+type managementCluster interface {
+	GetWorkloadCluster(ctx context.Context, cluster *clusterv1.Cluster) (internal.WorkloadCluster, error)
+}
+
 func (r *KubeadmControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&controlplanev1.KubeadmControlPlane{}).
@@ -95,6 +121,12 @@ func (r *KubeadmControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager, optio
 
 	if r.remoteClient == nil {
 		r.remoteClient = remote.NewClusterClient
+		if r.Tracker != nil {
+			r.remoteClient = r.Tracker.ClusterClient
+		}
+	}
+	if r.managementCluster == nil {
+		r.managementCluster = &internal.ManagementCluster{Client: r.Client, Scheme: r.scheme, Tracker: r.Tracker}
 	}
 
 	return nil
@@ -164,7 +196,22 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, kcp *cont
 		return ctrl.Result{}, err
 	}
 
-	// TODO: handle proper adoption of Machines
+	// Adopt the control plane Machines of the cluster which aren't controlled by anything yet.
+	adoptableMachines, err := r.getAdoptableMachines(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+	if err != nil {
+		logger.Error(err, "failed to get list of adoptable machines")
+		return ctrl.Result{}, err
+	}
+	if len(adoptableMachines) > 0 {
+		logger.Info("Adopting control plane Machines", "Machines", len(adoptableMachines))
+		if err := r.adoptMachines(ctx, kcp, adoptableMachines); err != nil {
+			logger.Error(err, "Failed to adopt control plane Machines")
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedAdoption", "Failed to adopt control plane Machines: %v", err)
+			return ctrl.Result{}, err
+		}
+		r.recorder.Eventf(kcp, corev1.EventTypeNormal, "SuccessfulAdoption", "Adopted %d control plane Machines", len(adoptableMachines))
+	}
+
 	ownedMachines, err := r.getOwnedMachines(
 		ctx,
 		kcp,
@@ -198,8 +245,16 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, kcp *cont
 	)
 	if err != nil {
 		logger.Error(err, "unable to lookup or create cluster certificates")
+		conditions.MarkFalse(kcp, controlplanev1.CertificatesAvailableCondition, controlplanev1.CertificatesGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+	// The certificates of an external etcd cluster are never generated, they must be supplied as secrets.
+	if err := certificates.EnsureAllExist(); err != nil {
+		logger.Error(err, "missing cluster certificates")
+		conditions.MarkFalse(kcp, controlplanev1.CertificatesAvailableCondition, controlplanev1.CertificatesMissingReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
+	conditions.MarkTrue(kcp, controlplanev1.CertificatesAvailableCondition)
 
 	// If ControlPlaneEndpoint is not set, return early
 	if cluster.Spec.ControlPlaneEndpoint.IsZero() {
@@ -215,6 +270,7 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, kcp *cont
 		kcp,
 	)
 	if err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.KubeconfigAvailableCondition, controlplanev1.KubeconfigGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		if requeueErr, ok := errors.Cause(err).(capierrors.HasRequeueAfterError); ok {
 			logger.Error(err, "required certificates not found, requeueing")
 			return ctrl.Result{
@@ -225,16 +281,84 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, kcp *cont
 		logger.Error(err, "failed to reconcile Kubeconfig")
 		return ctrl.Result{}, err
 	}
+	conditions.MarkTrue(kcp, controlplanev1.KubeconfigAvailableCondition)
+
+	// A paused control plane keeps its Machines as they are, only its status is updated.
+	if kcp.Spec.Paused {
+		logger.Info("Reconciliation is paused, the control plane Machines are not scaled, upgraded or remediated")
+		return ctrl.Result{}, nil
+	}
+
+	if len(ownedMachines) > 0 {
+		// Replace the failed Machines first, since the control plane can't become healthy while they exist.
+		unhealthyMachines, err := r.getUnhealthyMachines(ctx, cluster, ownedMachines)
+		if err != nil {
+			logger.Error(err, "Failed to check the health of the control plane Machines")
+			return ctrl.Result{}, err
+		}
+		if len(unhealthyMachines) > 0 {
+			logger.Info("Remediating unhealthy control plane Machines", "Unhealthy Machines", len(unhealthyMachines))
+			result, err := r.remediateControlPlane(ctx, cluster, kcp, ownedMachines, unhealthyMachines)
+			if err != nil {
+				logger.Error(err, "Failed to remediate the Control Plane")
+				r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedRemediation", "Failed to remediate the control plane: %v", err)
+				return ctrl.Result{}, err
+			}
+			return result, nil
+		}
+
+		// Machines are only added or removed when the control plane is fully healthy, this includes waiting
+		// for the Node and the etcd member of a Machine created by a previous reconciliation.
+		if err := r.reconcileHealth(ctx, cluster, kcp, ownedMachines); err != nil {
+			logger.Info("Waiting for the control plane to be healthy", "cause", err.Error())
+			return ctrl.Result{RequeueAfter: healthCheckRequeueAfter}, nil
+		}
+
+		if err := r.reconcileCertificateExpiries(ctx, cluster, ownedMachines); err != nil {
+			logger.Error(err, "Failed to reconcile the certificates expiry dates")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Upgrade takes precedence over scaling: replace the outdated Machines, and the ones whose certificates
+	// are about to expire, one at a time first.
+	requireUpgrade := filterMachines(ownedMachines, machineNeedsRollout(kcp))
+	if len(requireUpgrade) > 0 {
+		logger.Info("Upgrading Control Plane", "Version", kcp.Spec.Version, "Outdated Replicas", len(requireUpgrade))
+		conditions.MarkFalse(kcp, controlplanev1.MachinesSpecUpToDateCondition, controlplanev1.RollingUpdateInProgressReason, clusterv1.ConditionSeverityWarning,
+			"Rolling %d replicas with outdated spec (%d replicas up to date)", len(requireUpgrade), len(ownedMachines)-len(requireUpgrade))
+		result, err := r.upgradeControlPlane(ctx, cluster, kcp, ownedMachines, requireUpgrade)
+		if err != nil {
+			logger.Error(err, "Failed to upgrade the Control Plane")
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedUpgrade", "Failed to upgrade the control plane: %v", err)
+			return ctrl.Result{}, err
+		}
+		return result, nil
+	}
+
+	conditions.MarkTrue(kcp, controlplanev1.MachinesSpecUpToDateCondition)
 
-	// Currently we are not handling upgrade, so treat all owned machines as one for now.
-	// Once we start handling upgrade, we'll need to filter this list and act appropriately
 	numMachines := len(ownedMachines)
 	desiredReplicas := int(*kcp.Spec.Replicas)
+
+	// The add-ons follow once all the control plane Machines run the desired version. A failure to update them
+	// must not block scaling, so it is only reported and retried later.
+	var addonsResult ctrl.Result
+	if numMachines > 0 && len(filterMachines(ownedMachines, machineHasVersion(kcp.Spec.Version))) == numMachines {
+		if err := r.reconcileAddons(ctx, cluster, kcp); err != nil {
+			logger.Error(err, "Failed to reconcile the add-ons")
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedAddonsUpgrade", "Failed to upgrade the add-ons: %v", err)
+			addonsResult = ctrl.Result{RequeueAfter: addonsRequeueAfter}
+		}
+	}
+
 	switch {
 	// We are creating the first replica
 	case numMachines < desiredReplicas && numMachines == 0:
 		// create new Machine w/ init
 		logger.Info("Scaling to 1", "Desired Replicas", desiredReplicas, "Existing Replicas", numMachines)
+		conditions.MarkFalse(kcp, controlplanev1.ResizedCondition, controlplanev1.ScalingUpReason, clusterv1.ConditionSeverityInfo,
+			"Scaling up control plane to %d replicas (actual %d)", desiredReplicas, numMachines)
 		if err := r.initializeControlPlane(ctx, cluster, kcp); err != nil {
 			logger.Error(err, "Failed to initialize the Control Plane")
 			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedInitialization", "Failed to initialize the control plane: %v", err)
@@ -242,23 +366,59 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, kcp *cont
 		}
 	// scaling up
 	case numMachines < desiredReplicas && numMachines > 0:
-		// create a new Machine w/ join
+		// Create a single new Machine w/ join: the next one is only added once the control plane, including the
+		// etcd member of this Machine, is healthy again.
 		logger.Info("Scaling up", "Desired Replicas", desiredReplicas, "Existing Replicas", numMachines)
-		wantMachines := desiredReplicas - numMachines
-		if err := r.scaleUpControlPlane(ctx, cluster, kcp, wantMachines); err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.ResizedCondition, controlplanev1.ScalingUpReason, clusterv1.ConditionSeverityInfo,
+			"Scaling up control plane to %d replicas (actual %d)", desiredReplicas, numMachines)
+		if err := r.scaleUpControlPlane(ctx, cluster, kcp, ownedMachines, 1); err != nil {
 			logger.Error(err, "Failed to scale up the Control Plane")
 			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedScaleUp", "Failed to scale up the control plane: %v", err)
 			return ctrl.Result{}, err
 		}
+		return lowestNonZeroResult(ctrl.Result{Requeue: true}, addonsResult), nil
 	// scaling down
 	case numMachines > desiredReplicas:
 		logger.Info("Scaling down", "Desired Replicas", desiredReplicas, "Existing Replicas", numMachines)
-		err := errors.New("Not Implemented")
-		logger.Error(err, "Should delete the appropriate Machine here.")
-		return ctrl.Result{}, err
+		conditions.MarkFalse(kcp, controlplanev1.ResizedCondition, controlplanev1.ScalingDownReason, clusterv1.ConditionSeverityInfo,
+			"Scaling down control plane to %d replicas (actual %d)", desiredReplicas, numMachines)
+		result, err := r.scaleDownControlPlane(ctx, cluster, kcp, ownedMachines, ownedMachines)
+		if err != nil {
+			logger.Error(err, "Failed to scale down the Control Plane")
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedScaleDown", "Failed to scale down the control plane: %v", err)
+			return ctrl.Result{}, err
+		}
+		return lowestNonZeroResult(result, addonsResult), nil
+	default:
+		conditions.MarkTrue(kcp, controlplanev1.ResizedCondition)
 	}
 
-	return ctrl.Result{}, nil
+	// Come back when the UpgradeAfter time is reached, to replace the Machines created before it.
+	if kcp.Spec.UpgradeAfter != nil {
+		if wait := time.Until(kcp.Spec.UpgradeAfter.Time); wait > 0 {
+			return lowestNonZeroResult(ctrl.Result{RequeueAfter: wait}, addonsResult), nil
+		}
+	}
+
+	return addonsResult, nil
+}
+
+// lowestNonZeroResult returns the result which requeues the soonest, ignoring the results that don't requeue.
+func lowestNonZeroResult(i, j ctrl.Result) ctrl.Result {
+	switch {
+	case i == ctrl.Result{}:
+		return j
+	case j == ctrl.Result{}:
+		return i
+	case i.Requeue && i.RequeueAfter == 0:
+		return i
+	case j.Requeue && j.RequeueAfter == 0:
+		return j
+	case i.RequeueAfter < j.RequeueAfter:
+		return i
+	default:
+		return j
+	}
 }
 
 func (r *KubeadmControlPlaneReconciler) updateStatus(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, cluster *clusterv1.Cluster) error {
@@ -282,8 +442,8 @@ func (r *KubeadmControlPlaneReconciler) updateStatus(ctx context.Context, kcp *c
 	}
 
 	replicas := int32(len(ownedMachines))
-	// TODO: take into account configuration hash once upgrades are in place
 	kcp.Status.Replicas = replicas
+	kcp.Status.UpdatedReplicas = replicas - int32(len(filterMachines(ownedMachines, machineNeedsRollout(kcp))))
 
 	remoteClient, err := r.remoteClient(r.Client, cluster, r.scheme)
 	if err != nil && !apierrors.IsNotFound(errors.Cause(err)) {
@@ -307,39 +467,193 @@ func (r *KubeadmControlPlaneReconciler) updateStatus(ctx context.Context, kcp *c
 	kcp.Status.ReadyReplicas = readyMachines
 	kcp.Status.UnavailableReplicas = replicas - readyMachines
 
+	if replicas > 0 && readyMachines == replicas {
+		conditions.MarkTrue(kcp, controlplanev1.MachinesReadyCondition)
+	} else {
+		conditions.MarkFalse(kcp, controlplanev1.MachinesReadyCondition, controlplanev1.MachinesNotReadyReason, clusterv1.ConditionSeverityWarning,
+			"%d of %d control plane Machines have a ready Node", readyMachines, replicas)
+	}
+
 	if !kcp.Status.Initialized {
 		if kcp.Status.ReadyReplicas > 0 {
 			kcp.Status.Initialized = true
 		}
 	}
+
+	switch {
+	case kcp.Status.Initialized:
+		conditions.MarkTrue(kcp, controlplanev1.AvailableCondition)
+	case cluster.Spec.ControlPlaneEndpoint.IsZero():
+		conditions.MarkFalse(kcp, controlplanev1.AvailableCondition, controlplanev1.WaitingForControlPlaneEndpointReason, clusterv1.ConditionSeverityInfo, "")
+	default:
+		conditions.MarkFalse(kcp, controlplanev1.AvailableCondition, controlplanev1.WaitingForKubeadmInitReason, clusterv1.ConditionSeverityInfo, "")
+	}
 	return nil
 }
 
-func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, numMachines int) error {
+// scaleUpControlPlane adds the given number of control plane Machines, spreading them across the failure domains
+// suitable for the control plane.
+func (r *KubeadmControlPlaneReconciler) scaleUpControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, machines []*clusterv1.Machine, numMachines int) error {
 	var errs []error
 
-	// Create the bootstrap configuration
+	// Create the bootstrap configuration. The cluster configuration is only kept to let the joining Machines
+	// know about an external etcd cluster, whose certificates they need.
 	bootstrapSpec := kcp.Spec.KubeadmConfigSpec.DeepCopy()
 	bootstrapSpec.InitConfiguration = nil
-	bootstrapSpec.ClusterConfiguration = nil
+	if !isExternalEtcd(kcp) {
+		bootstrapSpec.ClusterConfiguration = nil
+	}
 
+	placed := append([]*clusterv1.Machine{}, machines...)
 	for i := 0; i < numMachines; i++ {
-		err := r.cloneConfigsAndGenerateMachine(ctx, cluster, kcp, bootstrapSpec)
-		if err != nil {
+		failureDomain := selectFailureDomainForScaleUp(cluster.Status.FailureDomains, placed)
+		if err := r.cloneConfigsAndGenerateMachine(ctx, cluster, kcp, bootstrapSpec, failureDomain); err != nil {
 			errs = append(errs, errors.Wrap(err, "failed to clone and create an additional control plane Machine"))
+			continue
 		}
+		// Account for the new Machine when placing the next one.
+		placed = append(placed, &clusterv1.Machine{Spec: clusterv1.MachineSpec{FailureDomain: failureDomain}})
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
+// scaleDownControlPlane removes a single control plane Machine picked among the given candidates, making sure
+// its etcd member, unless etcd is external, and its kubeadm ClusterStatus entry are removed from the workload
+// cluster before the Machine itself is deleted.
+func (r *KubeadmControlPlaneReconciler) scaleDownControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, machines, candidates []*clusterv1.Machine) (ctrl.Result, error) {
+	logger := r.Log.WithValues("kubeadmControlPlane", kcp.Name, "namespace", kcp.Namespace, "cluster", cluster.Name)
+
+	// Wait for any delete in progress to complete before deleting another Machine.
+	if len(filterMachines(machines, machineIsDeleting)) > 0 {
+		logger.Info("Waiting for a control plane Machine to be deleted before scaling down further")
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+	}
+
+	machineToDelete := selectMachineForScaleDown(machines, candidates)
+	if machineToDelete == nil {
+		return ctrl.Result{}, errors.New("failed to pick a control plane Machine to delete")
+	}
+	logger = logger.WithValues("machine", machineToDelete.Name)
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	// The members of an external etcd cluster are not managed by the control plane.
+	if !isExternalEtcd(kcp) {
+		// Refuse to proceed if removing the etcd member would cause the etcd cluster to lose quorum.
+		ok, err := workloadCluster.CanSafelyRemoveEtcdMember(ctx, machineToDelete)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to check etcd quorum")
+		}
+		if !ok {
+			return ctrl.Result{}, errors.Errorf("removing the etcd member for Machine %q would cause the etcd cluster to lose quorum", machineToDelete.Name)
+		}
+
+		// If the Machine is running the etcd leader, move the leadership to a member which is going to stay.
+		leaderCandidate := selectLeaderCandidate(machines, machineToDelete)
+		if err := workloadCluster.ForwardEtcdLeadership(ctx, machineToDelete, leaderCandidate); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to move etcd leadership to another control plane Machine")
+		}
+
+		if err := workloadCluster.RemoveEtcdMemberForMachine(ctx, machineToDelete); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to remove etcd member for control plane Machine")
+		}
+	}
+
+	if err := workloadCluster.RemoveMachineFromKubeadmConfigMap(ctx, machineToDelete); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to remove control plane Machine from the kubeadm ClusterStatus")
+	}
+
+	logger.Info("Deleting control plane Machine")
+	if err := r.Client.Delete(ctx, machineToDelete); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to delete control plane Machine %s/%s", machineToDelete.Namespace, machineToDelete.Name)
+	}
+
+	// Requeue so that the next Machine, if any, is only removed once this one is gone.
+	return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+}
+
+// reconcileHealth checks the health of the control plane and records the outcome in the KubeadmControlPlane status.
+func (r *KubeadmControlPlaneReconciler) reconcileHealth(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, machines []*clusterv1.Machine) error {
+	err := r.controlPlaneIsHealthy(ctx, cluster, kcp, machines)
+	kcp.Status.Healthy = err == nil
+	kcp.Status.HealthCheckMessage = nil
+	if err != nil {
+		message := err.Error()
+		kcp.Status.HealthCheckMessage = &message
+	}
+	return err
+}
+
+// controlPlaneIsHealthy returns an error if any of the given control plane Machines doesn't have a ready Node,
+// if any etcd member or control plane component of the workload cluster is unhealthy, or if the etcd members
+// and the control plane Nodes don't match the given Machines. The members of an external etcd cluster are
+// checked through its endpoints and aren't expected to match the Machines. The health of etcd and of the control plane
+// components is recorded in the KubeadmControlPlane conditions.
+func (r *KubeadmControlPlaneReconciler) controlPlaneIsHealthy(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, machines []*clusterv1.Machine) error {
+	remoteClient, err := r.remoteClient(r.Client, cluster, r.scheme)
+	if err != nil {
+		return errors.Wrap(err, "failed to create remote cluster client")
+	}
+	for _, m := range machines {
+		node, err := getMachineNode(ctx, remoteClient, m)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the Node of Machine %q", m.Name)
+		}
+		if node == nil || !noderefutil.IsNodeReady(node) {
+			return errors.Errorf("Machine %q does not have a ready Node", m.Name)
+		}
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	if isExternalEtcd(kcp) {
+		err = workloadCluster.ExternalEtcdIsHealthy(ctx, kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.Endpoints)
+	} else {
+		var etcdResult internal.HealthCheckResult
+		etcdResult, err = workloadCluster.EtcdIsHealthy(ctx)
+		if err == nil {
+			err = etcdResult.CompareMachines(machines)
+		}
+	}
+	if err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.EtcdClusterHealthyCondition, controlplanev1.EtcdClusterUnhealthyReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrap(err, "etcd cluster is not healthy")
+	}
+	conditions.MarkTrue(kcp, controlplanev1.EtcdClusterHealthyCondition)
+
+	controlPlaneResult, err := workloadCluster.ControlPlaneIsHealthy(ctx)
+	if err == nil {
+		err = controlPlaneResult.CompareMachines(machines)
+	}
+	if err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.ControlPlaneComponentsHealthyCondition, controlplanev1.ControlPlaneComponentsUnhealthyReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrap(err, "control plane components are not healthy")
+	}
+	conditions.MarkTrue(kcp, controlplanev1.ControlPlaneComponentsHealthyCondition)
+	return nil
+}
+
+// isExternalEtcd returns true if the control plane uses an external etcd cluster instead of stacked etcd members.
+func isExternalEtcd(kcp *controlplanev1.KubeadmControlPlane) bool {
+	clusterConfiguration := kcp.Spec.KubeadmConfigSpec.ClusterConfiguration
+	return clusterConfiguration != nil && clusterConfiguration.Etcd.External != nil
+}
+
 func (r *KubeadmControlPlaneReconciler) initializeControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) error {
 	bootstrapSpec := kcp.Spec.KubeadmConfigSpec.DeepCopy()
 	bootstrapSpec.JoinConfiguration = nil
-	return r.cloneConfigsAndGenerateMachine(ctx, cluster, kcp, bootstrapSpec)
+	failureDomain := selectFailureDomainForScaleUp(cluster.Status.FailureDomains, nil)
+	return r.cloneConfigsAndGenerateMachine(ctx, cluster, kcp, bootstrapSpec, failureDomain)
 }
 
-func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, bootstrapSpec *bootstrapv1.KubeadmConfigSpec) error {
+func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, bootstrapSpec *bootstrapv1.KubeadmConfigSpec, failureDomain *string) error {
 	var errs []error
 
 	ownerRef := metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
@@ -355,18 +669,21 @@ func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx conte
 	)
 	if err != nil {
 		// Safe to return early here since no resources have been created yet.
+		conditions.MarkFalse(kcp, controlplanev1.MachinesCreatedCondition, controlplanev1.InfrastructureTemplateCloningFailedReason, clusterv1.ConditionSeverityError, err.Error())
 		return errors.Wrap(err, "failed to clone infrastructure template")
 	}
 
 	// Clone the bootstrap configuration
 	bootstrapRef, err := r.generateKubeadmConfig(ctx, kcp, cluster, bootstrapSpec)
 	if err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.MachinesCreatedCondition, controlplanev1.BootstrapTemplateCloningFailedReason, clusterv1.ConditionSeverityError, err.Error())
 		errs = append(errs, errors.Wrap(err, "failed to generate bootstrap config"))
 	}
 
 	// Only proceed to generating the Machine if we haven't encountered an error
 	if len(errs) == 0 {
-		if err := r.generateMachine(ctx, kcp, cluster, infraRef, bootstrapRef); err != nil {
+		if err := r.generateMachine(ctx, kcp, cluster, infraRef, bootstrapRef, failureDomain); err != nil {
+			conditions.MarkFalse(kcp, controlplanev1.MachinesCreatedCondition, controlplanev1.MachineGenerationFailedReason, clusterv1.ConditionSeverityError, err.Error())
 			errs = append(errs, errors.Wrap(err, "failed to create Machine"))
 		}
 	}
@@ -380,6 +697,7 @@ func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx conte
 		return utilerrors.NewAggregate(errs)
 	}
 
+	conditions.MarkTrue(kcp, controlplanev1.MachinesCreatedCondition)
 	return nil
 }
 
@@ -408,7 +726,10 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
 			Namespace: kcp.Namespace,
-			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:                     cluster.Name,
+				controlplanev1.KubeadmControlPlaneHashLabelKey: hash.Compute(&kcp.Spec),
+			},
 		},
 		Spec: *spec,
 	}
@@ -432,10 +753,13 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 	return bootstrapRef, nil
 }
 
-func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, cluster *clusterv1.Cluster, infraRef, bootstrapRef *corev1.ObjectReference) error {
+func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, cluster *clusterv1.Cluster, infraRef, bootstrapRef *corev1.ObjectReference, failureDomain *string) error {
+	labels := generateKubeadmControlPlaneLabels(cluster.Name)
+	labels[controlplanev1.KubeadmControlPlaneHashLabelKey] = hash.Compute(&kcp.Spec)
+
 	machine := &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:    generateKubeadmControlPlaneLabels(cluster.Name),
+			Labels:    labels,
 			Namespace: kcp.Namespace,
 			Name:      names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
 		},
@@ -446,6 +770,7 @@ func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp
 			Bootstrap: clusterv1.Bootstrap{
 				ConfigRef: bootstrapRef,
 			},
+			FailureDomain: failureDomain,
 		},
 	}
 
@@ -475,11 +800,69 @@ func generateKubeadmControlPlaneLabels(clusterName string) map[string]string {
 }
 
 // reconcileDelete handles KubeadmControlPlane deletion.
-func (r *KubeadmControlPlaneReconciler) reconcileDelete(_ context.Context, kcp *controlplanev1.KubeadmControlPlane, logger logr.Logger) (ctrl.Result, error) {
-	err := errors.New("Not Implemented")
+// The control plane Machines are only deleted once all the worker Machines of the Cluster are gone,
+// because the control plane is needed to drain their Nodes.
+func (r *KubeadmControlPlaneReconciler) reconcileDelete(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, logger logr.Logger) (ctrl.Result, error) {
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, kcp.ObjectMeta)
+	if err != nil {
+		logger.Error(err, "Failed to retrieve owner Cluster from the API Server")
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		// Without an owner Cluster there is nothing which can be cleaned up, the generated objects
+		// are owned by the KubeadmControlPlane and will be garbage collected.
+		logger.Info("Cluster Controller has not yet set OwnerRef, removing finalizer")
+		controllerutil.RemoveFinalizer(kcp, controlplanev1.KubeadmControlPlaneFinalizer)
+		return ctrl.Result{}, nil
+	}
+	logger = logger.WithValues("cluster", cluster.Name)
+	clusterName := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
 
+	allMachines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, allMachines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to list machines")
+	}
+	ownedMachines, err := r.getOwnedMachines(ctx, kcp, clusterName)
 	if err != nil {
-		logger.Error(err, "Not Implemented")
+		logger.Error(err, "failed to get list of owned machines")
+		return ctrl.Result{}, err
+	}
+
+	// Wait for the worker Machines to be deleted first. Control plane Machines not owned by the
+	// KubeadmControlPlane are neither waited for nor deleted.
+	workers := 0
+	for i := range allMachines.Items {
+		if !util.IsControlPlaneMachine(&allMachines.Items[i]) {
+			workers++
+		}
+	}
+	if workers > 0 {
+		logger.Info("Waiting for worker Machines to be deleted", "Worker Machines", workers)
+		r.recorder.Eventf(kcp, corev1.EventTypeNormal, "WaitingForWorkers", "Waiting for %d worker Machines to be deleted before deleting the control plane", workers)
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+	}
+
+	// Delete all the control plane Machines at once, and wait for them to be gone.
+	if len(ownedMachines) > 0 {
+		var errs []error
+		for _, m := range filterMachines(ownedMachines, machineIsNotDeleting) {
+			logger.Info("Deleting control plane Machine", "machine", m.Name)
+			if err := r.Client.Delete(ctx, m); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "failed to delete control plane Machine %s/%s", m.Namespace, m.Name))
+			}
+		}
+		if len(errs) > 0 {
+			err := kerrors.NewAggregate(errs)
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedDelete", "Failed to delete control plane Machines: %v", err)
+			return ctrl.Result{}, err
+		}
+		logger.Info("Waiting for control plane Machines to be deleted", "Existing Replicas", len(ownedMachines))
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+	}
+
+	// Clean up the bootstrap configurations and infrastructure clones which are not attached to a Machine anymore.
+	if err := r.cleanupGeneratedObjects(ctx, cluster, kcp); err != nil {
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedDelete", "Failed to clean up generated resources: %v", err)
 		return ctrl.Result{}, err
 	}
 
@@ -487,6 +870,52 @@ func (r *KubeadmControlPlaneReconciler) reconcileDelete(_ context.Context, kcp *
 	return ctrl.Result{}, nil
 }
 
+// cleanupGeneratedObjects deletes the KubeadmConfigs and the infrastructure Machines cloned from the
+// InfrastructureTemplate which are still controlled by the given KubeadmControlPlane.
+func (r *KubeadmControlPlaneReconciler) cleanupGeneratedObjects(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) error {
+	var refs []*corev1.ObjectReference
+
+	configs := &bootstrapv1.KubeadmConfigList{}
+	if err := r.Client.List(ctx, configs, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return errors.Wrap(err, "failed to list bootstrap configs")
+	}
+	for i := range configs.Items {
+		if isControlledByKubeadmControlPlane(&configs.Items[i], kcp) {
+			refs = append(refs, &corev1.ObjectReference{
+				APIVersion: bootstrapv1.GroupVersion.String(),
+				Kind:       "KubeadmConfig",
+				Namespace:  configs.Items[i].Namespace,
+				Name:       configs.Items[i].Name,
+			})
+		}
+	}
+
+	infraMachines := &unstructured.UnstructuredList{}
+	infraMachines.SetAPIVersion(kcp.Spec.InfrastructureTemplate.APIVersion)
+	infraMachines.SetKind(strings.TrimSuffix(kcp.Spec.InfrastructureTemplate.Kind, external.TemplateSuffix) + "List")
+	if err := r.Client.List(ctx, infraMachines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return errors.Wrap(err, "failed to list infrastructure machines")
+	}
+	for i := range infraMachines.Items {
+		if isControlledByKubeadmControlPlane(&infraMachines.Items[i], kcp) {
+			refs = append(refs, &corev1.ObjectReference{
+				APIVersion: infraMachines.Items[i].GetAPIVersion(),
+				Kind:       infraMachines.Items[i].GetKind(),
+				Namespace:  infraMachines.Items[i].GetNamespace(),
+				Name:       infraMachines.Items[i].GetName(),
+			})
+		}
+	}
+
+	return r.cleanupFromGeneration(ctx, refs...)
+}
+
+// isControlledByKubeadmControlPlane returns true if the controller of the given object is the given KubeadmControlPlane.
+func isControlledByKubeadmControlPlane(obj metav1.Object, kcp *controlplanev1.KubeadmControlPlane) bool {
+	controllerRef := metav1.GetControllerOf(obj)
+	return controllerRef != nil && controllerRef.Kind == "KubeadmControlPlane" && controllerRef.Name == kcp.Name
+}
+
 func (r *KubeadmControlPlaneReconciler) reconcileKubeconfig(ctx context.Context, clusterName types.NamespacedName, endpoint clusterv1.APIEndpoint, kcp *controlplanev1.KubeadmControlPlane) error {
 	if endpoint.IsZero() {
 		return nil
@@ -535,8 +964,7 @@ func (r *KubeadmControlPlaneReconciler) getOwnedMachines(ctx context.Context, kc
 	var ownedMachines []*clusterv1.Machine
 	for i := range allMachines.Items {
 		m := allMachines.Items[i]
-		controllerRef := metav1.GetControllerOf(&m)
-		if controllerRef != nil && controllerRef.Kind == "KubeadmControlPlane" && controllerRef.Name == kcp.Name {
+		if isControlledByKubeadmControlPlane(&m, kcp) {
 			ownedMachines = append(ownedMachines, &m)
 		}
 	}
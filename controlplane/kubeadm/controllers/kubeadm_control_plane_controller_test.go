@@ -20,13 +20,17 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/klogr"
@@ -41,6 +45,9 @@ import (
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/secret"
 )
@@ -696,7 +703,7 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 		Client: fakeClient,
 		Log:    log.Log,
 	}
-	g.Expect(r.generateMachine(context.Background(), kcp, cluster, infraRef, bootstrapRef)).To(gomega.Succeed())
+	g.Expect(r.generateMachine(context.Background(), kcp, cluster, infraRef, bootstrapRef, nil)).To(gomega.Succeed())
 
 	machineList := &clusterv1.MachineList{}
 	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
@@ -705,7 +712,9 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 	machine := machineList.Items[0]
 	g.Expect(machine.Name).To(gomega.HavePrefix(kcp.Name))
 	g.Expect(machine.Namespace).To(gomega.Equal(kcp.Namespace))
-	g.Expect(machine.Labels).To(gomega.Equal(generateKubeadmControlPlaneLabels(cluster.Name)))
+	expectedLabels := generateKubeadmControlPlaneLabels(cluster.Name)
+	expectedLabels[controlplanev1.KubeadmControlPlaneHashLabelKey] = hash.Compute(&kcp.Spec)
+	g.Expect(machine.Labels).To(gomega.Equal(expectedLabels))
 	g.Expect(machine.OwnerReferences).To(gomega.HaveLen(1))
 	g.Expect(machine.OwnerReferences).To(gomega.ContainElement(*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))))
 	g.Expect(machine.Spec).To(gomega.Equal(expectedMachineSpec))
@@ -733,7 +742,10 @@ func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
 	spec := bootstrapv1.KubeadmConfigSpec{}
 	expectedReferenceKind := "KubeadmConfig"
 	expectedReferenceAPIVersion := bootstrapv1.GroupVersion.String()
-	expectedLabels := map[string]string{clusterv1.ClusterLabelName: cluster.Name}
+	expectedLabels := map[string]string{
+		clusterv1.ClusterLabelName:                     cluster.Name,
+		controlplanev1.KubeadmControlPlaneHashLabelKey: hash.Compute(&kcp.Spec),
+	}
 	expectedOwner := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
 
 	r := &KubeadmControlPlaneReconciler{
@@ -854,6 +866,8 @@ func TestKubeadmControlPlaneReconciler_updateStatusNoMachines(t *testing.T) {
 	g.Expect(kcp.Status.Selector).NotTo(gomega.BeEmpty())
 	g.Expect(kcp.Status.FailureMessage).To(gomega.BeNil())
 	g.Expect(kcp.Status.FailureReason).To(gomega.BeEquivalentTo(""))
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.MachinesReadyCondition)).To(gomega.BeTrue())
+	g.Expect(conditions.GetReason(kcp, controlplanev1.AvailableCondition)).To(gomega.Equal(controlplanev1.WaitingForControlPlaneEndpointReason))
 }
 
 func createMachineNodePair(name string, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, ready bool) (*clusterv1.Machine, *corev1.Node) {
@@ -893,6 +907,99 @@ func createMachineNodePair(name string, cluster *clusterv1.Cluster, kcp *control
 	return machine, node
 }
 
+// controlPlaneFixtures holds a Cluster with a KubeadmControlPlane and its Machines.
+type controlPlaneFixtures struct {
+	cluster       *clusterv1.Cluster
+	kcp           *controlplanev1.KubeadmControlPlane
+	infraTemplate *unstructured.Unstructured
+	machines      []*clusterv1.Machine
+	nodes         []*corev1.Node
+}
+
+// objects returns copies of all the fixtures, to seed a fake client.
+func (f *controlPlaneFixtures) objects() []runtime.Object {
+	objs := []runtime.Object{f.cluster.DeepCopy(), f.kcp.DeepCopy(), f.infraTemplate.DeepCopy()}
+	for i := range f.machines {
+		objs = append(objs, f.machines[i].DeepCopy(), f.nodes[i].DeepCopy())
+	}
+	return objs
+}
+
+// createControlPlaneFixtures returns the Cluster "foo" and its KubeadmControlPlane "kcp-foo", which runs v1.17.0
+// with 3 replicas generated from the GenericMachineTemplate "infra-foo". A control plane Machine with a ready Node
+// is created for each of the given versions, named "test-<index>" and created one hour after the previous one.
+func createControlPlaneFixtures(versions ...string) *controlPlaneFixtures {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "test.local", Port: 9999},
+		},
+	}
+
+	genericMachineTemplate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "GenericMachineTemplate",
+			"apiVersion": "generic.io/v1",
+			"metadata": map[string]interface{}{
+				"name":      "infra-foo",
+				"namespace": cluster.Namespace,
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"hello": "world",
+					},
+				},
+			},
+		},
+	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kcp-foo",
+			Namespace: cluster.Namespace,
+			UID:       "kcp-uid",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					Kind:       "Cluster",
+					APIVersion: clusterv1.GroupVersion.String(),
+					Name:       cluster.Name,
+				},
+			},
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Replicas: utilpointer.Int32Ptr(3),
+			Version:  "v1.17.0",
+			InfrastructureTemplate: corev1.ObjectReference{
+				Kind:       genericMachineTemplate.GetKind(),
+				Namespace:  genericMachineTemplate.GetNamespace(),
+				Name:       genericMachineTemplate.GetName(),
+				APIVersion: genericMachineTemplate.GetAPIVersion(),
+			},
+		},
+	}
+
+	f := &controlPlaneFixtures{
+		cluster:       cluster,
+		kcp:           kcp,
+		infraTemplate: genericMachineTemplate,
+	}
+	for i, v := range versions {
+		m, n := createMachineNodePair(fmt.Sprintf("test-%d", i), cluster, kcp, true)
+		m.CreationTimestamp = metav1.NewTime(time.Date(2020, 1, 1, i, 0, 0, 0, time.UTC))
+		m.Spec.Version = utilpointer.StringPtr(v)
+		if v == kcp.Spec.Version {
+			m.Labels[controlplanev1.KubeadmControlPlaneHashLabelKey] = hash.Compute(&kcp.Spec)
+		}
+		f.machines = append(f.machines, m)
+		f.nodes = append(f.nodes, n)
+	}
+	return f
+}
+
 func TestKubeadmControlPlaneReconciler_updateStatusAllMachinesNotReady(t *testing.T) {
 	g := gomega.NewWithT(t)
 
@@ -942,6 +1049,9 @@ func TestKubeadmControlPlaneReconciler_updateStatusAllMachinesNotReady(t *testin
 	g.Expect(kcp.Status.FailureReason).To(gomega.BeEquivalentTo(""))
 	g.Expect(kcp.Status.Initialized).To(gomega.BeFalse())
 	g.Expect(kcp.Status.Ready).To(gomega.BeFalse())
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.MachinesReadyCondition)).To(gomega.BeTrue())
+	g.Expect(conditions.GetMessage(kcp, controlplanev1.MachinesReadyCondition)).To(gomega.Equal("0 of 3 control plane Machines have a ready Node"))
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.AvailableCondition)).To(gomega.BeTrue())
 }
 
 func TestKubeadmControlPlaneReconciler_updateStatusAllMachinesReady(t *testing.T) {
@@ -992,11 +1102,41 @@ func TestKubeadmControlPlaneReconciler_updateStatusAllMachinesReady(t *testing.T
 	g.Expect(kcp.Status.FailureMessage).To(gomega.BeNil())
 	g.Expect(kcp.Status.FailureReason).To(gomega.BeEquivalentTo(""))
 	g.Expect(kcp.Status.Initialized).To(gomega.BeTrue())
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.MachinesReadyCondition)).To(gomega.BeTrue())
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.AvailableCondition)).To(gomega.BeTrue())
 
 	// TODO: will need to be updated once we start handling Ready
 	g.Expect(kcp.Status.Ready).To(gomega.BeFalse())
 }
 
+func TestKubeadmControlPlaneReconciler_updateStatusUpdatedReplicas(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.16.1")
+	cluster, kcp := f.cluster, f.kcp
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+	}
+
+	g.Expect(r.updateStatus(context.Background(), kcp, cluster)).To(gomega.Succeed())
+	g.Expect(kcp.Status.Replicas).To(gomega.BeEquivalentTo(3))
+	g.Expect(kcp.Status.UpdatedReplicas).To(gomega.BeEquivalentTo(2))
+
+	// Machines which are going to be rolled out aren't reported as updated, even if they run the desired spec.
+	kcp.Spec.UpgradeAfter = &metav1.Time{Time: time.Now()}
+	g.Expect(r.updateStatus(context.Background(), kcp, cluster)).To(gomega.Succeed())
+	g.Expect(kcp.Status.UpdatedReplicas).To(gomega.BeEquivalentTo(0))
+}
+
 func TestKubeadmControlPlaneReconciler_updateStatusMachinesReadyMixed(t *testing.T) {
 	g := gomega.NewWithT(t)
 
@@ -1120,19 +1260,30 @@ func TestReconcileControlPlaneScaleUp(t *testing.T) {
 		},
 	}
 
+	kcp.Default()
+	g.Expect(kcp.ValidateCreate()).To(gomega.Succeed())
+
+	machineLabels := generateKubeadmControlPlaneLabels(cluster.Name)
+	machineLabels[controlplanev1.KubeadmControlPlaneHashLabelKey] = hash.Compute(&kcp.Spec)
 	machine := &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "foo-0",
 			Namespace: cluster.Namespace,
-			Labels:    generateKubeadmControlPlaneLabels(cluster.Name),
+			Labels:    machineLabels,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
 			},
 		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Kind: "Node", Name: "foo-0"},
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-0"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
 	}
-
-	kcp.Default()
-	g.Expect(kcp.ValidateCreate()).To(gomega.Succeed())
 
 	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
 	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
@@ -1142,6 +1293,7 @@ func TestReconcileControlPlaneScaleUp(t *testing.T) {
 		kcp.DeepCopy(),
 		cluster.DeepCopy(),
 		machine.DeepCopy(),
+		node.DeepCopy(),
 		genericMachineTemplate.DeepCopy(),
 	)
 	log.SetLogger(klogr.New())
@@ -1152,21 +1304,35 @@ func TestReconcileControlPlaneScaleUp(t *testing.T) {
 		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
 			return c, nil
 		},
+		managementCluster: &fakeManagementCluster{
+			Workload: &fakeWorkloadCluster{
+				ControlPlaneHealthCheck: internal.HealthCheckResult{"foo-0": nil},
+				EtcdHealthCheck:         internal.HealthCheckResult{"foo-0": nil},
+			},
+		},
 		recorder: record.NewFakeRecorder(32),
 	}
 
+	// A single Machine is added, the next one waits for the control plane to be healthy again.
 	result, err := r.reconcile(context.Background(), kcp, r.Log)
 	g.Expect(err).NotTo(gomega.HaveOccurred())
-	g.Expect(result).To(gomega.Equal(ctrl.Result{}))
+	g.Expect(result).To(gomega.Equal(ctrl.Result{Requeue: true}))
 
-	g.Expect(kcp.Status.Replicas).To(gomega.BeEquivalentTo(3))
+	g.Expect(kcp.Status.Replicas).To(gomega.BeEquivalentTo(2))
 
 	machineList := &clusterv1.MachineList{}
 	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace("test"))).To(gomega.Succeed())
-	g.Expect(machineList.Items).To(gomega.HaveLen(3))
+	g.Expect(machineList.Items).To(gomega.HaveLen(2))
 	for _, m := range machineList.Items {
 		g.Expect(m.Name).To(gomega.HavePrefix(kcp.Name))
 	}
+
+	// The new Machine has no Node nor etcd member yet, so the control plane is not scaled up further.
+	result, err = r.reconcile(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(ctrl.Result{RequeueAfter: healthCheckRequeueAfter}))
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace("test"))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(2))
 }
 
 func TestScaleUpControlPlaneAddsANewMachine(t *testing.T) {
@@ -1233,7 +1399,7 @@ func TestScaleUpControlPlaneAddsANewMachine(t *testing.T) {
 		recorder: record.NewFakeRecorder(32),
 	}
 
-	g.Expect(r.scaleUpControlPlane(context.Background(), cluster, kcp, 2)).To(gomega.Succeed())
+	g.Expect(r.scaleUpControlPlane(context.Background(), cluster, kcp, nil, 2)).To(gomega.Succeed())
 
 	machineList := &clusterv1.MachineList{}
 	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
@@ -1241,7 +1407,92 @@ func TestScaleUpControlPlaneAddsANewMachine(t *testing.T) {
 
 	for _, m := range machineList.Items {
 		g.Expect(m.Spec.Bootstrap.ConfigRef.Name).To(gomega.HavePrefix(kcp.Name))
+		g.Expect(m.Spec.FailureDomain).To(gomega.BeNil())
+	}
+}
+
+func TestScaleUpControlPlaneSpreadsMachinesAcrossFailureDomains(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+		Status: clusterv1.ClusterStatus{
+			FailureDomains: clusterv1.FailureDomains{
+				"one":     clusterv1.FailureDomainSpec{ControlPlane: true},
+				"two":     clusterv1.FailureDomainSpec{ControlPlane: true},
+				"workers": clusterv1.FailureDomainSpec{ControlPlane: false},
+			},
+		},
+	}
+
+	genericMachineTemplate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "GenericMachineTemplate",
+			"apiVersion": "generic.io/v1",
+			"metadata": map[string]interface{}{
+				"name":      "infra-foo",
+				"namespace": cluster.Namespace,
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"hello": "world",
+					},
+				},
+			},
+		},
 	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kcp-foo",
+			Namespace: cluster.Namespace,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			InfrastructureTemplate: corev1.ObjectReference{
+				Kind:       genericMachineTemplate.GetKind(),
+				Namespace:  genericMachineTemplate.GetNamespace(),
+				Name:       genericMachineTemplate.GetName(),
+				APIVersion: genericMachineTemplate.GetAPIVersion(),
+			},
+		},
+	}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(
+		scheme.Scheme,
+		cluster.DeepCopy(),
+		kcp.DeepCopy(),
+		genericMachineTemplate.DeepCopy(),
+	)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	existing := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing"},
+		Spec:       clusterv1.MachineSpec{FailureDomain: utilpointer.StringPtr("one")},
+	}
+	g.Expect(r.scaleUpControlPlane(context.Background(), cluster, kcp, []*clusterv1.Machine{existing}, 3)).To(gomega.Succeed())
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(3))
+
+	counts := map[string]int{}
+	for _, m := range machineList.Items {
+		g.Expect(m.Spec.FailureDomain).NotTo(gomega.BeNil())
+		counts[*m.Spec.FailureDomain]++
+	}
+	g.Expect(counts).To(gomega.Equal(map[string]int{"one": 1, "two": 2}))
 }
 
 func TestCloneConfigsAndGenerateMachine(t *testing.T) {
@@ -1306,7 +1557,7 @@ func TestCloneConfigsAndGenerateMachine(t *testing.T) {
 	bootstrapSpec := &bootstrapv1.KubeadmConfigSpec{
 		JoinConfiguration: &kubeadmv1.JoinConfiguration{},
 	}
-	g.Expect(r.cloneConfigsAndGenerateMachine(context.Background(), cluster, kcp, bootstrapSpec)).To(gomega.Succeed())
+	g.Expect(r.cloneConfigsAndGenerateMachine(context.Background(), cluster, kcp, bootstrapSpec, nil)).To(gomega.Succeed())
 
 	machineList := &clusterv1.MachineList{}
 	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
@@ -1328,3 +1579,698 @@ func TestCloneConfigsAndGenerateMachine(t *testing.T) {
 		g.Expect(m.Spec.Bootstrap.ConfigRef.Kind).To(gomega.Equal("KubeadmConfig"))
 	}
 }
+
+type fakeManagementCluster struct {
+	Workload *fakeWorkloadCluster
+	Err      error
+}
+
+func (f *fakeManagementCluster) GetWorkloadCluster(_ context.Context, _ *clusterv1.Cluster) (internal.WorkloadCluster, error) {
+	return f.Workload, f.Err
+}
+
+type fakeWorkloadCluster struct {
+	ControlPlaneHealthCheck   internal.HealthCheckResult
+	EtcdHealthCheck           internal.HealthCheckResult
+	EtcdHealthCheckErr        error
+	ExternalEtcdEndpoints     []string
+	UnsafeToRemove            bool
+	ForwardedLeadership       []string
+	RemovedEtcdMembers        []string
+	RemovedKubeadmAPIEndpoint []string
+	KubernetesVersion         string
+	KubeletConfigVersion      string
+	ClusterConfiguration      *kubeadmv1.ClusterConfiguration
+	KubeProxyVersion          string
+	KubeProxyImageRepository  string
+	CoreDNSConfiguration      *kubeadmv1.ClusterConfiguration
+	UpdateKubeProxyErr        error
+	CertificatesExpiryDates   map[string]time.Time
+}
+
+func (f *fakeWorkloadCluster) ControlPlaneIsHealthy(_ context.Context) (internal.HealthCheckResult, error) {
+	return f.ControlPlaneHealthCheck, nil
+}
+
+func (f *fakeWorkloadCluster) EtcdIsHealthy(_ context.Context) (internal.HealthCheckResult, error) {
+	return f.EtcdHealthCheck, f.EtcdHealthCheckErr
+}
+
+func (f *fakeWorkloadCluster) ExternalEtcdIsHealthy(_ context.Context, endpoints []string) error {
+	f.ExternalEtcdEndpoints = append(f.ExternalEtcdEndpoints, endpoints...)
+	return f.EtcdHealthCheckErr
+}
+
+func (f *fakeWorkloadCluster) ReconcileKubeletRBACRole(_ context.Context, _ *version.Version) error {
+	return nil
+}
+
+func (f *fakeWorkloadCluster) ReconcileKubeletRBACBinding(_ context.Context, _ *version.Version) error {
+	return nil
+}
+
+func (f *fakeWorkloadCluster) UpdateKubernetesVersionInKubeadmConfigMap(_ context.Context, v *version.Version) error {
+	f.KubernetesVersion = v.String()
+	return nil
+}
+
+func (f *fakeWorkloadCluster) UpdateKubeletConfigMap(_ context.Context, v *version.Version) error {
+	f.KubeletConfigVersion = v.String()
+	return nil
+}
+
+func (f *fakeWorkloadCluster) UpdateClusterConfigurationInKubeadmConfigMap(_ context.Context, clusterConfiguration *kubeadmv1.ClusterConfiguration) error {
+	f.ClusterConfiguration = clusterConfiguration
+	return nil
+}
+
+func (f *fakeWorkloadCluster) UpdateKubeProxyImageInfo(_ context.Context, v *version.Version, imageRepository string) error {
+	if f.UpdateKubeProxyErr != nil {
+		return f.UpdateKubeProxyErr
+	}
+	f.KubeProxyVersion = v.String()
+	f.KubeProxyImageRepository = imageRepository
+	return nil
+}
+
+func (f *fakeWorkloadCluster) UpdateCoreDNS(_ context.Context, clusterConfiguration *kubeadmv1.ClusterConfiguration) error {
+	f.CoreDNSConfiguration = clusterConfiguration
+	return nil
+}
+
+func (f *fakeWorkloadCluster) GetAPIServerCertificateExpiry(_ context.Context, nodeName string) (*time.Time, error) {
+	expiry, ok := f.CertificatesExpiryDates[nodeName]
+	if !ok {
+		expiry = time.Now().AddDate(1, 0, 0)
+	}
+	return &expiry, nil
+}
+
+func (f *fakeWorkloadCluster) CanSafelyRemoveEtcdMember(_ context.Context, _ *clusterv1.Machine) (bool, error) {
+	return !f.UnsafeToRemove, nil
+}
+
+func (f *fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, machine *clusterv1.Machine, _ *clusterv1.Machine) error {
+	f.ForwardedLeadership = append(f.ForwardedLeadership, machine.Name)
+	return nil
+}
+
+func (f *fakeWorkloadCluster) RemoveEtcdMemberForMachine(_ context.Context, machine *clusterv1.Machine) error {
+	f.RemovedEtcdMembers = append(f.RemovedEtcdMembers, machine.Name)
+	return nil
+}
+
+func (f *fakeWorkloadCluster) RemoveMachineFromKubeadmConfigMap(_ context.Context, machine *clusterv1.Machine) error {
+	f.RemovedKubeadmAPIEndpoint = append(f.RemovedKubeadmAPIEndpoint, machine.Name)
+	return nil
+}
+
+func TestKubeadmControlPlaneReconciler_scaleDownControlPlane(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	workload := &fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		Log:               log.Log,
+		recorder:          record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	result, err := r.scaleDownControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+
+	// The oldest Machine is removed from etcd and from the kubeadm ClusterStatus before being deleted.
+	g.Expect(workload.ForwardedLeadership).To(gomega.ConsistOf("test-0"))
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.ConsistOf("test-0"))
+	g.Expect(workload.RemovedKubeadmAPIEndpoint).To(gomega.ConsistOf("test-0"))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(4))
+	for _, m := range machineList.Items {
+		g.Expect(m.Name).NotTo(gomega.Equal("test-0"))
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_scaleDownControlPlaneExternalEtcd(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0")
+	f.kcp.Spec.Replicas = utilpointer.Int32Ptr(1)
+	f.kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+		Etcd: kubeadmv1.Etcd{
+			External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}},
+		},
+	}
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	// The etcd quorum check would refuse the scale down if it was performed.
+	workload := &fakeWorkloadCluster{UnsafeToRemove: true}
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		Log:               log.Log,
+		recorder:          record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	result, err := r.scaleDownControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+
+	// The members of an external etcd cluster are left untouched.
+	g.Expect(workload.ForwardedLeadership).To(gomega.BeEmpty())
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.BeEmpty())
+	g.Expect(workload.RemovedKubeadmAPIEndpoint).To(gomega.ConsistOf("test-0"))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(1))
+}
+
+func TestKubeadmControlPlaneReconciler_scaleDownControlPlaneRefusesToBreakQuorum(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0")
+	f.kcp.Spec.Replicas = utilpointer.Int32Ptr(1)
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	workload := &fakeWorkloadCluster{UnsafeToRemove: true}
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		Log:               log.Log,
+		recorder:          record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	_, err := r.scaleDownControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("quorum")))
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.BeEmpty())
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(3))
+}
+
+func TestKubeadmControlPlaneReconciler_scaleDownControlPlaneWaitsForDeletion(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+	now := metav1.Now()
+	machines[0].DeletionTimestamp = &now
+
+	workload := &fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fake.NewFakeClientWithScheme(scheme.Scheme),
+		Log:               log.Log,
+		recorder:          record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	result, err := r.scaleDownControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.BeEmpty())
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileHealth(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kcp-foo",
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	healthy := internal.HealthCheckResult{"test-0": nil, "test-1": nil, "test-2": nil}
+	unhealthy := internal.HealthCheckResult{"test-0": nil, "test-1": errors.New("etcd member reports alarms"), "test-2": nil}
+	unknownMember := internal.HealthCheckResult{"test-0": nil, "test-1": nil, "test-2": nil, "out-of-band": nil}
+
+	tests := []struct {
+		name               string
+		workload           *fakeWorkloadCluster
+		notReadyNode       bool
+		expectErr          bool
+		unhealthyCondition clusterv1.ConditionType
+	}{
+		{
+			name:     "healthy control plane",
+			workload: &fakeWorkloadCluster{EtcdHealthCheck: healthy, ControlPlaneHealthCheck: healthy},
+		},
+		{
+			name:         "a Node is not ready",
+			workload:     &fakeWorkloadCluster{EtcdHealthCheck: healthy, ControlPlaneHealthCheck: healthy},
+			notReadyNode: true,
+			expectErr:    true,
+		},
+		{
+			name:               "an etcd member is unhealthy",
+			workload:           &fakeWorkloadCluster{EtcdHealthCheck: unhealthy, ControlPlaneHealthCheck: healthy},
+			expectErr:          true,
+			unhealthyCondition: controlplanev1.EtcdClusterHealthyCondition,
+		},
+		{
+			name:               "the etcd cluster is unhealthy",
+			workload:           &fakeWorkloadCluster{EtcdHealthCheck: healthy, EtcdHealthCheckErr: errors.New("member list mismatch"), ControlPlaneHealthCheck: healthy},
+			expectErr:          true,
+			unhealthyCondition: controlplanev1.EtcdClusterHealthyCondition,
+		},
+		{
+			name:               "a control plane component is unhealthy",
+			workload:           &fakeWorkloadCluster{EtcdHealthCheck: healthy, ControlPlaneHealthCheck: unhealthy},
+			expectErr:          true,
+			unhealthyCondition: controlplanev1.ControlPlaneComponentsHealthyCondition,
+		},
+		{
+			name:               "a control plane node doesn't belong to any Machine",
+			workload:           &fakeWorkloadCluster{EtcdHealthCheck: unknownMember, ControlPlaneHealthCheck: healthy},
+			expectErr:          true,
+			unhealthyCondition: controlplanev1.EtcdClusterHealthyCondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			var machines []*clusterv1.Machine
+			objs := []runtime.Object{}
+			for i := 0; i < 3; i++ {
+				m, n := createMachineNodePair(fmt.Sprintf("test-%d", i), cluster, kcp, !(tt.notReadyNode && i == 0))
+				machines = append(machines, m)
+				objs = append(objs, n)
+			}
+
+			r := &KubeadmControlPlaneReconciler{
+				Client: fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+				Log:    log.Log,
+				remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+					return c, nil
+				},
+				managementCluster: &fakeManagementCluster{Workload: tt.workload},
+				recorder:          record.NewFakeRecorder(32),
+			}
+
+			kcp := kcp.DeepCopy()
+			err := r.reconcileHealth(context.Background(), cluster, kcp, machines)
+			if tt.expectErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				g.Expect(kcp.Status.Healthy).To(gomega.BeFalse())
+				g.Expect(kcp.Status.HealthCheckMessage).NotTo(gomega.BeNil())
+				if tt.unhealthyCondition != "" {
+					g.Expect(conditions.IsFalse(kcp, tt.unhealthyCondition)).To(gomega.BeTrue())
+					g.Expect(conditions.Get(kcp, tt.unhealthyCondition).Severity).To(gomega.Equal(clusterv1.ConditionSeverityWarning))
+				}
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(kcp.Status.Healthy).To(gomega.BeTrue())
+				g.Expect(kcp.Status.HealthCheckMessage).To(gomega.BeNil())
+				g.Expect(conditions.IsTrue(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(gomega.BeTrue())
+				g.Expect(conditions.IsTrue(kcp, controlplanev1.ControlPlaneComponentsHealthyCondition)).To(gomega.BeTrue())
+			}
+		})
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileHealthExternalEtcd(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kcp-foo",
+			Namespace: cluster.Namespace,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				ClusterConfiguration: &kubeadmv1.ClusterConfiguration{
+					Etcd: kubeadmv1.Etcd{
+						External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd-0:2379", "https://etcd-1:2379"}},
+					},
+				},
+			},
+		},
+	}
+
+	var machines []*clusterv1.Machine
+	objs := []runtime.Object{}
+	for i := 0; i < 3; i++ {
+		m, n := createMachineNodePair(fmt.Sprintf("test-%d", i), cluster, kcp, true)
+		machines = append(machines, m)
+		objs = append(objs, n)
+	}
+
+	healthy := internal.HealthCheckResult{"test-0": nil, "test-1": nil, "test-2": nil}
+	// The stacked etcd members are not checked, they wouldn't match the Machines.
+	workload := &fakeWorkloadCluster{EtcdHealthCheck: internal.HealthCheckResult{}, ControlPlaneHealthCheck: healthy}
+	r := &KubeadmControlPlaneReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		Log:    log.Log,
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{Workload: workload},
+		recorder:          record.NewFakeRecorder(32),
+	}
+
+	g.Expect(r.reconcileHealth(context.Background(), cluster, kcp, machines)).To(gomega.Succeed())
+	g.Expect(workload.ExternalEtcdEndpoints).To(gomega.ConsistOf("https://etcd-0:2379", "https://etcd-1:2379"))
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(gomega.BeTrue())
+
+	// An unhealthy external etcd cluster is reported.
+	workload.EtcdHealthCheckErr = errors.New("etcd endpoint reports alarms")
+	g.Expect(r.reconcileHealth(context.Background(), cluster, kcp, machines)).NotTo(gomega.Succeed())
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(gomega.BeTrue())
+}
+
+func TestReconcileControlPlaneWaitsForHealthyControlPlane(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0")
+	cluster, kcp := f.cluster, f.kcp
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+
+	// Pre-create the certificates and the kubeconfig, so that reconcile gets to the health check.
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), kubeconfigSecret)...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{
+			Workload: &fakeWorkloadCluster{
+				EtcdHealthCheck:         internal.HealthCheckResult{"test-0": errors.New("etcd member reports alarms")},
+				ControlPlaneHealthCheck: internal.HealthCheckResult{"test-0": nil},
+			},
+		},
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	result, err := r.reconcile(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(healthCheckRequeueAfter))
+	g.Expect(kcp.Status.Healthy).To(gomega.BeFalse())
+	g.Expect(*kcp.Status.HealthCheckMessage).To(gomega.ContainSubstring("alarms"))
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.CertificatesAvailableCondition)).To(gomega.BeTrue())
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.KubeconfigAvailableCondition)).To(gomega.BeTrue())
+	g.Expect(conditions.GetReason(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(gomega.Equal(controlplanev1.EtcdClusterUnhealthyReason))
+
+	// No Machine is added until the control plane is healthy.
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(1))
+}
+
+func TestReconcileControlPlaneAddons(t *testing.T) {
+	tests := []struct {
+		name               string
+		updateKubeProxyErr error
+		expectResult       ctrl.Result
+		expectKubeProxy    string
+	}{
+		{
+			name:            "updates the add-ons once all the Machines run the desired version",
+			expectResult:    ctrl.Result{Requeue: true},
+			expectKubeProxy: "1.17.0",
+		},
+		{
+			name:               "keeps scaling when the add-ons can't be updated",
+			updateKubeProxyErr: errors.New("kube-proxy DaemonSet not found"),
+			expectResult:       ctrl.Result{Requeue: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			f := createControlPlaneFixtures("v1.17.0")
+			cluster, kcp := f.cluster, f.kcp
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+
+			kubeconfigSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+					Namespace: cluster.Namespace,
+				},
+			}
+			fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), kubeconfigSecret)...)
+
+			workload := &fakeWorkloadCluster{
+				EtcdHealthCheck:         internal.HealthCheckResult{"test-0": nil},
+				ControlPlaneHealthCheck: internal.HealthCheckResult{"test-0": nil},
+				UpdateKubeProxyErr:      tt.updateKubeProxyErr,
+			}
+			r := &KubeadmControlPlaneReconciler{
+				Client: fakeClient,
+				Log:    log.Log,
+				remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+					return c, nil
+				},
+				managementCluster: &fakeManagementCluster{Workload: workload},
+				recorder:          record.NewFakeRecorder(32),
+			}
+
+			result, err := r.reconcile(context.Background(), kcp, r.Log)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(result).To(gomega.Equal(tt.expectResult))
+			g.Expect(workload.KubeProxyVersion).To(gomega.Equal(tt.expectKubeProxy))
+
+			// The control plane is scaled up either way.
+			machineList := &clusterv1.MachineList{}
+			g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+			g.Expect(machineList.Items).To(gomega.HaveLen(2))
+		})
+	}
+}
+
+func TestReconcileControlPlanePaused(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.16.1")
+	cluster, kcp := f.cluster, f.kcp
+	kcp.Spec.Paused = true
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), kubeconfigSecret)...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{
+			Workload: &fakeWorkloadCluster{
+				EtcdHealthCheck:         internal.HealthCheckResult{"test-0": nil},
+				ControlPlaneHealthCheck: internal.HealthCheckResult{"test-0": nil},
+			},
+		},
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	result, err := r.reconcile(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(ctrl.Result{}))
+
+	// The outdated Machine is neither upgraded nor joined by new ones, but the status is still reported.
+	g.Expect(kcp.Status.Replicas).To(gomega.BeEquivalentTo(1))
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(1))
+	g.Expect(*machineList.Items[0].Spec.Version).To(gomega.Equal("v1.16.1"))
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, kcp := f.cluster, f.kcp
+	kcp.Finalizers = []string{controlplanev1.KubeadmControlPlaneFinalizer}
+	controllerRef := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
+
+	infraMachine := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "GenericMachine",
+			"apiVersion": "generic.io/v1",
+			"metadata": map[string]interface{}{
+				"name":      "infra-foo-leftover",
+				"namespace": cluster.Namespace,
+				"labels": map[string]interface{}{
+					clusterv1.ClusterLabelName: cluster.Name,
+				},
+			},
+		},
+	}
+	infraMachine.SetOwnerReferences([]metav1.OwnerReference{controllerRef})
+	bootstrapConfig := &bootstrapv1.KubeadmConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "kcp-foo-leftover",
+			Namespace:       cluster.Namespace,
+			Labels:          map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+			OwnerReferences: []metav1.OwnerReference{controllerRef},
+		},
+	}
+	unrelatedConfig := &bootstrapv1.KubeadmConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-config",
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+		},
+	}
+
+	// The fake client needs to know about the infrastructure machine kind to list it.
+	testScheme := runtime.NewScheme()
+	g.Expect(scheme.AddToScheme(testScheme)).To(gomega.Succeed())
+	g.Expect(clusterv1.AddToScheme(testScheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(testScheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(testScheme)).To(gomega.Succeed())
+	infraGroupVersion := schema.GroupVersion{Group: "generic.io", Version: "v1"}
+	testScheme.AddKnownTypeWithName(infraGroupVersion.WithKind("GenericMachine"), &unstructured.Unstructured{})
+	testScheme.AddKnownTypeWithName(infraGroupVersion.WithKind("GenericMachineList"), &unstructured.UnstructuredList{})
+
+	// A control plane Machine which isn't owned by the KubeadmControlPlane is neither waited for nor deleted.
+	orphan, _ := createMachineNodePair("orphan", cluster, kcp, true)
+	orphan.OwnerReferences = nil
+
+	objs := append(f.objects(), infraMachine, bootstrapConfig, unrelatedConfig, orphan)
+	fakeClient := fake.NewFakeClientWithScheme(testScheme, objs...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// The control plane Machines are deleted first.
+	result, err := r.reconcileDelete(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+	g.Expect(kcp.Finalizers).To(gomega.ContainElement(controlplanev1.KubeadmControlPlaneFinalizer))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(1))
+	g.Expect(machineList.Items[0].Name).To(gomega.Equal(orphan.Name))
+
+	// Once they are gone, the generated objects are cleaned up and the finalizer is removed.
+	result, err = r.reconcileDelete(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(ctrl.Result{}))
+	g.Expect(kcp.Finalizers).NotTo(gomega.ContainElement(controlplanev1.KubeadmControlPlaneFinalizer))
+
+	configList := &bootstrapv1.KubeadmConfigList{}
+	g.Expect(fakeClient.List(context.Background(), configList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(configList.Items).To(gomega.HaveLen(1))
+	g.Expect(configList.Items[0].Name).To(gomega.Equal(unrelatedConfig.Name))
+
+	infraMachineList := &unstructured.UnstructuredList{}
+	infraMachineList.SetAPIVersion("generic.io/v1")
+	infraMachineList.SetKind("GenericMachineList")
+	g.Expect(fakeClient.List(context.Background(), infraMachineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(infraMachineList.Items).To(gomega.BeEmpty())
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileDeleteWaitsForWorkers(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, kcp := f.cluster, f.kcp
+	kcp.Finalizers = []string{controlplanev1.KubeadmControlPlaneFinalizer}
+	worker := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+		},
+	}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, append(f.objects(), worker)...)
+
+	recorder := record.NewFakeRecorder(32)
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: recorder,
+	}
+
+	result, err := r.reconcileDelete(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+	g.Expect(kcp.Finalizers).To(gomega.ContainElement(controlplanev1.KubeadmControlPlaneFinalizer))
+	g.Expect(recorder.Events).To(gomega.Receive(gomega.ContainSubstring("WaitingForWorkers")))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(4))
+}
+
+func TestKubeadmControlPlaneReconciler_reconcileDeleteNoCluster(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kcp := createControlPlaneFixtures().kcp
+	kcp.Finalizers = []string{controlplanev1.KubeadmControlPlaneFinalizer}
+	kcp.OwnerReferences = nil
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, kcp.DeepCopy()),
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	result, err := r.reconcileDelete(context.Background(), kcp, r.Log)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(ctrl.Result{}))
+	g.Expect(kcp.Finalizers).NotTo(gomega.ContainElement(controlplanev1.KubeadmControlPlaneFinalizer))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sort"
+	"time"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
+)
+
+// machineFilterFunc returns true if the given Machine should be kept by filterMachines.
+type machineFilterFunc func(machine *clusterv1.Machine) bool
+
+// filterMachines returns the Machines which pass all of the given filters.
+func filterMachines(machines []*clusterv1.Machine, filters ...machineFilterFunc) []*clusterv1.Machine {
+	var res []*clusterv1.Machine
+	for _, m := range machines {
+		keep := true
+		for _, filter := range filters {
+			if !filter(m) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// machineIsDeleting returns true if the Machine has a deletion timestamp.
+func machineIsDeleting(machine *clusterv1.Machine) bool {
+	return !machine.DeletionTimestamp.IsZero()
+}
+
+// machineIsNotDeleting returns true if the Machine doesn't have a deletion timestamp.
+func machineIsNotDeleting(machine *clusterv1.Machine) bool {
+	return !machineIsDeleting(machine)
+}
+
+// machineHasNode returns true if the Machine is linked to a Node.
+func machineHasNode(machine *clusterv1.Machine) bool {
+	return machine.Status.NodeRef != nil
+}
+
+// machineHasVersion returns a filter to find all the Machines running the given Kubernetes version.
+func machineHasVersion(version string) machineFilterFunc {
+	return func(machine *clusterv1.Machine) bool {
+		return machine.Spec.Version != nil && *machine.Spec.Version == version
+	}
+}
+
+// machineNeedsUpgrade returns a filter to find all the Machines which don't match the desired state
+// of the given KubeadmControlPlane, either because they run another version or because they were
+// generated from a different spec.
+func machineNeedsUpgrade(kcp *controlplanev1.KubeadmControlPlane) machineFilterFunc {
+	specHash := hash.Compute(&kcp.Spec)
+	return func(machine *clusterv1.Machine) bool {
+		machineVersion := ""
+		if machine.Spec.Version != nil {
+			machineVersion = *machine.Spec.Version
+		}
+		return machineVersion != kcp.Spec.Version || machine.Labels[controlplanev1.KubeadmControlPlaneHashLabelKey] != specHash
+	}
+}
+
+// machineCertificatesExpireSoon returns a filter to find all the Machines whose certificates expire within the
+// threshold configured in the given KubeadmControlPlane, if any.
+func machineCertificatesExpireSoon(kcp *controlplanev1.KubeadmControlPlane) machineFilterFunc {
+	if kcp.Spec.RolloutBefore == nil || kcp.Spec.RolloutBefore.CertificatesExpiryDays == nil {
+		return func(_ *clusterv1.Machine) bool {
+			return false
+		}
+	}
+	deadline := time.Now().AddDate(0, 0, int(*kcp.Spec.RolloutBefore.CertificatesExpiryDays))
+	return func(machine *clusterv1.Machine) bool {
+		return machine.Status.CertificatesExpiryDate != nil && machine.Status.CertificatesExpiryDate.Time.Before(deadline)
+	}
+}
+
+// machineCreatedBeforeUpgradeAfter returns a filter to find all the Machines created before the UpgradeAfter time
+// of the given KubeadmControlPlane, once that time has been reached.
+func machineCreatedBeforeUpgradeAfter(kcp *controlplanev1.KubeadmControlPlane) machineFilterFunc {
+	if kcp.Spec.UpgradeAfter == nil || time.Now().Before(kcp.Spec.UpgradeAfter.Time) {
+		return func(_ *clusterv1.Machine) bool {
+			return false
+		}
+	}
+	upgradeAfter := kcp.Spec.UpgradeAfter
+	return func(machine *clusterv1.Machine) bool {
+		return machine.CreationTimestamp.Before(upgradeAfter)
+	}
+}
+
+// machineNeedsRollout returns a filter to find all the Machines which must be replaced, either because they
+// need an upgrade, because their certificates are about to expire or because they predate the UpgradeAfter time.
+func machineNeedsRollout(kcp *controlplanev1.KubeadmControlPlane) machineFilterFunc {
+	needsUpgrade := machineNeedsUpgrade(kcp)
+	certificatesExpireSoon := machineCertificatesExpireSoon(kcp)
+	createdBeforeUpgradeAfter := machineCreatedBeforeUpgradeAfter(kcp)
+	return func(machine *clusterv1.Machine) bool {
+		return needsUpgrade(machine) || certificatesExpireSoon(machine) || createdBeforeUpgradeAfter(machine)
+	}
+}
+
+// sortMachinesByCreationTimestamp returns a copy of the given Machines sorted from the oldest to the newest.
+func sortMachinesByCreationTimestamp(machines []*clusterv1.Machine) []*clusterv1.Machine {
+	sorted := make([]*clusterv1.Machine, len(machines))
+	copy(sorted, machines)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+	})
+	return sorted
+}
+
+// selectMachineForScaleDown returns the candidate which should be removed first when scaling down.
+// Candidates in the failure domain hosting the most Machines are preferred, so that the remaining
+// Machines stay spread across failure domains; ties are broken by picking the oldest candidate.
+func selectMachineForScaleDown(machines, candidates []*clusterv1.Machine) *clusterv1.Machine {
+	counts := countMachinesByFailureDomain(filterMachines(machines, machineIsNotDeleting))
+
+	var selected *clusterv1.Machine
+	for _, m := range sortMachinesByCreationTimestamp(filterMachines(candidates, machineIsNotDeleting)) {
+		if selected == nil || counts[machineFailureDomain(m)] > counts[machineFailureDomain(selected)] {
+			selected = m
+		}
+	}
+	return selected
+}
+
+// selectFailureDomainForScaleUp returns the control plane failure domain hosting the fewest of the given
+// Machines, or nil if the cluster doesn't define any failure domain suitable for the control plane.
+func selectFailureDomainForScaleUp(failureDomains clusterv1.FailureDomains, machines []*clusterv1.Machine) *string {
+	eligible := failureDomains.FilterControlPlane()
+	if len(eligible) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(eligible))
+	for name := range eligible {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	counts := countMachinesByFailureDomain(filterMachines(machines, machineIsNotDeleting))
+	selected := names[0]
+	for _, name := range names[1:] {
+		if counts[name] < counts[selected] {
+			selected = name
+		}
+	}
+	return &selected
+}
+
+// countMachinesByFailureDomain returns the number of Machines in each failure domain, Machines without
+// a failure domain are counted under the empty string.
+func countMachinesByFailureDomain(machines []*clusterv1.Machine) map[string]int {
+	counts := map[string]int{}
+	for _, m := range machines {
+		counts[machineFailureDomain(m)]++
+	}
+	return counts
+}
+
+// machineFailureDomain returns the failure domain of the Machine, or the empty string if it has none.
+func machineFailureDomain(machine *clusterv1.Machine) string {
+	if machine.Spec.FailureDomain == nil {
+		return ""
+	}
+	return *machine.Spec.FailureDomain
+}
+
+// selectLeaderCandidate returns the newest Machine with a Node, other than the given one,
+// which can take over the etcd leadership.
+func selectLeaderCandidate(machines []*clusterv1.Machine, excluded *clusterv1.Machine) *clusterv1.Machine {
+	candidates := sortMachinesByCreationTimestamp(filterMachines(machines, machineIsNotDeleting, machineHasNode))
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].Name != excluded.Name {
+			return candidates[i]
+		}
+	}
+	return nil
+}
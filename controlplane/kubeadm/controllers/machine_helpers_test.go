@@ -0,0 +1,220 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
+)
+
+func machineCreatedAt(name string, hour int) *clusterv1.Machine {
+	return &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC)),
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Kind: "Node", Name: name},
+		},
+	}
+}
+
+func TestSelectMachineForScaleDown(t *testing.T) {
+	deleted := machineCreatedAt("deleted", 0)
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+
+	tests := []struct {
+		name     string
+		machines []*clusterv1.Machine
+		expected string
+	}{
+		{
+			name:     "picks the oldest Machine",
+			machines: []*clusterv1.Machine{machineCreatedAt("b", 2), machineCreatedAt("a", 1), machineCreatedAt("c", 3)},
+			expected: "a",
+		},
+		{
+			name:     "ignores Machines being deleted",
+			machines: []*clusterv1.Machine{deleted, machineCreatedAt("b", 2), machineCreatedAt("c", 3)},
+			expected: "b",
+		},
+		{
+			name:     "breaks ties by name",
+			machines: []*clusterv1.Machine{machineCreatedAt("b", 1), machineCreatedAt("a", 1)},
+			expected: "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(selectMachineForScaleDown(tt.machines, tt.machines).Name).To(gomega.Equal(tt.expected))
+		})
+	}
+}
+
+func TestSelectMachineForScaleDownNoCandidates(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(selectMachineForScaleDown(nil, nil)).To(gomega.BeNil())
+}
+
+func machineInFailureDomain(name string, hour int, failureDomain string) *clusterv1.Machine {
+	m := machineCreatedAt(name, hour)
+	m.Spec.FailureDomain = pointer.StringPtr(failureDomain)
+	return m
+}
+
+func TestSelectMachineForScaleDownPrefersMostCrowdedFailureDomain(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	machines := []*clusterv1.Machine{
+		machineInFailureDomain("a", 1, "one"),
+		machineInFailureDomain("b", 2, "two"),
+		machineInFailureDomain("c", 3, "two"),
+		machineInFailureDomain("d", 4, "three"),
+	}
+	g.Expect(selectMachineForScaleDown(machines, machines).Name).To(gomega.Equal("b"))
+
+	// Only candidates can be selected, even if their failure domain isn't the most crowded.
+	candidates := []*clusterv1.Machine{machines[0], machines[3]}
+	g.Expect(selectMachineForScaleDown(machines, candidates).Name).To(gomega.Equal("a"))
+}
+
+func TestSelectFailureDomainForScaleUp(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	failureDomains := clusterv1.FailureDomains{
+		"one":     clusterv1.FailureDomainSpec{ControlPlane: true},
+		"two":     clusterv1.FailureDomainSpec{ControlPlane: true},
+		"three":   clusterv1.FailureDomainSpec{ControlPlane: true},
+		"workers": clusterv1.FailureDomainSpec{ControlPlane: false},
+	}
+	deleting := machineInFailureDomain("deleting", 0, "three")
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	g.Expect(selectFailureDomainForScaleUp(nil, nil)).To(gomega.BeNil())
+	g.Expect(selectFailureDomainForScaleUp(clusterv1.FailureDomains{"workers": clusterv1.FailureDomainSpec{}}, nil)).To(gomega.BeNil())
+	g.Expect(*selectFailureDomainForScaleUp(failureDomains, nil)).To(gomega.Equal("one"))
+	g.Expect(*selectFailureDomainForScaleUp(failureDomains, []*clusterv1.Machine{
+		machineInFailureDomain("a", 1, "one"),
+		machineInFailureDomain("b", 2, "two"),
+		deleting,
+	})).To(gomega.Equal("three"))
+	g.Expect(*selectFailureDomainForScaleUp(failureDomains, []*clusterv1.Machine{
+		machineInFailureDomain("a", 1, "one"),
+		machineInFailureDomain("b", 2, "three"),
+	})).To(gomega.Equal("two"))
+}
+
+func TestSelectLeaderCandidate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	oldest := machineCreatedAt("a", 1)
+	newest := machineCreatedAt("c", 3)
+	noNode := machineCreatedAt("d", 4)
+	noNode.Status.NodeRef = nil
+	machines := []*clusterv1.Machine{oldest, machineCreatedAt("b", 2), newest, noNode}
+
+	g.Expect(selectLeaderCandidate(machines, oldest).Name).To(gomega.Equal("c"))
+	g.Expect(selectLeaderCandidate(machines, newest).Name).To(gomega.Equal("b"))
+	g.Expect(selectLeaderCandidate([]*clusterv1.Machine{oldest}, oldest)).To(gomega.BeNil())
+}
+
+func TestMachineHasVersion(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	machine := machineCreatedAt("a", 1)
+	g.Expect(machineHasVersion("v1.17.0")(machine)).To(gomega.BeFalse())
+	g.Expect(machineHasVersion("")(machine)).To(gomega.BeFalse())
+
+	machine.Spec.Version = pointer.StringPtr("v1.17.0")
+	g.Expect(machineHasVersion("v1.17.0")(machine)).To(gomega.BeTrue())
+	g.Expect(machineHasVersion("v1.16.1")(machine)).To(gomega.BeFalse())
+}
+
+func TestMachineNeedsUpgrade(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		Spec: controlplanev1.KubeadmControlPlaneSpec{Version: "v1.17.0"},
+	}
+	upToDate := machineCreatedAt("a", 1)
+	upToDate.Spec.Version = pointer.StringPtr("v1.17.0")
+	upToDate.Labels = map[string]string{controlplanev1.KubeadmControlPlaneHashLabelKey: hash.Compute(&kcp.Spec)}
+	outdatedVersion := upToDate.DeepCopy()
+	outdatedVersion.Spec.Version = pointer.StringPtr("v1.16.1")
+	outdatedSpec := upToDate.DeepCopy()
+	outdatedSpec.Labels[controlplanev1.KubeadmControlPlaneHashLabelKey] = "outdated"
+	noVersion := upToDate.DeepCopy()
+	noVersion.Spec.Version = nil
+	noHash := upToDate.DeepCopy()
+	noHash.Labels = nil
+
+	g.Expect(machineNeedsUpgrade(kcp)(upToDate)).To(gomega.BeFalse())
+	g.Expect(machineNeedsUpgrade(kcp)(outdatedVersion)).To(gomega.BeTrue())
+	g.Expect(machineNeedsUpgrade(kcp)(outdatedSpec)).To(gomega.BeTrue())
+	g.Expect(machineNeedsUpgrade(kcp)(noVersion)).To(gomega.BeTrue())
+	g.Expect(machineNeedsUpgrade(kcp)(noHash)).To(gomega.BeTrue())
+}
+
+func TestMachineNeedsRollout(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		Spec: controlplanev1.KubeadmControlPlaneSpec{Version: "v1.17.0"},
+	}
+	noExpiry := machineCreatedAt("a", 1)
+	noExpiry.Spec.Version = pointer.StringPtr("v1.17.0")
+	noExpiry.Labels = map[string]string{controlplanev1.KubeadmControlPlaneHashLabelKey: hash.Compute(&kcp.Spec)}
+	expiresLater := noExpiry.DeepCopy()
+	expiresLater.Status.CertificatesExpiryDate = &metav1.Time{Time: time.Now().AddDate(0, 0, 60)}
+	expiresSoon := noExpiry.DeepCopy()
+	expiresSoon.Status.CertificatesExpiryDate = &metav1.Time{Time: time.Now().AddDate(0, 0, 10)}
+	outdatedVersion := expiresLater.DeepCopy()
+	outdatedVersion.Spec.Version = pointer.StringPtr("v1.16.1")
+
+	// Without threshold, the certificates expiry is ignored.
+	g.Expect(machineNeedsRollout(kcp)(expiresSoon)).To(gomega.BeFalse())
+	g.Expect(machineNeedsRollout(kcp)(outdatedVersion)).To(gomega.BeTrue())
+
+	kcp.Spec.RolloutBefore = &controlplanev1.RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(30)}
+	g.Expect(machineNeedsRollout(kcp)(noExpiry)).To(gomega.BeFalse())
+	g.Expect(machineNeedsRollout(kcp)(expiresLater)).To(gomega.BeFalse())
+	g.Expect(machineNeedsRollout(kcp)(expiresSoon)).To(gomega.BeTrue())
+	g.Expect(machineNeedsRollout(kcp)(outdatedVersion)).To(gomega.BeTrue())
+
+	// Machines created before the UpgradeAfter time are only replaced once it is reached.
+	kcp.Spec.RolloutBefore = nil
+	kcp.Spec.UpgradeAfter = &metav1.Time{Time: time.Now().Add(time.Hour)}
+	g.Expect(machineNeedsRollout(kcp)(noExpiry)).To(gomega.BeFalse())
+	kcp.Spec.UpgradeAfter = &metav1.Time{Time: time.Now()}
+	g.Expect(machineNeedsRollout(kcp)(noExpiry)).To(gomega.BeTrue())
+	createdLater := noExpiry.DeepCopy()
+	createdLater.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Minute))
+	g.Expect(machineNeedsRollout(kcp)(createdLater)).To(gomega.BeFalse())
+}
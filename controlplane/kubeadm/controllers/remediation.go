@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+const (
+	// nodeNotReadyRemediationTimeout is how long the Node of a control plane Machine can stay NotReady
+	// before the Machine is remediated.
+	nodeNotReadyRemediationTimeout = 5 * time.Minute
+
+	// minRemediationInterval is the minimum time between two remediations, so that a systemic failure
+	// can't cascade into replacing the whole control plane.
+	minRemediationInterval = 10 * time.Minute
+)
+
+// getUnhealthyMachines returns the Machines which have failed, or whose Node has been NotReady for too long.
+func (r *KubeadmControlPlaneReconciler) getUnhealthyMachines(ctx context.Context, cluster *clusterv1.Cluster, machines []*clusterv1.Machine) ([]*clusterv1.Machine, error) {
+	remoteClient, err := r.remoteClient(r.Client, cluster, r.scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create remote cluster client")
+	}
+
+	var unhealthy []*clusterv1.Machine
+	for _, m := range filterMachines(machines, machineIsNotDeleting) {
+		if machineHasFailed(m) {
+			unhealthy = append(unhealthy, m)
+			continue
+		}
+		node, err := getMachineNode(ctx, remoteClient, m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the Node of Machine %q", m.Name)
+		}
+		if node != nil && nodeNotReadyFor(node, nodeNotReadyRemediationTimeout) {
+			unhealthy = append(unhealthy, m)
+		}
+	}
+	return unhealthy, nil
+}
+
+// remediateControlPlane deletes one of the given unhealthy Machines, after removing its stacked etcd member, so that it
+// gets replaced by a scale up once the remaining control plane is healthy. Remediations are rate-limited, and refused
+// when the etcd cluster would lose quorum.
+func (r *KubeadmControlPlaneReconciler) remediateControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, machines, unhealthy []*clusterv1.Machine) (ctrl.Result, error) {
+	logger := r.Log.WithValues("kubeadmControlPlane", kcp.Name, "namespace", kcp.Namespace, "cluster", cluster.Name)
+
+	// Only remediate one Machine at a time.
+	if len(filterMachines(machines, machineIsDeleting)) > 0 {
+		logger.Info("Waiting for a control plane Machine to be deleted before remediating another one")
+		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+	}
+
+	if lastRemediation, ok := kcp.Annotations[controlplanev1.KubeadmControlPlaneLastRemediationAnnotation]; ok {
+		last, err := time.Parse(time.RFC3339, lastRemediation)
+		if err != nil {
+			logger.Error(err, "Ignoring invalid last remediation annotation", "value", lastRemediation)
+		} else if wait := minRemediationInterval - time.Since(last); wait > 0 {
+			logger.Info("Waiting before remediating another control plane Machine", "unhealthy", len(unhealthy), "wait", wait.String())
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	machineToRemediate := sortMachinesByCreationTimestamp(unhealthy)[0]
+	logger = logger.WithValues("machine", machineToRemediate.Name)
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	// The members of an external etcd cluster are not managed by the control plane. A stacked member can't be
+	// removed when no other member is reachable, for example when there is a single replica.
+	if !isExternalEtcd(kcp) {
+		ok, err := workloadCluster.CanSafelyRemoveEtcdMember(ctx, machineToRemediate)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to check etcd quorum")
+		}
+		if !ok {
+			logger.Info("Not remediating the control plane Machine, the etcd cluster would lose quorum")
+			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "RemediationBlocked",
+				"Can't remediate control plane Machine %q, removing its etcd member would cause the etcd cluster to lose quorum", machineToRemediate.Name)
+			return ctrl.Result{RequeueAfter: healthCheckRequeueAfter}, nil
+		}
+	}
+
+	// Record the remediation before changing anything, so that the rate limit holds even if the reconciliation
+	// fails half-way through.
+	patchHelper, err := patch.NewHelper(kcp, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if kcp.Annotations == nil {
+		kcp.Annotations = map[string]string{}
+	}
+	kcp.Annotations[controlplanev1.KubeadmControlPlaneLastRemediationAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if err := patchHelper.Patch(ctx, kcp); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to record the remediation on the KubeadmControlPlane")
+	}
+
+	if !isExternalEtcd(kcp) {
+		// The etcd leadership isn't forwarded, an unhealthy member is unlikely to be the leader and etcd elects a
+		// new one by itself if it is.
+		if err := workloadCluster.RemoveEtcdMemberForMachine(ctx, machineToRemediate); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to remove etcd member for control plane Machine")
+		}
+	}
+
+	if err := workloadCluster.RemoveMachineFromKubeadmConfigMap(ctx, machineToRemediate); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to remove control plane Machine from the kubeadm ClusterStatus")
+	}
+
+	logger.Info("Deleting unhealthy control plane Machine")
+	if err := r.Client.Delete(ctx, machineToRemediate); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to delete control plane Machine %s/%s", machineToRemediate.Namespace, machineToRemediate.Name)
+	}
+
+	r.recorder.Eventf(kcp, corev1.EventTypeNormal, "Remediated", "Deleted unhealthy control plane Machine %q", machineToRemediate.Name)
+
+	// The replacement Machine is created by a scale up once the unhealthy one is gone.
+	return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
+}
+
+// machineHasFailed returns true if the Machine reported a terminal failure.
+func machineHasFailed(machine *clusterv1.Machine) bool {
+	return machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil ||
+		machine.Status.GetTypedPhase() == clusterv1.MachinePhaseFailed
+}
+
+// nodeNotReadyFor returns true if the Node has not been ready for at least the given duration.
+func nodeNotReadyFor(node *corev1.Node, timeout time.Duration) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status != corev1.ConditionTrue && time.Since(c.LastTransitionTime.Time) >= timeout
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+)
+
+func TestKubeadmControlPlaneReconciler_getUnhealthyMachines(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, machines, nodes := f.cluster, f.machines, f.nodes
+
+	// test-0 has failed, test-1 has been NotReady for too long and test-2 only recently became NotReady.
+	machines[0].Status.FailureMessage = pointer.StringPtr("instance terminated")
+	nodes[1].Status.Conditions[0].Status = corev1.ConditionFalse
+	nodes[1].Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * nodeNotReadyRemediationTimeout))
+	nodes[2].Status.Conditions[0].Status = corev1.ConditionUnknown
+	nodes[2].Status.Conditions[0].LastTransitionTime = metav1.Now()
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+	}
+
+	unhealthy, err := r.getUnhealthyMachines(context.Background(), cluster, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(unhealthy).To(gomega.HaveLen(2))
+	g.Expect(unhealthy[0].Name).To(gomega.Equal("test-0"))
+	g.Expect(unhealthy[1].Name).To(gomega.Equal("test-1"))
+}
+
+func TestKubeadmControlPlaneReconciler_remediateControlPlane(t *testing.T) {
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+
+	tests := []struct {
+		name              string
+		lastRemediation   *time.Time
+		unsafeToRemove    bool
+		externalEtcd      bool
+		deletingMachine   bool
+		expectRemediation bool
+	}{
+		{
+			name:              "removes the oldest unhealthy Machine",
+			expectRemediation: true,
+		},
+		{
+			name:              "remediates again once the minimum interval has elapsed",
+			lastRemediation:   timePtr(time.Now().Add(-2 * minRemediationInterval)),
+			expectRemediation: true,
+		},
+		{
+			name:            "is rate-limited",
+			lastRemediation: timePtr(time.Now().Add(-time.Minute)),
+		},
+		{
+			name:           "keeps the etcd quorum",
+			unsafeToRemove: true,
+		},
+		{
+			name:              "doesn't manage the members of an external etcd cluster",
+			unsafeToRemove:    true,
+			externalEtcd:      true,
+			expectRemediation: true,
+		},
+		{
+			name:            "waits for other deletions",
+			deletingMachine: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			kcp := kcp.DeepCopy()
+			if tt.externalEtcd {
+				kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+					Etcd: kubeadmv1.Etcd{External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+				}
+			}
+			if tt.lastRemediation != nil {
+				kcp.Annotations = map[string]string{
+					controlplanev1.KubeadmControlPlaneLastRemediationAnnotation: tt.lastRemediation.Format(time.RFC3339),
+				}
+			}
+			machines := []*clusterv1.Machine{machines[0].DeepCopy(), machines[1].DeepCopy(), machines[2].DeepCopy()}
+			if tt.deletingMachine {
+				now := metav1.Now()
+				machines[0].DeletionTimestamp = &now
+			}
+			unhealthy := []*clusterv1.Machine{machines[2], machines[1]}
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+			fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, kcp.DeepCopy(), machines[0], machines[1], machines[2])
+
+			workload := &fakeWorkloadCluster{UnsafeToRemove: tt.unsafeToRemove}
+			r := &KubeadmControlPlaneReconciler{
+				Client:            fakeClient,
+				Log:               log.Log,
+				recorder:          record.NewFakeRecorder(32),
+				managementCluster: &fakeManagementCluster{Workload: workload},
+			}
+
+			result, err := r.remediateControlPlane(context.Background(), cluster, kcp, machines, unhealthy)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(result.RequeueAfter).NotTo(gomega.BeZero())
+
+			machineList := &clusterv1.MachineList{}
+			g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+			if !tt.expectRemediation {
+				g.Expect(machineList.Items).To(gomega.HaveLen(3))
+				g.Expect(workload.RemovedEtcdMembers).To(gomega.BeEmpty())
+				return
+			}
+
+			g.Expect(machineList.Items).To(gomega.HaveLen(2))
+			for _, m := range machineList.Items {
+				g.Expect(m.Name).NotTo(gomega.Equal("test-1"))
+			}
+			if tt.externalEtcd {
+				g.Expect(workload.RemovedEtcdMembers).To(gomega.BeEmpty())
+			} else {
+				g.Expect(workload.RemovedEtcdMembers).To(gomega.ConsistOf("test-1"))
+			}
+			g.Expect(workload.RemovedKubeadmAPIEndpoint).To(gomega.ConsistOf("test-1"))
+
+			// The remediation is persisted independently of the status of the KubeadmControlPlane.
+			persistedKCP := &controlplanev1.KubeadmControlPlane{}
+			g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: kcp.Namespace, Name: kcp.Name}, persistedKCP)).To(gomega.Succeed())
+			lastRemediation, err := time.Parse(time.RFC3339, persistedKCP.Annotations[controlplanev1.KubeadmControlPlaneLastRemediationAnnotation])
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(lastRemediation).To(gomega.BeTemporally("~", time.Now(), time.Minute))
+		})
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_remediateControlPlaneSingleReplica(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+	kcp.Spec.Replicas = pointer.Int32Ptr(1)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, kcp.DeepCopy(), machines[0].DeepCopy())
+
+	// The etcd member of the only Machine can't be removed safely.
+	recorder := record.NewFakeRecorder(32)
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		Log:               log.Log,
+		recorder:          recorder,
+		managementCluster: &fakeManagementCluster{Workload: &fakeWorkloadCluster{UnsafeToRemove: true}},
+	}
+
+	result, err := r.remediateControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(healthCheckRequeueAfter))
+	g.Expect(recorder.Events).To(gomega.Receive(gomega.ContainSubstring("RemediationBlocked")))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(1))
+	g.Expect(kcp.Annotations).NotTo(gomega.HaveKey(controlplanev1.KubeadmControlPlaneLastRemediationAnnotation))
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
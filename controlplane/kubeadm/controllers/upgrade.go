@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+)
+
+// upgradeControlPlane replaces the control plane Machines which run an outdated version or were generated
+// from an outdated spec one at a time: a new Machine is created first, and an outdated Machine is removed
+// once the new one joined. When the rollout strategy doesn't allow any surge, the outdated Machine is removed
+// first instead. The caller must make sure that the control plane is healthy before each step.
+func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, ownedMachines, requireUpgrade []*clusterv1.Machine) (ctrl.Result, error) {
+	logger := r.Log.WithValues("kubeadmControlPlane", kcp.Name, "namespace", kcp.Namespace, "cluster", cluster.Name)
+
+	if err := validateVersionSkew(ownedMachines, kcp.Spec.Version); err != nil {
+		return ctrl.Result{}, err
+	}
+	parsedVersion, err := version.ParseSemantic(kcp.Spec.Version)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	// Prepare the workload cluster so that Machines at the new version can join it.
+	if err := workloadCluster.ReconcileKubeletRBACRole(ctx, parsedVersion); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the kubelet config Role")
+	}
+	if err := workloadCluster.ReconcileKubeletRBACBinding(ctx, parsedVersion); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the kubelet config RoleBinding")
+	}
+	if err := workloadCluster.UpdateKubernetesVersionInKubeadmConfigMap(ctx, parsedVersion); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to update the kubernetes version in the kubeadm config map")
+	}
+	if err := workloadCluster.UpdateKubeletConfigMap(ctx, parsedVersion); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create the kubelet config map for the new version")
+	}
+	if kcp.Spec.KubeadmConfigSpec.ClusterConfiguration != nil {
+		if err := workloadCluster.UpdateClusterConfigurationInKubeadmConfigMap(ctx, kcp.Spec.KubeadmConfigSpec.ClusterConfiguration); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to update the cluster configuration in the kubeadm config map")
+		}
+	}
+
+	// Scale up first if a surge is allowed, so that there is always a spare Machine while the outdated one is removed.
+	if len(ownedMachines) < int(*kcp.Spec.Replicas)+rolloutMaxSurge(kcp) {
+		logger.Info("Adding a control plane Machine at the desired version", "version", kcp.Spec.Version)
+		if err := r.scaleUpControlPlane(ctx, cluster, kcp, ownedMachines, 1); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Removing an outdated control plane Machine", "outdated", len(requireUpgrade))
+	return r.scaleDownControlPlane(ctx, cluster, kcp, ownedMachines, requireUpgrade)
+}
+
+// rolloutMaxSurge returns the number of control plane Machines which can be created above the desired number of
+// replicas during a rollout, which defaults to 1.
+func rolloutMaxSurge(kcp *controlplanev1.KubeadmControlPlane) int {
+	strategy := kcp.Spec.RolloutStrategy
+	if strategy == nil || strategy.RollingUpdate == nil || strategy.RollingUpdate.MaxSurge == nil {
+		return 1
+	}
+	return strategy.RollingUpdate.MaxSurge.IntValue()
+}
+
+// validateVersionSkew returns an error if moving the given Machines to the target version would not be
+// supported by kubeadm, that is if it is a downgrade or if it skips one or more minor versions.
+func validateVersionSkew(machines []*clusterv1.Machine, target string) error {
+	targetVersion, err := version.ParseSemantic(target)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", target)
+	}
+
+	for _, m := range machines {
+		if m.Spec.Version == nil {
+			continue
+		}
+		current, err := version.ParseSemantic(*m.Spec.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse kubernetes version %q of Machine %q", *m.Spec.Version, m.Name)
+		}
+		if current.Major() != targetVersion.Major() {
+			return errors.Errorf("cannot upgrade Machine %q from %s to %s: changing the major version is not supported", m.Name, *m.Spec.Version, target)
+		}
+		if targetVersion.Minor() < current.Minor() {
+			return errors.Errorf("cannot upgrade Machine %q from %s to %s: downgrading the minor version is not supported", m.Name, *m.Spec.Version, target)
+		}
+		if targetVersion.Minor() > current.Minor()+1 {
+			return errors.Errorf("cannot upgrade Machine %q from %s to %s: skipping minor versions is not supported", m.Name, *m.Spec.Version, target)
+		}
+	}
+	return nil
+}
+
+// reconcileAddons brings the add-ons kubeadm installs in the workload cluster, kube-proxy and CoreDNS, in line with the
+// KubeadmControlPlane. The caller must make sure that all the control plane Machines run the desired version.
+func (r *KubeadmControlPlaneReconciler) reconcileAddons(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) error {
+	parsedVersion, err := version.ParseSemantic(kcp.Spec.Version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", kcp.Spec.Version)
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	imageRepository := ""
+	if kcp.Spec.KubeadmConfigSpec.ClusterConfiguration != nil {
+		imageRepository = kcp.Spec.KubeadmConfigSpec.ClusterConfiguration.ImageRepository
+	}
+	if err := workloadCluster.UpdateKubeProxyImageInfo(ctx, parsedVersion, imageRepository); err != nil {
+		return errors.Wrap(err, "failed to update the kube-proxy image")
+	}
+	if err := workloadCluster.UpdateCoreDNS(ctx, kcp.Spec.KubeadmConfigSpec.ClusterConfiguration); err != nil {
+		return errors.Wrap(err, "failed to update CoreDNS")
+	}
+	return nil
+}
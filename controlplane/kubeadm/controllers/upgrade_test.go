@@ -0,0 +1,246 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
+)
+
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneScalesUpFirst(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.16.1", "v1.16.1", "v1.16.1")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	workload := &fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	_, err := r.upgradeControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The workload cluster is prepared for the new version.
+	g.Expect(workload.KubernetesVersion).To(gomega.Equal("1.17.0"))
+	g.Expect(workload.KubeletConfigVersion).To(gomega.Equal("1.17.0"))
+
+	// A Machine at the new version is added, nothing is removed.
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.BeEmpty())
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(4))
+	upgraded := 0
+	for _, m := range machineList.Items {
+		if *m.Spec.Version == kcp.Spec.Version {
+			upgraded++
+		}
+	}
+	g.Expect(upgraded).To(gomega.Equal(1))
+}
+
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneRemovesOutdatedMachine(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	// The oldest Machine is already up to date, so it must not be picked for deletion.
+	f := createControlPlaneFixtures("v1.17.0", "v1.16.1", "v1.16.1", "v1.16.1")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	workload := &fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	requireUpgrade := filterMachines(machines, machineNeedsUpgrade(kcp))
+	result, err := r.upgradeControlPlane(context.Background(), cluster, kcp, machines, requireUpgrade)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.ConsistOf("test-1"))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(3))
+}
+
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneWithoutSurgeRemovesFirst(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.16.1", "v1.16.1", "v1.16.1")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+	maxSurge := intstr.FromInt(0)
+	kcp.Spec.RolloutStrategy = &controlplanev1.RolloutStrategy{
+		Type:          controlplanev1.RollingUpdateStrategyType,
+		RollingUpdate: &controlplanev1.RollingUpdate{MaxSurge: &maxSurge},
+	}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	workload := &fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	// An outdated Machine is removed before any new one is created.
+	result, err := r.upgradeControlPlane(context.Background(), cluster, kcp, machines, machines)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.RequeueAfter).To(gomega.Equal(deleteRequeueAfter))
+	g.Expect(workload.RemovedEtcdMembers).To(gomega.ConsistOf("test-0"))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(2))
+
+	// Once it is gone, its replacement is created.
+	_, err = r.upgradeControlPlane(context.Background(), cluster, kcp, machines[1:], machines[1:])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	machineList = &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(3))
+}
+
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneRollsOutSpecChanges(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	f := createControlPlaneFixtures("v1.17.0", "v1.17.0", "v1.17.0")
+	cluster, kcp, machines := f.cluster, f.kcp, f.machines
+	kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+		APIServer: kubeadmv1.APIServer{
+			ControlPlaneComponent: kubeadmv1.ControlPlaneComponent{
+				ExtraArgs: map[string]string{"audit-log-maxage": "30"},
+			},
+		},
+	}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	g.Expect(controlplanev1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, f.objects()...)
+
+	workload := &fakeWorkloadCluster{}
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+		remoteClient: func(c client.Client, _ *clusterv1.Cluster, _ *runtime.Scheme) (client.Client, error) {
+			return c, nil
+		},
+		managementCluster: &fakeManagementCluster{Workload: workload},
+	}
+
+	// All the Machines run the right version, but were generated before the spec changed.
+	requireUpgrade := filterMachines(machines, machineNeedsUpgrade(kcp))
+	g.Expect(requireUpgrade).To(gomega.HaveLen(3))
+
+	_, err := r.upgradeControlPlane(context.Background(), cluster, kcp, machines, requireUpgrade)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(workload.ClusterConfiguration).To(gomega.Equal(kcp.Spec.KubeadmConfigSpec.ClusterConfiguration))
+
+	machineList := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(context.Background(), machineList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(machineList.Items).To(gomega.HaveLen(4))
+	g.Expect(filterMachines(machineListToSlice(machineList), machineNeedsUpgrade(kcp))).To(gomega.HaveLen(3))
+
+	configList := &bootstrapv1.KubeadmConfigList{}
+	g.Expect(fakeClient.List(context.Background(), configList, client.InNamespace(cluster.Namespace))).To(gomega.Succeed())
+	g.Expect(configList.Items).To(gomega.HaveLen(1))
+	g.Expect(configList.Items[0].Labels).To(gomega.HaveKeyWithValue(controlplanev1.KubeadmControlPlaneHashLabelKey, hash.Compute(&kcp.Spec)))
+}
+
+func machineListToSlice(list *clusterv1.MachineList) []*clusterv1.Machine {
+	machines := make([]*clusterv1.Machine, 0, len(list.Items))
+	for i := range list.Items {
+		machines = append(machines, &list.Items[i])
+	}
+	return machines
+}
+
+func TestValidateVersionSkew(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		target    string
+		expectErr bool
+	}{
+		{name: "patch upgrade", current: "v1.16.1", target: "v1.16.3"},
+		{name: "minor upgrade", current: "v1.16.1", target: "v1.17.0"},
+		{name: "same version", current: "v1.17.0", target: "v1.17.0"},
+		{name: "skipping a minor version", current: "v1.15.3", target: "v1.17.0", expectErr: true},
+		{name: "minor downgrade", current: "v1.17.0", target: "v1.16.1", expectErr: true},
+		{name: "major upgrade", current: "v1.17.0", target: "v2.0.0", expectErr: true},
+		{name: "invalid target", current: "v1.17.0", target: "latest", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			machine := &clusterv1.Machine{Spec: clusterv1.MachineSpec{Version: utilpointer.StringPtr(tt.current)}}
+			err := validateVersionSkew([]*clusterv1.Machine{machine}, tt.target)
+			if tt.expectErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+// etcdClientCertificateRenewBefore is how long before its expiry a cached etcd client certificate is replaced.
+const etcdClientCertificateRenewBefore = 24 * time.Hour
+
+// ManagementCluster holds operations on the management cluster.
+type ManagementCluster struct {
+	Client ctrlclient.Client
+	Scheme *runtime.Scheme
+
+	// Tracker, if set, provides cached clients for the workload clusters.
+	Tracker *remote.ClusterCacheTracker
+
+	lock                   sync.Mutex
+	etcdClientCertificates map[types.NamespacedName]etcdClientCertificate
+}
+
+// etcdClientCertificate is a client certificate signed by the etcd CA of a cluster. It is cached so that a new
+// key is not generated and signed every time the cluster is reconciled.
+type etcdClientCertificate struct {
+	// caData is the PEM encoded etcd CA the certificate was signed by.
+	caData   []byte
+	keyPair  tls.Certificate
+	notAfter time.Time
+}
+
+// GetWorkloadCluster builds a cluster object.
+// The cluster comes with an etcd client generator to connect to any etcd pod living on a managed machine.
+func (m *ManagementCluster) GetWorkloadCluster(ctx context.Context, cluster *clusterv1.Cluster) (WorkloadCluster, error) {
+	restConfig, err := remote.RESTConfig(m.Client, cluster)
+	if err != nil {
+		return nil, err
+	}
+	restConfig.Timeout = 30 * time.Second
+
+	clusterKey := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	var c ctrlclient.Client
+	if m.Tracker != nil {
+		c, err = m.Tracker.GetClient(ctx, clusterKey)
+	} else {
+		c, err = remote.NewClusterClient(m.Client, cluster, m.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clientCert, caPool, err := m.etcdClientCredentials(clusterKey)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:      caPool,
+		Certificates: []tls.Certificate{clientCert},
+	}
+
+	return &Workload{
+		Client:     c,
+		restConfig: restConfig,
+		etcdClientGenerator: &etcdClientGenerator{
+			restConfig: restConfig,
+			tlsConfig:  tlsConfig,
+		},
+	}, nil
+}
+
+// etcdClientCredentials returns a client certificate signed by the etcd CA of the given cluster
+// along with a pool containing that CA, which together allow talking to the stacked etcd members.
+// The client certificate is reused until the etcd CA changes or the certificate is about to expire.
+// The etcd CA of an external etcd cluster comes without its key, in which case the user supplied
+// API server etcd client certificate is used instead.
+func (m *ManagementCluster) etcdClientCredentials(clusterKey types.NamespacedName) (tls.Certificate, *x509.CertPool, error) {
+	etcdCASecret, err := secret.GetFromNamespacedName(m.Client, clusterKey, secret.EtcdCA)
+	if err != nil {
+		return tls.Certificate{}, nil, errors.Wrapf(err, "failed to retrieve etcd CA secret for Cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
+	}
+	crtData, ok := etcdCASecret.Data[secret.TLSCrtDataName]
+	if !ok {
+		return tls.Certificate{}, nil, errors.Errorf("etcd CA secret for Cluster %s/%s is missing %q", clusterKey.Namespace, clusterKey.Name, secret.TLSCrtDataName)
+	}
+
+	caCert, err := certs.DecodeCertPEM(crtData)
+	if err != nil {
+		return tls.Certificate{}, nil, errors.Wrap(err, "failed to decode etcd CA certificate")
+	}
+	if caCert == nil {
+		return tls.Certificate{}, nil, errors.New("etcd CA secret does not contain a certificate")
+	}
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+
+	keyData := etcdCASecret.Data[secret.TLSKeyDataName]
+	if len(keyData) == 0 {
+		keyPair, err := m.externalEtcdClientCertificate(clusterKey)
+		if err != nil {
+			return tls.Certificate{}, nil, err
+		}
+		return keyPair, caPool, nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if cached, ok := m.etcdClientCertificates[clusterKey]; ok &&
+		bytes.Equal(cached.caData, crtData) && time.Until(cached.notAfter) > etcdClientCertificateRenewBefore {
+		return cached.keyPair, caPool, nil
+	}
+
+	caKey, err := certs.DecodePrivateKeyPEM(keyData)
+	if err != nil {
+		return tls.Certificate{}, nil, errors.Wrap(err, "failed to decode etcd CA private key")
+	}
+	if caKey == nil {
+		return tls.Certificate{}, nil, errors.New("etcd CA secret does not contain a private key")
+	}
+
+	clientKey, err := certs.NewPrivateKey()
+	if err != nil {
+		return tls.Certificate{}, nil, errors.Wrap(err, "failed to create etcd client key")
+	}
+	cfg := &certs.Config{
+		CommonName: "cluster-api.x-k8s.io",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientCert, err := cfg.NewSignedCert(clientKey, caCert, caKey)
+	if err != nil {
+		return tls.Certificate{}, nil, errors.Wrap(err, "failed to sign etcd client certificate")
+	}
+
+	keyPair, err := tls.X509KeyPair(certs.EncodeCertPEM(clientCert), certs.EncodePrivateKeyPEM(clientKey))
+	if err != nil {
+		return tls.Certificate{}, nil, errors.Wrap(err, "failed to build etcd client key pair")
+	}
+
+	if m.etcdClientCertificates == nil {
+		m.etcdClientCertificates = map[types.NamespacedName]etcdClientCertificate{}
+	}
+	m.etcdClientCertificates[clusterKey] = etcdClientCertificate{
+		caData:   crtData,
+		keyPair:  keyPair,
+		notAfter: clientCert.NotAfter,
+	}
+	return keyPair, caPool, nil
+}
+
+// externalEtcdClientCertificate returns the user supplied certificate the API servers of the given cluster use
+// to connect to an external etcd cluster.
+func (m *ManagementCluster) externalEtcdClientCertificate(clusterKey types.NamespacedName) (tls.Certificate, error) {
+	clientSecret, err := secret.GetFromNamespacedName(m.Client, clusterKey, secret.APIServerEtcdClient)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "failed to retrieve the API server etcd client secret for Cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
+	}
+	keyPair, err := tls.X509KeyPair(clientSecret.Data[secret.TLSCrtDataName], clientSecret.Data[secret.TLSKeyDataName])
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "failed to load the API server etcd client key pair for Cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
+	}
+	return keyPair, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestEtcdClientCredentials(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	clusterKey := types.NamespacedName{Namespace: "test", Name: "foo"}
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(gomega.Succeed())
+	etcdCA := certificates.GetByPurpose(secret.EtcdCA).AsSecret(clusterKey, metav1.OwnerReference{})
+
+	m := &ManagementCluster{Client: fake.NewFakeClientWithScheme(scheme.Scheme, etcdCA)}
+	clientCert, caPool, err := m.etcdClientCredentials(clusterKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(caPool).NotTo(gomega.BeNil())
+
+	// The client certificate is signed by the etcd CA.
+	caCert, err := certs.DecodeCertPEM(etcdCA.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	leaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(leaf.CheckSignatureFrom(caCert)).To(gomega.Succeed())
+}
+
+func TestEtcdClientCredentialsAreCached(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	clusterKey := types.NamespacedName{Namespace: "test", Name: "foo"}
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(gomega.Succeed())
+	etcdCA := certificates.GetByPurpose(secret.EtcdCA).AsSecret(clusterKey, metav1.OwnerReference{})
+
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, etcdCA)
+	m := &ManagementCluster{Client: fakeClient}
+	first, _, err := m.etcdClientCredentials(clusterKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The same certificate is returned while the etcd CA doesn't change.
+	second, _, err := m.etcdClientCredentials(clusterKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(second.Certificate).To(gomega.Equal(first.Certificate))
+
+	// A new certificate is signed once the etcd CA is replaced.
+	newCertificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(newCertificates.Generate()).To(gomega.Succeed())
+	newEtcdCA := newCertificates.GetByPurpose(secret.EtcdCA).AsSecret(clusterKey, metav1.OwnerReference{})
+	g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: etcdCA.Namespace, Name: etcdCA.Name}, etcdCA)).To(gomega.Succeed())
+	etcdCA.Data = newEtcdCA.Data
+	g.Expect(fakeClient.Update(context.Background(), etcdCA)).To(gomega.Succeed())
+
+	third, _, err := m.etcdClientCredentials(clusterKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(third.Certificate).NotTo(gomega.Equal(first.Certificate))
+	caCert, err := certs.DecodeCertPEM(newEtcdCA.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	leaf, err := x509.ParseCertificate(third.Certificate[0])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(leaf.CheckSignatureFrom(caCert)).To(gomega.Succeed())
+}
+
+func TestEtcdClientCredentialsExternalEtcd(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	clusterKey := types.NamespacedName{Namespace: "test", Name: "foo"}
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(gomega.Succeed())
+	etcdCAKeyPair := certificates.GetByPurpose(secret.EtcdCA).KeyPair
+
+	// The external etcd CA is supplied without its key, along with the API server etcd client certificate.
+	caCert, err := certs.DecodeCertPEM(etcdCAKeyPair.Cert)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	caKey, err := certs.DecodePrivateKeyPEM(etcdCAKeyPair.Key)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	clientKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	cfg := &certs.Config{
+		CommonName: "kube-apiserver-etcd-client",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientCert, err := cfg.NewSignedCert(clientKey, caCert, caKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	externalCertificates := secret.Certificates{
+		&secret.Certificate{Purpose: secret.EtcdCA, KeyPair: &certs.KeyPair{Cert: etcdCAKeyPair.Cert}},
+		&secret.Certificate{Purpose: secret.APIServerEtcdClient, KeyPair: &certs.KeyPair{
+			Cert: certs.EncodeCertPEM(clientCert),
+			Key:  certs.EncodePrivateKeyPEM(clientKey),
+		}},
+	}
+	m := &ManagementCluster{Client: fake.NewFakeClientWithScheme(scheme.Scheme,
+		externalCertificates[0].AsSecret(clusterKey, metav1.OwnerReference{}),
+		externalCertificates[1].AsSecret(clusterKey, metav1.OwnerReference{}),
+	)}
+
+	keyPair, caPool, err := m.etcdClientCredentials(clusterKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(caPool).NotTo(gomega.BeNil())
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(leaf.Subject.CommonName).To(gomega.Equal("kube-apiserver-etcd-client"))
+}
+
+func TestEtcdClientCredentialsExternalEtcdMissingClientCertificate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	clusterKey := types.NamespacedName{Namespace: "test", Name: "foo"}
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(gomega.Succeed())
+	etcdCA := &secret.Certificate{Purpose: secret.EtcdCA, KeyPair: &certs.KeyPair{Cert: certificates.GetByPurpose(secret.EtcdCA).KeyPair.Cert}}
+
+	m := &ManagementCluster{Client: fake.NewFakeClientWithScheme(scheme.Scheme, etcdCA.AsSecret(clusterKey, metav1.OwnerReference{}))}
+	_, _, err := m.etcdClientCredentials(clusterKey)
+	g.Expect(err).To(gomega.HaveOccurred())
+}
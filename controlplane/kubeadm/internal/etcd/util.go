@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+// MemberForName returns the etcd member with the matching name.
+func MemberForName(members []*Member, name string) *Member {
+	for _, m := range members {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// MemberIDSet returns a set of member IDs.
+func MemberIDSet(members []*Member) UInt64Set {
+	set := UInt64Set{}
+	for _, m := range members {
+		set.Insert(m.ID)
+	}
+	return set
+}
+
+// UInt64Set is a set of uint64 values.
+type UInt64Set map[uint64]struct{}
+
+// Insert adds items to the set.
+func (s UInt64Set) Insert(items ...uint64) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+// Has returns true if and only if item is contained in the set.
+func (s UInt64Set) Has(item uint64) bool {
+	_, contained := s[item]
+	return contained
+}
+
+// Difference returns a set of objects that are not in s2.
+func (s UInt64Set) Difference(s2 UInt64Set) UInt64Set {
+	result := UInt64Set{}
+	for key := range s {
+		if !s2.Has(key) {
+			result.Insert(key)
+		}
+	}
+	return result
+}
+
+// UnsortedList returns the slice with contents in random order.
+func (s UInt64Set) UnsortedList() []uint64 {
+	res := make([]uint64, 0, len(s))
+	for key := range s {
+		res = append(res, key)
+	}
+	return res
+}
+
+// Len returns the size of the set.
+func (s UInt64Set) Len() int {
+	return len(s)
+}
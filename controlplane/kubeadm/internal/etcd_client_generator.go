@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd"
+	"sigs.k8s.io/cluster-api/util/proxy"
+)
+
+// etcdClientGenerator generates etcd clients that connect to specific etcd members on particular control plane nodes.
+type etcdClientGenerator struct {
+	restConfig *rest.Config
+	tlsConfig  *tls.Config
+}
+
+// forNode returns a client that talks to the etcd member running on the given node.
+func (c *etcdClientGenerator) forNode(ctx context.Context, name string) (*etcd.Client, error) {
+	// This does not support external etcd.
+	p := proxy.Proxy{
+		Kind:         proxy.KindPods,
+		Namespace:    metaNamespaceSystem,
+		ResourceName: staticPodName("etcd", name),
+		KubeConfig:   c.restConfig,
+		TLSConfig:    c.tlsConfig,
+		Port:         2379,
+	}
+	dialer, err := proxy.NewDialer(p)
+	if err != nil {
+		return nil, err
+	}
+	etcdclient, err := etcd.NewEtcdClient("127.0.0.1", dialer.DialContextWithAddr, c.tlsConfig)
+	if err != nil {
+		_ = dialer.Close()
+		return nil, err
+	}
+	proxiedClient := &proxiedEtcdClient{Client: etcdclient, dialer: dialer}
+	customClient, err := etcd.NewClientWithEtcd(ctx, proxiedClient)
+	if err != nil {
+		_ = proxiedClient.Close()
+		return nil, errors.Wrapf(err, "failed to create etcd client for node %q", name)
+	}
+	return customClient, nil
+}
+
+// proxiedEtcdClient is an etcd client connected through a proxy Dialer, which is closed together with the client.
+type proxiedEtcdClient struct {
+	*clientv3.Client
+	dialer *proxy.Dialer
+}
+
+// Close closes the etcd client and then the port-forwarded connection its connections were multiplexed on.
+func (c *proxiedEtcdClient) Close() error {
+	err := c.Client.Close()
+	if dialerErr := c.dialer.Close(); err == nil {
+		err = dialerErr
+	}
+	return err
+}
+
+// forEndpoint returns a client that talks to the member of an external etcd cluster listening on the given endpoint.
+func (c *etcdClientGenerator) forEndpoint(ctx context.Context, endpoint string) (*etcd.Client, error) {
+	dialer := &net.Dialer{}
+	dial := func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	etcdclient, err := etcd.NewEtcdClient(endpoint, dial, c.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	customClient, err := etcd.NewClientWithEtcd(ctx, etcdclient)
+	if err != nil {
+		_ = etcdclient.Close()
+		return nil, errors.Wrapf(err, "failed to create etcd client for endpoint %q", endpoint)
+	}
+	return customClient, nil
+}
+
+// staticPodName returns the name kubeadm gives to a static control plane pod running on the given node.
+func staticPodName(component, nodeName string) string {
+	return fmt.Sprintf("%s-%s", component, nodeName)
+}
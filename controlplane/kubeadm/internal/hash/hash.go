@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hash
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+)
+
+// machineSpec holds the parts of a KubeadmControlPlaneSpec which are used to generate control plane Machines.
+// Only these are hashed, so that adding fields to the KubeadmControlPlaneSpec doesn't change the hash of the
+// existing Machines and trigger a rollout.
+type machineSpec struct {
+	Version                string
+	InfrastructureTemplate corev1.ObjectReference
+	KubeadmConfigSpec      bootstrapv1.KubeadmConfigSpec
+}
+
+// Compute stably hashes the parts of a KubeadmControlPlaneSpec which are used to generate control plane Machines:
+// the version, the infrastructure template and the kubeadm configuration. The DNS add-on image, which is updated
+// in place, is ignored.
+func Compute(spec *controlplanev1.KubeadmControlPlaneSpec) string {
+	toHash := machineSpec{
+		Version:                spec.Version,
+		InfrastructureTemplate: spec.InfrastructureTemplate,
+		KubeadmConfigSpec:      *spec.KubeadmConfigSpec.DeepCopy(),
+	}
+	if toHash.KubeadmConfigSpec.ClusterConfiguration != nil {
+		toHash.KubeadmConfigSpec.ClusterConfiguration.DNS.ImageMeta = kubeadmv1.ImageMeta{}
+	}
+
+	hasher := fnv.New32a()
+	mdutil.DeepHashObject(hasher, toHash)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
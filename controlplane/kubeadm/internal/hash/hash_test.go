@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+)
+
+func TestCompute(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	spec := &controlplanev1.KubeadmControlPlaneSpec{
+		Replicas: pointer.Int32Ptr(3),
+		Version:  "v1.17.0",
+		InfrastructureTemplate: corev1.ObjectReference{
+			Kind: "GenericMachineTemplate",
+			Name: "infra-foo",
+		},
+		KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+			ClusterConfiguration: &kubeadmv1.ClusterConfiguration{},
+		},
+	}
+	original := Compute(spec)
+	g.Expect(original).NotTo(gomega.BeEmpty())
+	g.Expect(Compute(spec.DeepCopy())).To(gomega.Equal(original))
+
+	scaled := spec.DeepCopy()
+	scaled.Replicas = pointer.Int32Ptr(5)
+	g.Expect(Compute(scaled)).To(gomega.Equal(original))
+
+	newRolloutBefore := spec.DeepCopy()
+	newRolloutBefore.RolloutBefore = &controlplanev1.RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(30)}
+	g.Expect(Compute(newRolloutBefore)).To(gomega.Equal(original))
+
+	newRolloutControls := spec.DeepCopy()
+	maxSurge := intstr.FromInt(0)
+	newRolloutControls.Paused = true
+	newRolloutControls.UpgradeAfter = &metav1.Time{Time: time.Now()}
+	newRolloutControls.RolloutStrategy = &controlplanev1.RolloutStrategy{
+		Type:          controlplanev1.RollingUpdateStrategyType,
+		RollingUpdate: &controlplanev1.RollingUpdate{MaxSurge: &maxSurge},
+	}
+	g.Expect(Compute(newRolloutControls)).To(gomega.Equal(original))
+
+	newDNSImage := spec.DeepCopy()
+	newDNSImage.KubeadmConfigSpec.ClusterConfiguration.DNS.ImageTag = "1.6.5"
+	g.Expect(Compute(newDNSImage)).To(gomega.Equal(original))
+
+	newVersion := spec.DeepCopy()
+	newVersion.Version = "v1.17.1"
+	g.Expect(Compute(newVersion)).NotTo(gomega.Equal(original))
+
+	newTemplate := spec.DeepCopy()
+	newTemplate.InfrastructureTemplate.Name = "infra-bar"
+	g.Expect(Compute(newTemplate)).NotTo(gomega.Equal(original))
+
+	newExtraArgs := spec.DeepCopy()
+	newExtraArgs.KubeadmConfigSpec.ClusterConfiguration.APIServer.ExtraArgs = map[string]string{"audit-log-maxage": "30"}
+	g.Expect(Compute(newExtraArgs)).NotTo(gomega.Equal(original))
+}
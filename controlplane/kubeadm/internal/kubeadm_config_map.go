@@ -0,0 +1,195 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+)
+
+const (
+	kubeadmConfigKey        = "kubeadm-config"
+	clusterStatusKey        = "ClusterStatus"
+	clusterConfigurationKey = "ClusterConfiguration"
+	apiEndpointsKey         = "apiEndpoints"
+	kubernetesVersionKey    = "kubernetesVersion"
+	metaNamespaceSystem     = "kube-system"
+)
+
+// kubeadmConfig wraps up interactions necessary for modifying the kubeadm config during an upgrade.
+type kubeadmConfig struct {
+	ConfigMap *corev1.ConfigMap
+}
+
+// RemoveAPIEndpoint removes an APIEndpoint from the kubeadm config cluster status config map.
+func (k *kubeadmConfig) RemoveAPIEndpoint(endpoint string) error {
+	data, ok := k.ConfigMap.Data[clusterStatusKey]
+	if !ok {
+		return errors.Errorf("unable to find %q key in kubeadm ConfigMap", clusterStatusKey)
+	}
+	status, err := yamlToUnstructured([]byte(data))
+	if err != nil {
+		return errors.Wrapf(err, "unable to convert %q key to unstructured", clusterStatusKey)
+	}
+	endpoints, _, err := unstructured.NestedMap(status.UnstructuredContent(), apiEndpointsKey)
+	if err != nil {
+		return errors.Wrapf(err, "unable to extract %q from kubeadm ConfigMap's %q", apiEndpointsKey, clusterStatusKey)
+	}
+	delete(endpoints, endpoint)
+	if err := unstructured.SetNestedMap(status.UnstructuredContent(), endpoints, apiEndpointsKey); err != nil {
+		return errors.Wrapf(err, "unable to update %q on kubeadm ConfigMap's %q", apiEndpointsKey, clusterStatusKey)
+	}
+	updated, err := yaml.Marshal(status)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode kubeadm ConfigMap's %q to YAML", clusterStatusKey)
+	}
+	k.ConfigMap.Data[clusterStatusKey] = string(updated)
+	return nil
+}
+
+// UpdateKubernetesVersion sets the kubernetesVersion in the kubeadm config cluster configuration.
+func (k *kubeadmConfig) UpdateKubernetesVersion(version string) error {
+	data, ok := k.ConfigMap.Data[clusterConfigurationKey]
+	if !ok {
+		return errors.Errorf("unable to find %q key in kubeadm ConfigMap", clusterConfigurationKey)
+	}
+	configuration, err := yamlToUnstructured([]byte(data))
+	if err != nil {
+		return errors.Wrapf(err, "unable to convert %q key to unstructured", clusterConfigurationKey)
+	}
+	if err := unstructured.SetNestedField(configuration.UnstructuredContent(), version, kubernetesVersionKey); err != nil {
+		return errors.Wrapf(err, "unable to update %q on kubeadm ConfigMap's %q", kubernetesVersionKey, clusterConfigurationKey)
+	}
+	updated, err := yaml.Marshal(configuration)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode kubeadm ConfigMap's %q to YAML", clusterConfigurationKey)
+	}
+	k.ConfigMap.Data[clusterConfigurationKey] = string(updated)
+	return nil
+}
+
+// UpdateClusterConfiguration copies the fields of the desired cluster configuration which can be changed on
+// a running cluster into the kubeadm config cluster configuration, so that they are used by the joining
+// control plane Machines.
+func (k *kubeadmConfig) UpdateClusterConfiguration(desired *kubeadmv1.ClusterConfiguration) error {
+	data, ok := k.ConfigMap.Data[clusterConfigurationKey]
+	if !ok {
+		return errors.Errorf("unable to find %q key in kubeadm ConfigMap", clusterConfigurationKey)
+	}
+	configuration, err := yamlToUnstructured([]byte(data))
+	if err != nil {
+		return errors.Wrapf(err, "unable to convert %q key to unstructured", clusterConfigurationKey)
+	}
+	content := configuration.UnstructuredContent()
+
+	if err := setNestedObject(content, &desired.APIServer, "apiServer"); err != nil {
+		return err
+	}
+	if err := setNestedObject(content, &desired.ControllerManager, "controllerManager"); err != nil {
+		return err
+	}
+	if err := setNestedObject(content, &desired.Scheduler, "scheduler"); err != nil {
+		return err
+	}
+	if err := setNestedString(content, desired.ImageRepository, "imageRepository"); err != nil {
+		return err
+	}
+	if _, found, _ := unstructured.NestedMap(content, "etcd", "local"); found && desired.Etcd.Local != nil {
+		if err := setNestedString(content, desired.Etcd.Local.ImageRepository, "etcd", "local", "imageRepository"); err != nil {
+			return err
+		}
+		if err := setNestedString(content, desired.Etcd.Local.ImageTag, "etcd", "local", "imageTag"); err != nil {
+			return err
+		}
+	}
+
+	updated, err := yaml.Marshal(configuration)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode kubeadm ConfigMap's %q to YAML", clusterConfigurationKey)
+	}
+	k.ConfigMap.Data[clusterConfigurationKey] = string(updated)
+	return nil
+}
+
+// UpdateDNSImageInfo sets the DNS add-on image repository and tag in the kubeadm config cluster configuration.
+func (k *kubeadmConfig) UpdateDNSImageInfo(dns *kubeadmv1.DNS) error {
+	data, ok := k.ConfigMap.Data[clusterConfigurationKey]
+	if !ok {
+		return errors.Errorf("unable to find %q key in kubeadm ConfigMap", clusterConfigurationKey)
+	}
+	configuration, err := yamlToUnstructured([]byte(data))
+	if err != nil {
+		return errors.Wrapf(err, "unable to convert %q key to unstructured", clusterConfigurationKey)
+	}
+	content := configuration.UnstructuredContent()
+
+	if err := setNestedString(content, dns.ImageRepository, "dns", "imageRepository"); err != nil {
+		return err
+	}
+	if err := setNestedString(content, dns.ImageTag, "dns", "imageTag"); err != nil {
+		return err
+	}
+
+	updated, err := yaml.Marshal(configuration)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode kubeadm ConfigMap's %q to YAML", clusterConfigurationKey)
+	}
+	k.ConfigMap.Data[clusterConfigurationKey] = string(updated)
+	return nil
+}
+
+// setNestedObject sets the unstructured representation of the given object at the given path,
+// or removes the path if the object is empty.
+func setNestedObject(content map[string]interface{}, obj interface{}, fields ...string) error {
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return errors.Wrapf(err, "unable to convert %q to unstructured", strings.Join(fields, "."))
+	}
+	if len(value) == 0 {
+		unstructured.RemoveNestedField(content, fields...)
+		return nil
+	}
+	if err := unstructured.SetNestedField(content, value, fields...); err != nil {
+		return errors.Wrapf(err, "unable to update %q on kubeadm ConfigMap's %q", strings.Join(fields, "."), clusterConfigurationKey)
+	}
+	return nil
+}
+
+// setNestedString sets the given string at the given path, or removes the path if the string is empty.
+func setNestedString(content map[string]interface{}, value string, fields ...string) error {
+	if value == "" {
+		unstructured.RemoveNestedField(content, fields...)
+		return nil
+	}
+	if err := unstructured.SetNestedField(content, value, fields...); err != nil {
+		return errors.Wrapf(err, "unable to update %q on kubeadm ConfigMap's %q", strings.Join(fields, "."), clusterConfigurationKey)
+	}
+	return nil
+}
+
+func yamlToUnstructured(rawYAML []byte) (*unstructured.Unstructured, error) {
+	unst := &unstructured.Unstructured{}
+	err := yaml.Unmarshal(rawYAML, &unst.Object)
+	return unst, err
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+)
+
+func TestRemoveAPIEndpoint(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kc := kubeadmConfig{
+		ConfigMap: &corev1.ConfigMap{
+			Data: map[string]string{
+				clusterStatusKey: `apiEndpoints:
+  ip-10-0-0-1.ec2.internal:
+    advertiseAddress: 10.0.0.1
+    bindPort: 6443
+  ip-10-0-0-2.ec2.internal:
+    advertiseAddress: 10.0.0.2
+    bindPort: 6443
+apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterStatus
+`,
+			},
+		},
+	}
+	g.Expect(kc.RemoveAPIEndpoint("ip-10-0-0-1.ec2.internal")).To(gomega.Succeed())
+
+	status := map[string]interface{}{}
+	g.Expect(yaml.Unmarshal([]byte(kc.ConfigMap.Data[clusterStatusKey]), &status)).To(gomega.Succeed())
+	g.Expect(status[apiEndpointsKey]).To(gomega.HaveLen(1))
+	g.Expect(status[apiEndpointsKey]).To(gomega.HaveKey("ip-10-0-0-2.ec2.internal"))
+	g.Expect(status).To(gomega.HaveKeyWithValue("kind", "ClusterStatus"))
+}
+
+func TestRemoveAPIEndpointMissingClusterStatus(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kc := kubeadmConfig{
+		ConfigMap: &corev1.ConfigMap{
+			Data: map[string]string{},
+		},
+	}
+	g.Expect(kc.RemoveAPIEndpoint("ip-10-0-0-1.ec2.internal")).NotTo(gomega.Succeed())
+}
+
+func TestUpdateKubernetesVersion(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kc := kubeadmConfig{
+		ConfigMap: &corev1.ConfigMap{
+			Data: map[string]string{
+				clusterConfigurationKey: `apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+kubernetesVersion: v1.16.1
+`,
+			},
+		},
+	}
+	g.Expect(kc.UpdateKubernetesVersion("v1.17.2")).To(gomega.Succeed())
+
+	configuration := map[string]interface{}{}
+	g.Expect(yaml.Unmarshal([]byte(kc.ConfigMap.Data[clusterConfigurationKey]), &configuration)).To(gomega.Succeed())
+	g.Expect(configuration).To(gomega.HaveKeyWithValue(kubernetesVersionKey, "v1.17.2"))
+	g.Expect(configuration).To(gomega.HaveKeyWithValue("kind", "ClusterConfiguration"))
+}
+
+func TestUpdateKubernetesVersionMissingClusterConfiguration(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kc := kubeadmConfig{
+		ConfigMap: &corev1.ConfigMap{
+			Data: map[string]string{},
+		},
+	}
+	g.Expect(kc.UpdateKubernetesVersion("v1.17.2")).NotTo(gomega.Succeed())
+}
+
+func TestUpdateClusterConfiguration(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kc := kubeadmConfig{
+		ConfigMap: &corev1.ConfigMap{
+			Data: map[string]string{
+				clusterConfigurationKey: `apiServer:
+  extraArgs:
+    authorization-mode: Node,RBAC
+apiVersion: kubeadm.k8s.io/v1beta2
+controllerManager:
+  extraArgs:
+    cloud-provider: aws
+etcd:
+  local:
+    dataDir: /var/lib/etcd
+kind: ClusterConfiguration
+kubernetesVersion: v1.16.1
+networking:
+  podSubnet: 192.168.0.0/16
+`,
+			},
+		},
+	}
+	desired := &kubeadmv1.ClusterConfiguration{
+		APIServer: kubeadmv1.APIServer{
+			ControlPlaneComponent: kubeadmv1.ControlPlaneComponent{
+				ExtraArgs: map[string]string{"audit-log-maxage": "30"},
+			},
+			CertSANs: []string{"foo.example.com"},
+		},
+		ImageRepository: "registry.example.com",
+		Etcd: kubeadmv1.Etcd{
+			Local: &kubeadmv1.LocalEtcd{
+				ImageMeta: kubeadmv1.ImageMeta{ImageTag: "3.4.3-0"},
+			},
+		},
+	}
+	g.Expect(kc.UpdateClusterConfiguration(desired)).To(gomega.Succeed())
+
+	updated := &kubeadmv1.ClusterConfiguration{}
+	g.Expect(yaml.Unmarshal([]byte(kc.ConfigMap.Data[clusterConfigurationKey]), updated)).To(gomega.Succeed())
+	g.Expect(updated.APIServer).To(gomega.Equal(desired.APIServer))
+	g.Expect(updated.ControllerManager.ExtraArgs).To(gomega.BeEmpty())
+	g.Expect(updated.ImageRepository).To(gomega.Equal("registry.example.com"))
+	g.Expect(updated.Etcd.Local.ImageTag).To(gomega.Equal("3.4.3-0"))
+	// Fields which can't be changed on a running cluster are preserved.
+	g.Expect(updated.Etcd.Local.DataDir).To(gomega.Equal("/var/lib/etcd"))
+	g.Expect(updated.Networking.PodSubnet).To(gomega.Equal("192.168.0.0/16"))
+	g.Expect(updated.KubernetesVersion).To(gomega.Equal("v1.16.1"))
+}
+
+func TestUpdateDNSImageInfo(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kc := kubeadmConfig{
+		ConfigMap: &corev1.ConfigMap{
+			Data: map[string]string{
+				clusterConfigurationKey: `apiVersion: kubeadm.k8s.io/v1beta2
+dns:
+  imageRepository: k8s.gcr.io
+  type: CoreDNS
+kind: ClusterConfiguration
+kubernetesVersion: v1.16.1
+`,
+			},
+		},
+	}
+	g.Expect(kc.UpdateDNSImageInfo(&kubeadmv1.DNS{ImageMeta: kubeadmv1.ImageMeta{ImageTag: "1.6.5"}})).To(gomega.Succeed())
+
+	updated := &kubeadmv1.ClusterConfiguration{}
+	g.Expect(yaml.Unmarshal([]byte(kc.ConfigMap.Data[clusterConfigurationKey]), updated)).To(gomega.Succeed())
+	g.Expect(updated.DNS.Type).To(gomega.Equal(kubeadmv1.CoreDNS))
+	g.Expect(updated.DNS.ImageRepository).To(gomega.BeEmpty())
+	g.Expect(updated.DNS.ImageTag).To(gomega.Equal("1.6.5"))
+	g.Expect(updated.KubernetesVersion).To(gomega.Equal("v1.16.1"))
+}
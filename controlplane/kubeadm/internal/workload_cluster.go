@@ -0,0 +1,661 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/rest"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+const (
+	labelNodeRoleMaster         = "node-role.kubernetes.io/master"
+	kubeletConfigKey            = "kubelet"
+	nodesGroup                  = "system:nodes"
+	nodeBootstrapTokenAuthGroup = "system:bootstrappers:kubeadm:default-node-token"
+)
+
+var (
+	// ErrControlPlaneMinNodes signals that a cluster doesn't meet the minimum required nodes
+	// to remove an etcd member.
+	ErrControlPlaneMinNodes = errors.New("cluster has fewer than 2 control plane nodes; removing an etcd member is not supported")
+)
+
+// WorkloadCluster defines all behaviors necessary for the control plane controller
+// to interact with a workload cluster.
+type WorkloadCluster interface {
+	// Health checks
+	ControlPlaneIsHealthy(ctx context.Context) (HealthCheckResult, error)
+	EtcdIsHealthy(ctx context.Context) (HealthCheckResult, error)
+	ExternalEtcdIsHealthy(ctx context.Context, endpoints []string) error
+
+	// Etcd tasks
+	CanSafelyRemoveEtcdMember(ctx context.Context, machine *clusterv1.Machine) (bool, error)
+	ForwardEtcdLeadership(ctx context.Context, machine *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error
+	RemoveEtcdMemberForMachine(ctx context.Context, machine *clusterv1.Machine) error
+
+	// Upgrade related tasks
+	ReconcileKubeletRBACRole(ctx context.Context, version *version.Version) error
+	ReconcileKubeletRBACBinding(ctx context.Context, version *version.Version) error
+	UpdateKubernetesVersionInKubeadmConfigMap(ctx context.Context, version *version.Version) error
+	UpdateKubeletConfigMap(ctx context.Context, version *version.Version) error
+	UpdateClusterConfigurationInKubeadmConfigMap(ctx context.Context, clusterConfiguration *kubeadmv1.ClusterConfiguration) error
+
+	// Add-on tasks
+	UpdateKubeProxyImageInfo(ctx context.Context, version *version.Version, imageRepository string) error
+	UpdateCoreDNS(ctx context.Context, clusterConfiguration *kubeadmv1.ClusterConfiguration) error
+
+	// kubeadm-config tasks
+	RemoveMachineFromKubeadmConfigMap(ctx context.Context, machine *clusterv1.Machine) error
+
+	// Certificate tasks
+	GetAPIServerCertificateExpiry(ctx context.Context, nodeName string) (*time.Time, error)
+}
+
+// HealthCheckResult maps nodes that are checked to any errors the node has related to the check.
+type HealthCheckResult map[string]error
+
+// CompareMachines returns an error if any of the checked nodes reported an error, if a checked node
+// doesn't belong to any of the given Machines or if the node of one of the Machines wasn't checked.
+func (h HealthCheckResult) CompareMachines(machines []*clusterv1.Machine) error {
+	var errs []error
+
+	machineNodes := make(map[string]bool, len(machines))
+	for _, m := range machines {
+		if m.Status.NodeRef == nil {
+			errs = append(errs, errors.Errorf("control plane Machine %q does not have a Node yet", m.Name))
+			continue
+		}
+		machineNodes[m.Status.NodeRef.Name] = true
+		if _, ok := h[m.Status.NodeRef.Name]; !ok {
+			errs = append(errs, errors.Errorf("node %q of control plane Machine %q was not checked", m.Status.NodeRef.Name, m.Name))
+		}
+	}
+
+	for nodeName, err := range h {
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "node %q", nodeName))
+		}
+		if !machineNodes[nodeName] {
+			errs = append(errs, errors.Errorf("node %q does not belong to any control plane Machine", nodeName))
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+// Workload defines operations on workload clusters.
+type Workload struct {
+	Client              ctrlclient.Client
+	restConfig          *rest.Config
+	etcdClientGenerator *etcdClientGenerator
+}
+
+func (w *Workload) getControlPlaneNodes(ctx context.Context) (*corev1.NodeList, error) {
+	nodes := &corev1.NodeList{}
+	labels := map[string]string{
+		labelNodeRoleMaster: "",
+	}
+
+	if err := w.Client.List(ctx, nodes, ctrlclient.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (w *Workload) getConfigMap(ctx context.Context, configMap types.NamespacedName) (*corev1.ConfigMap, error) {
+	original := &corev1.ConfigMap{}
+	if err := w.Client.Get(ctx, configMap, original); err != nil {
+		return nil, errors.Wrapf(err, "error getting %s/%s configmap from target cluster", configMap.Namespace, configMap.Name)
+	}
+	return original.DeepCopy(), nil
+}
+
+// CanSafelyRemoveEtcdMember returns true if the etcd cluster would keep quorum after the member
+// running on the node of the given machine is removed.
+func (w *Workload) CanSafelyRemoveEtcdMember(ctx context.Context, machine *clusterv1.Machine) (bool, error) {
+	if machine == nil || machine.Status.NodeRef == nil {
+		// Nothing to remove, quorum is not affected.
+		return true, nil
+	}
+	nodeName := machine.Status.NodeRef.Name
+
+	controlPlaneNodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list control plane nodes")
+	}
+
+	var members []*etcd.Member
+	healthyMembers := 0
+	for _, node := range controlPlaneNodes.Items {
+		if node.Name == nodeName {
+			continue
+		}
+
+		etcdClient, err := w.etcdClientGenerator.forNode(ctx, node.Name)
+		if err != nil {
+			// An unreachable member can not be counted toward quorum.
+			continue
+		}
+		nodeMembers, err := etcdClient.Members(ctx)
+		_ = etcdClient.Close()
+		if err != nil {
+			continue
+		}
+		if member := etcd.MemberForName(nodeMembers, node.Name); member != nil && len(member.Alarms) == 0 {
+			healthyMembers++
+		}
+		members = nodeMembers
+	}
+	if members == nil {
+		// Without another reachable member, e.g. with a single replica, removing this one could leave no etcd
+		// cluster at all.
+		return false, nil
+	}
+
+	// Compute the quorum of the etcd cluster once the member is gone.
+	remainingMembers := len(members)
+	if etcd.MemberForName(members, nodeName) != nil {
+		remainingMembers--
+	}
+	quorum := remainingMembers/2 + 1
+
+	return healthyMembers >= quorum, nil
+}
+
+// RemoveEtcdMemberForMachine removes the etcd member from the target cluster's etcd cluster.
+func (w *Workload) RemoveEtcdMemberForMachine(ctx context.Context, machine *clusterv1.Machine) error {
+	if machine == nil || machine.Status.NodeRef == nil {
+		// Nothing to do, no node for Machine
+		return nil
+	}
+
+	// Pick a different node to talk to etcd
+	controlPlaneNodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(controlPlaneNodes.Items) < 2 {
+		return ErrControlPlaneMinNodes
+	}
+	anotherNode := firstNodeNotMatchingName(machine.Status.NodeRef.Name, controlPlaneNodes.Items)
+	if anotherNode == nil {
+		return errors.Errorf("failed to find a control plane node whose name is not %s", machine.Status.NodeRef.Name)
+	}
+	etcdClient, err := w.etcdClientGenerator.forNode(ctx, anotherNode.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to create etcd client")
+	}
+	defer etcdClient.Close()
+
+	// List etcd members. This checks that the member is healthy, because the request goes through consensus.
+	members, err := etcdClient.Members(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list etcd members using etcd client")
+	}
+	member := etcd.MemberForName(members, machine.Status.NodeRef.Name)
+
+	// The member has already been removed, return immediately
+	if member == nil {
+		return nil
+	}
+
+	if err := etcdClient.RemoveMember(ctx, member.ID); err != nil {
+		return errors.Wrap(err, "failed to remove member from etcd")
+	}
+
+	return nil
+}
+
+// ForwardEtcdLeadership forwards etcd leadership to the first follower.
+func (w *Workload) ForwardEtcdLeadership(ctx context.Context, machine *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error {
+	if machine == nil || machine.Status.NodeRef == nil {
+		// Nothing to do, no node for Machine
+		return nil
+	}
+
+	// TODO we'd probably prefer to pass in all the known nodes and let grpc handle retrying connections across them
+	etcdClient, err := w.etcdClientGenerator.forNode(ctx, machine.Status.NodeRef.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to create etcd Client")
+	}
+	defer etcdClient.Close()
+
+	members, err := etcdClient.Members(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list etcd members using etcd client")
+	}
+
+	currentMember := etcd.MemberForName(members, machine.Status.NodeRef.Name)
+	if currentMember == nil || currentMember.ID != etcdClient.LeaderID {
+		// The member is not the leader, nothing to forward.
+		return nil
+	}
+
+	// If we don't have a leader candidate, move the leader to the next available machine.
+	if leaderCandidate == nil || leaderCandidate.Status.NodeRef == nil {
+		for _, member := range members {
+			if member.ID != currentMember.ID {
+				if err := etcdClient.MoveLeader(ctx, member.ID); err != nil {
+					return errors.Wrapf(err, "failed to move leader")
+				}
+				break
+			}
+		}
+		return nil
+	}
+
+	// Move the leader to the provided candidate.
+	nextLeader := etcd.MemberForName(members, leaderCandidate.Status.NodeRef.Name)
+	if nextLeader == nil {
+		return errors.Errorf("failed to get etcd member from node %q", leaderCandidate.Status.NodeRef.Name)
+	}
+	if err := etcdClient.MoveLeader(ctx, nextLeader.ID); err != nil {
+		return errors.Wrapf(err, "failed to move leader")
+	}
+	return nil
+}
+
+// RemoveMachineFromKubeadmConfigMap removes the entry for the machine from the kubeadm configmap.
+func (w *Workload) RemoveMachineFromKubeadmConfigMap(ctx context.Context, machine *clusterv1.Machine) error {
+	if machine == nil || machine.Status.NodeRef == nil {
+		// Nothing to do, no node for Machine
+		return nil
+	}
+
+	configMapKey := types.NamespacedName{Name: kubeadmConfigKey, Namespace: metaNamespaceSystem}
+	kubeadmConfigMap, err := w.getConfigMap(ctx, configMapKey)
+	if err != nil {
+		return err
+	}
+	patchHelper, err := patch.NewHelper(kubeadmConfigMap, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for kubeadm ConfigMap")
+	}
+
+	config := &kubeadmConfig{ConfigMap: kubeadmConfigMap}
+	if err := config.RemoveAPIEndpoint(machine.Status.NodeRef.Name); err != nil {
+		return err
+	}
+	if err := patchHelper.Patch(ctx, config.ConfigMap); err != nil {
+		return errors.Wrapf(err, "error updating kubeadm ConfigMap")
+	}
+	return nil
+}
+
+func firstNodeNotMatchingName(name string, nodes []corev1.Node) *corev1.Node {
+	for _, n := range nodes {
+		if n.Name != name {
+			return &n
+		}
+	}
+	return nil
+}
+
+// ControlPlaneIsHealthy checks that the static pods of the control plane components are running and ready
+// on every control plane node. The etcd static pods are covered by EtcdIsHealthy.
+func (w *Workload) ControlPlaneIsHealthy(ctx context.Context) (HealthCheckResult, error) {
+	controlPlaneNodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(HealthCheckResult)
+	for _, node := range controlPlaneNodes.Items {
+		var errs []error
+		for _, component := range []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler"} {
+			pod := &corev1.Pod{}
+			key := ctrlclient.ObjectKey{Namespace: metaNamespaceSystem, Name: staticPodName(component, node.Name)}
+			if err := w.Client.Get(ctx, key, pod); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to get %s static pod", component))
+				continue
+			}
+			if err := checkStaticPodReadyCondition(pod); err != nil {
+				errs = append(errs, errors.Wrapf(err, "%s static pod is not healthy", component))
+			}
+		}
+		response[node.Name] = kerrors.NewAggregate(errs)
+	}
+
+	return response, nil
+}
+
+// checkStaticPodReadyCondition returns an error if the given pod is not running or is not ready.
+func checkStaticPodReadyCondition(pod *corev1.Pod) error {
+	if pod.Status.Phase != corev1.PodRunning {
+		return errors.Errorf("pod %s is in phase %q", pod.Name, pod.Status.Phase)
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			if condition.Status != corev1.ConditionTrue {
+				return errors.Errorf("pod %s is not ready: %s", pod.Name, condition.Message)
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("pod %s does not have a %s condition", pod.Name, corev1.PodReady)
+}
+
+// EtcdIsHealthy runs checks for every etcd member in the cluster to satisfy our definition of healthy.
+// This is a best effort check and nodes can become unhealthy after the check is complete. It is not a guarantee.
+// It's used a signal for if we should allow a target cluster to scale up, scale down or upgrade.
+// It returns a map of nodes checked along with an error for a given node.
+func (w *Workload) EtcdIsHealthy(ctx context.Context) (HealthCheckResult, error) {
+	var knownClusterID uint64
+	var knownLeaderID uint64
+	var knownMemberIDSet etcd.UInt64Set
+
+	controlPlaneNodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(HealthCheckResult)
+	for _, node := range controlPlaneNodes.Items {
+		name := node.Name
+		response[name] = nil
+		if node.Spec.ProviderID == "" {
+			response[name] = errors.New("empty provider ID")
+			continue
+		}
+
+		// Create the etcd Client for the etcd Pod scheduled on the Node
+		etcdClient, err := w.etcdClientGenerator.forNode(ctx, name)
+		if err != nil {
+			response[name] = errors.Wrap(err, "failed to create etcd client")
+			continue
+		}
+
+		// List etcd members. This checks that the member is healthy, because the request goes through consensus.
+		members, err := etcdClient.Members(ctx)
+		leaderID := etcdClient.LeaderID
+		_ = etcdClient.Close()
+		if err != nil {
+			response[name] = errors.Wrap(err, "failed to list etcd members using etcd client")
+			continue
+		}
+		member := etcd.MemberForName(members, name)
+		if member == nil {
+			response[name] = errors.New("etcd member not found in the member list")
+			continue
+		}
+
+		// Check that the member knows the leader, and that it is the same leader known by all other members.
+		if leaderID == 0 {
+			response[name] = errors.New("etcd member does not know the leader")
+			continue
+		}
+		if knownLeaderID == 0 {
+			knownLeaderID = leaderID
+		} else if knownLeaderID != leaderID {
+			response[name] = errors.Errorf("etcd member reports leader %d, but all previously seen etcd members reported leader %d", leaderID, knownLeaderID)
+			continue
+		}
+
+		// Check that the member reports no alarms.
+		if len(member.Alarms) > 0 {
+			response[name] = errors.Errorf("etcd member reports alarms: %v", member.Alarms)
+			continue
+		}
+
+		// Check that the member belongs to the same cluster as all other members.
+		clusterID := member.ClusterID
+		if knownClusterID == 0 {
+			knownClusterID = clusterID
+		} else if knownClusterID != clusterID {
+			response[name] = errors.Errorf("etcd member has cluster ID %d, but all previously seen etcd members have cluster ID %d", clusterID, knownClusterID)
+			continue
+		}
+
+		// Check that the member list is stable.
+		memberIDSet := etcd.MemberIDSet(members)
+		if knownMemberIDSet.Len() == 0 {
+			knownMemberIDSet = memberIDSet
+		} else {
+			unknownMembers := memberIDSet.Difference(knownMemberIDSet)
+			if unknownMembers.Len() > 0 {
+				response[name] = errors.Errorf("etcd member reports members IDs %v, but all previously seen etcd members reported member IDs %v", memberIDSet.UnsortedList(), knownMemberIDSet.UnsortedList())
+			}
+		}
+	}
+
+	// Out-of-band etcd members are detected by comparing the result with the control plane Machines, see CompareMachines.
+
+	// Check that there is exactly one etcd member for every control plane node.
+	// We gain no benefit from having an extra member in the list, and it creates the possibility of
+	// an additional failure to cause quorum loss.
+	if expected := len(controlPlaneNodes.Items); knownMemberIDSet.Len() != expected {
+		return response, errors.Errorf("there are %d control plane nodes, but %d etcd members", expected, knownMemberIDSet.Len())
+	}
+
+	return response, nil
+}
+
+// ExternalEtcdIsHealthy checks that every given endpoint of an external etcd cluster is reachable and reports no
+// alarms, and that all the endpoints agree on the leader and on the members of the etcd cluster.
+// Unlike EtcdIsHealthy, the members are not expected to match the control plane nodes.
+func (w *Workload) ExternalEtcdIsHealthy(ctx context.Context, endpoints []string) error {
+	var knownLeaderID uint64
+	var knownMemberIDSet etcd.UInt64Set
+
+	var errs []error
+	for _, endpoint := range endpoints {
+		etcdClient, err := w.etcdClientGenerator.forEndpoint(ctx, endpoint)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "endpoint %q", endpoint))
+			continue
+		}
+
+		// List etcd members. This checks that the member is healthy, because the request goes through consensus.
+		members, err := etcdClient.Members(ctx)
+		leaderID := etcdClient.LeaderID
+		_ = etcdClient.Close()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "endpoint %q: failed to list etcd members", endpoint))
+			continue
+		}
+
+		if leaderID == 0 {
+			errs = append(errs, errors.Errorf("endpoint %q: etcd member does not know the leader", endpoint))
+			continue
+		}
+		if knownLeaderID == 0 {
+			knownLeaderID = leaderID
+		} else if knownLeaderID != leaderID {
+			errs = append(errs, errors.Errorf("endpoint %q: etcd member reports leader %d, but all previously seen etcd members reported leader %d", endpoint, leaderID, knownLeaderID))
+			continue
+		}
+
+		for _, member := range members {
+			if len(member.Alarms) > 0 {
+				errs = append(errs, errors.Errorf("endpoint %q: etcd member %q reports alarms: %v", endpoint, member.Name, member.Alarms))
+			}
+		}
+
+		memberIDSet := etcd.MemberIDSet(members)
+		if knownMemberIDSet.Len() == 0 {
+			knownMemberIDSet = memberIDSet
+		} else if unknownMembers := memberIDSet.Difference(knownMemberIDSet); unknownMembers.Len() > 0 {
+			errs = append(errs, errors.Errorf("endpoint %q: etcd member reports members IDs %v, but all previously seen etcd members reported member IDs %v", endpoint, memberIDSet.UnsortedList(), knownMemberIDSet.UnsortedList()))
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+// UpdateKubernetesVersionInKubeadmConfigMap updates the kubernetes version in the kubeadm config map.
+func (w *Workload) UpdateKubernetesVersionInKubeadmConfigMap(ctx context.Context, version *version.Version) error {
+	configMapKey := types.NamespacedName{Name: kubeadmConfigKey, Namespace: metaNamespaceSystem}
+	kubeadmConfigMap, err := w.getConfigMap(ctx, configMapKey)
+	if err != nil {
+		return err
+	}
+	patchHelper, err := patch.NewHelper(kubeadmConfigMap, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for kubeadm ConfigMap")
+	}
+
+	config := &kubeadmConfig{ConfigMap: kubeadmConfigMap}
+	if err := config.UpdateKubernetesVersion(fmt.Sprintf("v%s", version)); err != nil {
+		return err
+	}
+	if err := patchHelper.Patch(ctx, config.ConfigMap); err != nil {
+		return errors.Wrapf(err, "error updating kubeadm ConfigMap")
+	}
+	return nil
+}
+
+// UpdateClusterConfigurationInKubeadmConfigMap updates the parts of the cluster configuration in the kubeadm config map
+// which can be changed on a running cluster.
+func (w *Workload) UpdateClusterConfigurationInKubeadmConfigMap(ctx context.Context, clusterConfiguration *kubeadmv1.ClusterConfiguration) error {
+	configMapKey := types.NamespacedName{Name: kubeadmConfigKey, Namespace: metaNamespaceSystem}
+	kubeadmConfigMap, err := w.getConfigMap(ctx, configMapKey)
+	if err != nil {
+		return err
+	}
+	patchHelper, err := patch.NewHelper(kubeadmConfigMap, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for kubeadm ConfigMap")
+	}
+
+	config := &kubeadmConfig{ConfigMap: kubeadmConfigMap}
+	if err := config.UpdateClusterConfiguration(clusterConfiguration); err != nil {
+		return err
+	}
+	if err := patchHelper.Patch(ctx, config.ConfigMap); err != nil {
+		return errors.Wrapf(err, "error updating kubeadm ConfigMap")
+	}
+	return nil
+}
+
+// UpdateKubeletConfigMap creates the kubelet config ConfigMap for the given version, if it doesn't exist yet,
+// by copying the one of the previous minor version.
+func (w *Workload) UpdateKubeletConfigMap(ctx context.Context, version *version.Version) error {
+	configMapName := kubeletConfigMapName(version)
+
+	// Return early if the ConfigMap for the desired version already exists.
+	existing := &corev1.ConfigMap{}
+	err := w.Client.Get(ctx, ctrlclient.ObjectKey{Name: configMapName, Namespace: metaNamespaceSystem}, existing)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "error determining if kubelet ConfigMap %s exists", configMapName)
+	}
+
+	previousConfigMapName := kubeletConfigMapName(version.WithMinor(version.Minor() - 1))
+	previous, err := w.getConfigMap(ctx, types.NamespacedName{Name: previousConfigMapName, Namespace: metaNamespaceSystem})
+	if err != nil {
+		return err
+	}
+
+	kubeletConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			kubeletConfigKey: previous.Data[kubeletConfigKey],
+		},
+	}
+	if err := w.Client.Create(ctx, kubeletConfigMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "error creating kubelet ConfigMap %s", configMapName)
+	}
+	return nil
+}
+
+// ReconcileKubeletRBACRole creates the Role allowing joining nodes to read the kubelet config ConfigMap
+// for the given version, if it doesn't exist yet.
+func (w *Workload) ReconcileKubeletRBACRole(ctx context.Context, version *version.Version) error {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeletConfigRBACName(version),
+			Namespace: metaNamespaceSystem,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				Verbs:         []string{"get"},
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{kubeletConfigMapName(version)},
+			},
+		},
+	}
+	if err := w.Client.Create(ctx, role); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create Role %s", role.Name)
+	}
+	return nil
+}
+
+// ReconcileKubeletRBACBinding binds the Role created by ReconcileKubeletRBACRole to the nodes and to
+// the nodes being bootstrapped with a kubeadm token, if the RoleBinding doesn't exist yet.
+func (w *Workload) ReconcileKubeletRBACBinding(ctx context.Context, version *version.Version) error {
+	roleName := kubeletConfigRBACName(version)
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      roleName,
+			Namespace: metaNamespaceSystem,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     roleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.GroupKind,
+				Name:     nodesGroup,
+			},
+			{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.GroupKind,
+				Name:     nodeBootstrapTokenAuthGroup,
+			},
+		},
+	}
+	if err := w.Client.Create(ctx, roleBinding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create RoleBinding %s", roleBinding.Name)
+	}
+	return nil
+}
+
+// kubeletConfigMapName returns the name of the ConfigMap kubeadm uses to store the kubelet configuration for a minor version.
+func kubeletConfigMapName(version *version.Version) string {
+	return fmt.Sprintf("kubelet-config-%d.%d", version.Major(), version.Minor())
+}
+
+// kubeletConfigRBACName returns the name of the Role and RoleBinding granting access to the kubelet config ConfigMap.
+func kubeletConfigRBACName(version *version.Version) string {
+	return fmt.Sprintf("kubeadm:%s", kubeletConfigMapName(version))
+}
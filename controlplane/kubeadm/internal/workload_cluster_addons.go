@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coredns/corefile-migration/migration"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/util/container"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+const (
+	kubeProxyKey      = "kube-proxy"
+	coreDNSKey        = "coredns"
+	corefileKey       = "Corefile"
+	corefileBackupKey = "Corefile-backup"
+)
+
+// UpdateKubeProxyImageInfo updates the kube-proxy DaemonSet image to the given Kubernetes version. The image is pulled
+// from the given repository, or from the repository of the current image if empty.
+func (w *Workload) UpdateKubeProxyImageInfo(ctx context.Context, version *version.Version, imageRepository string) error {
+	ds := &appsv1.DaemonSet{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Name: kubeProxyKey, Namespace: metaNamespaceSystem}, ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The cluster doesn't use kube-proxy, nothing to update.
+			return nil
+		}
+		return errors.Wrapf(err, "error getting %s/%s DaemonSet from target cluster", metaNamespaceSystem, kubeProxyKey)
+	}
+
+	proxyContainer := findContainer(ds.Spec.Template.Spec.Containers, kubeProxyKey)
+	if proxyContainer == nil {
+		return errors.Errorf("unable to find the %s container in the %s DaemonSet", kubeProxyKey, kubeProxyKey)
+	}
+	image, err := containerImageFromString(proxyContainer.Image)
+	if err != nil {
+		return err
+	}
+	if imageRepository != "" {
+		image.Repository = imageRepository
+	}
+	image.Tag = container.SemverToOCIImageTag(fmt.Sprintf("v%s", version))
+	image.Digest = ""
+	if proxyContainer.Image == image.String() {
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(ds, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s DaemonSet", kubeProxyKey)
+	}
+	proxyContainer.Image = image.String()
+	if err := patchHelper.Patch(ctx, ds); err != nil {
+		return errors.Wrapf(err, "error updating %s DaemonSet", kubeProxyKey)
+	}
+	return nil
+}
+
+// UpdateCoreDNS updates the CoreDNS Deployment image to the one defined by the given cluster configuration, migrating
+// the Corefile to the new CoreDNS version and recording the image in the kubeadm config map.
+func (w *Workload) UpdateCoreDNS(ctx context.Context, clusterConfiguration *kubeadmv1.ClusterConfiguration) error {
+	if clusterConfiguration == nil {
+		return nil
+	}
+	dns := clusterConfiguration.DNS
+	if dns.Type != "" && dns.Type != kubeadmv1.CoreDNS {
+		// Only CoreDNS is managed.
+		return nil
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Name: coreDNSKey, Namespace: metaNamespaceSystem}, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The cluster doesn't use CoreDNS, nothing to update.
+			return nil
+		}
+		return errors.Wrapf(err, "error getting %s/%s Deployment from target cluster", metaNamespaceSystem, coreDNSKey)
+	}
+
+	dnsContainer := findContainer(deployment.Spec.Template.Spec.Containers, coreDNSKey)
+	if dnsContainer == nil {
+		return errors.Errorf("unable to find the %s container in the %s Deployment", coreDNSKey, coreDNSKey)
+	}
+	current, err := containerImageFromString(dnsContainer.Image)
+	if err != nil {
+		return err
+	}
+	desired := current
+	switch {
+	case dns.ImageRepository != "":
+		desired.Repository = dns.ImageRepository
+	case clusterConfiguration.ImageRepository != "":
+		desired.Repository = clusterConfiguration.ImageRepository
+	}
+	if dns.ImageTag != "" {
+		desired.Tag = dns.ImageTag
+		desired.Digest = ""
+	}
+	if desired == current {
+		return nil
+	}
+
+	// The Corefile must be migrated before the new version of CoreDNS starts.
+	if desired.Tag != current.Tag {
+		if err := w.migrateCorefile(ctx, current.Tag, desired.Tag); err != nil {
+			return err
+		}
+	}
+
+	if err := w.updateDNSImageInfoInKubeadmConfigMap(ctx, &dns); err != nil {
+		return err
+	}
+
+	patchHelper, err := patch.NewHelper(deployment, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s Deployment", coreDNSKey)
+	}
+	dnsContainer.Image = desired.String()
+	if err := patchHelper.Patch(ctx, deployment); err != nil {
+		return errors.Wrapf(err, "error updating %s Deployment", coreDNSKey)
+	}
+	return nil
+}
+
+// migrateCorefile migrates the Corefile stored in the CoreDNS config map between the given versions, keeping a backup
+// of the original one. Downgrades are not supported.
+func (w *Workload) migrateCorefile(ctx context.Context, fromImageTag, toImageTag string) error {
+	fromVersion, toVersion := strings.TrimPrefix(fromImageTag, "v"), strings.TrimPrefix(toImageTag, "v")
+	from, err := version.ParseSemantic(fromVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the current CoreDNS version %q", fromVersion)
+	}
+	to, err := version.ParseSemantic(toVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the desired CoreDNS version %q", toVersion)
+	}
+	if to.LessThan(from) {
+		return errors.Errorf("downgrading CoreDNS from %s to %s is not supported", fromVersion, toVersion)
+	}
+
+	configMap, err := w.getConfigMap(ctx, types.NamespacedName{Name: coreDNSKey, Namespace: metaNamespaceSystem})
+	if err != nil {
+		return err
+	}
+	corefile, ok := configMap.Data[corefileKey]
+	if !ok {
+		return errors.Errorf("unable to find %q key in %s ConfigMap", corefileKey, coreDNSKey)
+	}
+
+	// The Corefile was already migrated by a previous attempt which failed to update the Deployment; migrating it
+	// again would overwrite the backup of the original Corefile.
+	if backup, ok := configMap.Data[corefileBackupKey]; ok {
+		if previous, err := migration.Migrate(fromVersion, toVersion, backup, false); err == nil && previous == corefile {
+			return nil
+		}
+	}
+
+	migrated, err := migration.Migrate(fromVersion, toVersion, corefile, false)
+	if err != nil {
+		return errors.Wrapf(err, "unable to migrate the Corefile from CoreDNS %s to %s", fromVersion, toVersion)
+	}
+
+	patchHelper, err := patch.NewHelper(configMap, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for %s ConfigMap", coreDNSKey)
+	}
+	configMap.Data[corefileBackupKey] = corefile
+	configMap.Data[corefileKey] = migrated
+	if err := patchHelper.Patch(ctx, configMap); err != nil {
+		return errors.Wrapf(err, "error updating %s ConfigMap", coreDNSKey)
+	}
+	return nil
+}
+
+func (w *Workload) updateDNSImageInfoInKubeadmConfigMap(ctx context.Context, dns *kubeadmv1.DNS) error {
+	configMapKey := types.NamespacedName{Name: kubeadmConfigKey, Namespace: metaNamespaceSystem}
+	kubeadmConfigMap, err := w.getConfigMap(ctx, configMapKey)
+	if err != nil {
+		return err
+	}
+	patchHelper, err := patch.NewHelper(kubeadmConfigMap, w.Client)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create patch helper for kubeadm ConfigMap")
+	}
+
+	config := &kubeadmConfig{ConfigMap: kubeadmConfigMap}
+	if err := config.UpdateDNSImageInfo(dns); err != nil {
+		return err
+	}
+	if err := patchHelper.Patch(ctx, config.ConfigMap); err != nil {
+		return errors.Wrapf(err, "error updating kubeadm ConfigMap")
+	}
+	return nil
+}
+
+// findContainer returns the container with the given name, or the only container if there is a single one.
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	if len(containers) == 1 {
+		return &containers[0]
+	}
+	return nil
+}
+
+func containerImageFromString(image string) (container.Image, error) {
+	res, err := container.ImageFromString(image)
+	if err != nil {
+		return res, errors.Wrapf(err, "unable to parse image %q", image)
+	}
+	return res, nil
+}
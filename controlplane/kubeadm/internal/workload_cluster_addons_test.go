@@ -0,0 +1,310 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coredns/corefile-migration/migration"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+)
+
+const corefile = `.:53 {
+    errors
+    health
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+       pods insecure
+       upstream
+       fallthrough in-addr.arpa ip6.arpa
+       ttl 30
+    }
+    prometheus :9153
+    forward . /etc/resolv.conf
+    cache 30
+    loop
+    reload
+    loadbalance
+}
+`
+
+func TestUpdateKubeProxyImageInfo(t *testing.T) {
+	tests := []struct {
+		name            string
+		image           string
+		imageRepository string
+		expectImage     string
+	}{
+		{
+			name:        "updates the image tag",
+			image:       "k8s.gcr.io/kube-proxy:v1.16.3",
+			expectImage: "k8s.gcr.io/kube-proxy:v1.17.2",
+		},
+		{
+			name:            "updates the image repository",
+			image:           "k8s.gcr.io/kube-proxy:v1.16.3",
+			imageRepository: "registry.example.com:5000/k8s",
+			expectImage:     "registry.example.com:5000/k8s/kube-proxy:v1.17.2",
+		},
+		{
+			name:        "drops the image digest",
+			image:       "k8s.gcr.io/kube-proxy:v1.16.3@sha256:c19e2a7e7d5d1b1b5d5e7b9a7f0bbd5f6c4f3e8a0b6e1d2c3f4a5b6c7d8e9f0a",
+			expectImage: "k8s.gcr.io/kube-proxy:v1.17.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			ds := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      kubeProxyKey,
+					Namespace: metaNamespaceSystem,
+				},
+				Spec: appsv1.DaemonSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: kubeProxyKey, Image: tt.image}},
+						},
+					},
+				},
+			}
+			w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, ds)}
+
+			g.Expect(w.UpdateKubeProxyImageInfo(context.Background(), version.MustParseSemantic("1.17.2"), tt.imageRepository)).To(gomega.Succeed())
+
+			updated := &appsv1.DaemonSet{}
+			g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: kubeProxyKey, Namespace: metaNamespaceSystem}, updated)).To(gomega.Succeed())
+			g.Expect(updated.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(tt.expectImage))
+		})
+	}
+}
+
+func TestUpdateKubeProxyImageInfoMissingDaemonSet(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+	g.Expect(w.UpdateKubeProxyImageInfo(context.Background(), version.MustParseSemantic("1.17.2"), "")).To(gomega.Succeed())
+}
+
+func TestUpdateCoreDNS(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: coreDNSKey, Image: "k8s.gcr.io/coredns:1.6.2"}},
+				},
+			},
+		},
+	}
+	coreDNSConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			corefileKey: corefile,
+		},
+	}
+	kubeadmConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeadmConfigKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			clusterConfigurationKey: `apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+kubernetesVersion: v1.16.1
+`,
+		},
+	}
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, deployment, coreDNSConfigMap, kubeadmConfigMap)}
+
+	clusterConfiguration := &kubeadmv1.ClusterConfiguration{
+		DNS: kubeadmv1.DNS{
+			ImageMeta: kubeadmv1.ImageMeta{
+				ImageRepository: "registry.example.com",
+				ImageTag:        "1.6.5",
+			},
+		},
+	}
+	g.Expect(w.UpdateCoreDNS(context.Background(), clusterConfiguration)).To(gomega.Succeed())
+
+	updatedDeployment := &appsv1.Deployment{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: coreDNSKey, Namespace: metaNamespaceSystem}, updatedDeployment)).To(gomega.Succeed())
+	g.Expect(updatedDeployment.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal("registry.example.com/coredns:1.6.5"))
+
+	updatedCoreDNSConfigMap := &corev1.ConfigMap{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: coreDNSKey, Namespace: metaNamespaceSystem}, updatedCoreDNSConfigMap)).To(gomega.Succeed())
+	g.Expect(updatedCoreDNSConfigMap.Data).To(gomega.HaveKeyWithValue(corefileBackupKey, corefile))
+	// The upstream plugin option is removed in CoreDNS 1.6.5.
+	g.Expect(updatedCoreDNSConfigMap.Data[corefileKey]).NotTo(gomega.ContainSubstring("upstream"))
+
+	updatedKubeadmConfigMap := &corev1.ConfigMap{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: kubeadmConfigKey, Namespace: metaNamespaceSystem}, updatedKubeadmConfigMap)).To(gomega.Succeed())
+	configuration := map[string]interface{}{}
+	g.Expect(yaml.Unmarshal([]byte(updatedKubeadmConfigMap.Data[clusterConfigurationKey]), &configuration)).To(gomega.Succeed())
+	g.Expect(configuration).To(gomega.HaveKeyWithValue("dns", map[string]interface{}{
+		"imageRepository": "registry.example.com",
+		"imageTag":        "1.6.5",
+	}))
+}
+
+func TestUpdateCoreDNSAlreadyMigratedCorefile(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	migrated, err := migration.Migrate("1.6.2", "1.6.5", corefile, false)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// A previous attempt migrated the Corefile but failed to update the Deployment.
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: coreDNSKey, Image: "k8s.gcr.io/coredns:1.6.2"}},
+				},
+			},
+		},
+	}
+	coreDNSConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			corefileKey:       migrated,
+			corefileBackupKey: corefile,
+		},
+	}
+	kubeadmConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeadmConfigKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			clusterConfigurationKey: `apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+kubernetesVersion: v1.16.1
+`,
+		},
+	}
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, deployment, coreDNSConfigMap, kubeadmConfigMap)}
+
+	clusterConfiguration := &kubeadmv1.ClusterConfiguration{
+		DNS: kubeadmv1.DNS{
+			ImageMeta: kubeadmv1.ImageMeta{ImageTag: "1.6.5"},
+		},
+	}
+	g.Expect(w.UpdateCoreDNS(context.Background(), clusterConfiguration)).To(gomega.Succeed())
+
+	// The backup of the original Corefile is kept.
+	updatedCoreDNSConfigMap := &corev1.ConfigMap{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: coreDNSKey, Namespace: metaNamespaceSystem}, updatedCoreDNSConfigMap)).To(gomega.Succeed())
+	g.Expect(updatedCoreDNSConfigMap.Data).To(gomega.HaveKeyWithValue(corefileBackupKey, corefile))
+	g.Expect(updatedCoreDNSConfigMap.Data).To(gomega.HaveKeyWithValue(corefileKey, migrated))
+
+	updatedDeployment := &appsv1.Deployment{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: coreDNSKey, Namespace: metaNamespaceSystem}, updatedDeployment)).To(gomega.Succeed())
+	g.Expect(updatedDeployment.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal("k8s.gcr.io/coredns:1.6.5"))
+}
+
+func TestUpdateCoreDNSDowngrade(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: coreDNSKey, Image: "k8s.gcr.io/coredns:1.6.5"}},
+				},
+			},
+		},
+	}
+	coreDNSConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			corefileKey: corefile,
+		},
+	}
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, deployment, coreDNSConfigMap)}
+
+	clusterConfiguration := &kubeadmv1.ClusterConfiguration{
+		DNS: kubeadmv1.DNS{
+			ImageMeta: kubeadmv1.ImageMeta{ImageTag: "1.6.2"},
+		},
+	}
+	g.Expect(w.UpdateCoreDNS(context.Background(), clusterConfiguration)).NotTo(gomega.Succeed())
+
+	updatedDeployment := &appsv1.Deployment{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: coreDNSKey, Namespace: metaNamespaceSystem}, updatedDeployment)).To(gomega.Succeed())
+	g.Expect(updatedDeployment.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal("k8s.gcr.io/coredns:1.6.5"))
+}
+
+func TestUpdateCoreDNSUnchangedImage(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      coreDNSKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: coreDNSKey, Image: "k8s.gcr.io/coredns:1.6.2"}},
+				},
+			},
+		},
+	}
+	// No ConfigMap exists, so any attempt to migrate the Corefile would fail.
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, deployment)}
+
+	clusterConfiguration := &kubeadmv1.ClusterConfiguration{
+		DNS: kubeadmv1.DNS{
+			ImageMeta: kubeadmv1.ImageMeta{ImageTag: "1.6.2"},
+		},
+	}
+	g.Expect(w.UpdateCoreDNS(context.Background(), clusterConfiguration)).To(gomega.Succeed())
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/cluster-api/util/proxy"
+)
+
+const (
+	// kubeAPIServerPort is the port kubeadm binds the kube-apiserver to by default.
+	kubeAPIServerPort = 6443
+
+	// kubeAPIServerServerName is a name present in all the kube-apiserver serving certificates generated by kubeadm.
+	kubeAPIServerServerName = "kubernetes"
+
+	// kubeAPIServerHandshakeTimeout bounds the time spent reading the kube-apiserver serving certificate.
+	kubeAPIServerHandshakeTimeout = 10 * time.Second
+)
+
+// GetAPIServerCertificateExpiry returns the expiry date of the serving certificate of the kube-apiserver running on
+// the given node. kubeadm generates all the certificates of a control plane node at the same time, so this is also
+// when the other certificates of the node expire.
+func (w *Workload) GetAPIServerCertificateExpiry(ctx context.Context, nodeName string) (*time.Time, error) {
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(w.restConfig.CAData) {
+		return nil, errors.New("failed to load the cluster CA from the workload cluster REST config")
+	}
+
+	p := proxy.Proxy{
+		Kind:         proxy.KindPods,
+		Namespace:    metaNamespaceSystem,
+		ResourceName: staticPodName("kube-apiserver", nodeName),
+		KubeConfig:   rest.CopyConfig(w.restConfig),
+		Port:         kubeAPIServerPort,
+	}
+	dialer, err := proxy.NewDialer(p)
+	if err != nil {
+		return nil, err
+	}
+	defer dialer.Close()
+	conn, err := dialer.DialContext(ctx, "tcp", "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to the kube-apiserver of node %q", nodeName)
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		RootCAs:    caPool,
+		ServerName: kubeAPIServerServerName,
+	})
+	defer tlsConn.Close()
+	if err := tlsConn.SetDeadline(time.Now().Add(kubeAPIServerHandshakeTimeout)); err != nil {
+		return nil, errors.Wrapf(err, "failed to set a deadline on the connection to the kube-apiserver of node %q", nodeName)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, errors.Wrapf(err, "failed to complete the TLS handshake with the kube-apiserver of node %q", nodeName)
+	}
+
+	peerCertificates := tlsConn.ConnectionState().PeerCertificates
+	if len(peerCertificates) == 0 {
+		return nil, errors.Errorf("the kube-apiserver of node %q didn't present a certificate", nodeName)
+	}
+	expiry := peerCertificates[0].NotAfter
+	return &expiry, nil
+}
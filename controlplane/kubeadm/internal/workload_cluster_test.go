@@ -0,0 +1,242 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestUpdateKubernetesVersionInKubeadmConfigMap(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	kubeadmConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeadmConfigKey,
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			clusterConfigurationKey: `apiVersion: kubeadm.k8s.io/v1beta2
+kind: ClusterConfiguration
+kubernetesVersion: v1.16.1
+`,
+		},
+	}
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, kubeadmConfigMap)}
+
+	g.Expect(w.UpdateKubernetesVersionInKubeadmConfigMap(context.Background(), version.MustParseSemantic("1.17.2"))).To(gomega.Succeed())
+
+	updated := &corev1.ConfigMap{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: kubeadmConfigKey, Namespace: metaNamespaceSystem}, updated)).To(gomega.Succeed())
+	configuration := map[string]interface{}{}
+	g.Expect(yaml.Unmarshal([]byte(updated.Data[clusterConfigurationKey]), &configuration)).To(gomega.Succeed())
+	g.Expect(configuration).To(gomega.HaveKeyWithValue(kubernetesVersionKey, "v1.17.2"))
+}
+
+func TestUpdateKubeletConfigMap(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	previous := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubelet-config-1.16",
+			Namespace: metaNamespaceSystem,
+		},
+		Data: map[string]string{
+			kubeletConfigKey: "apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\n",
+		},
+	}
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, previous)}
+
+	g.Expect(w.UpdateKubeletConfigMap(context.Background(), version.MustParseSemantic("1.17.2"))).To(gomega.Succeed())
+
+	created := &corev1.ConfigMap{}
+	g.Expect(w.Client.Get(context.Background(), ctrlclient.ObjectKey{Name: "kubelet-config-1.17", Namespace: metaNamespaceSystem}, created)).To(gomega.Succeed())
+	g.Expect(created.Data).To(gomega.Equal(previous.Data))
+
+	// Running again is a no-op.
+	g.Expect(w.UpdateKubeletConfigMap(context.Background(), version.MustParseSemantic("1.17.2"))).To(gomega.Succeed())
+}
+
+func TestUpdateKubeletConfigMapMissingPreviousVersion(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+	g.Expect(w.UpdateKubeletConfigMap(context.Background(), version.MustParseSemantic("1.17.2"))).NotTo(gomega.Succeed())
+}
+
+func TestReconcileKubeletRBAC(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+	v := version.MustParseSemantic("1.17.2")
+
+	// Reconciling twice must not fail on the already existing objects.
+	for i := 0; i < 2; i++ {
+		g.Expect(w.ReconcileKubeletRBACRole(context.Background(), v)).To(gomega.Succeed())
+		g.Expect(w.ReconcileKubeletRBACBinding(context.Background(), v)).To(gomega.Succeed())
+	}
+
+	key := ctrlclient.ObjectKey{Name: "kubeadm:kubelet-config-1.17", Namespace: metaNamespaceSystem}
+	role := &rbacv1.Role{}
+	g.Expect(w.Client.Get(context.Background(), key, role)).To(gomega.Succeed())
+	g.Expect(role.Rules).To(gomega.HaveLen(1))
+	g.Expect(role.Rules[0].ResourceNames).To(gomega.ConsistOf("kubelet-config-1.17"))
+
+	roleBinding := &rbacv1.RoleBinding{}
+	g.Expect(w.Client.Get(context.Background(), key, roleBinding)).To(gomega.Succeed())
+	g.Expect(roleBinding.RoleRef.Name).To(gomega.Equal(role.Name))
+	g.Expect(roleBinding.Subjects).To(gomega.HaveLen(2))
+}
+
+func staticPod(component, nodeName string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      staticPodName(component, nodeName),
+			Namespace: metaNamespaceSystem,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status},
+			},
+		},
+	}
+}
+
+func controlPlaneNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{labelNodeRoleMaster: ""},
+		},
+	}
+}
+
+func TestControlPlaneIsHealthy(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	objs := []runtime.Object{controlPlaneNode("healthy"), controlPlaneNode("unhealthy"), controlPlaneNode("missing-pod")}
+	for _, component := range []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler"} {
+		objs = append(objs, staticPod(component, "healthy", true), staticPod(component, "unhealthy", component != "kube-scheduler"))
+		if component != "kube-apiserver" {
+			objs = append(objs, staticPod(component, "missing-pod", true))
+		}
+	}
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, objs...)}
+
+	result, err := w.ControlPlaneIsHealthy(context.Background())
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.HaveLen(3))
+	g.Expect(result["healthy"]).NotTo(gomega.HaveOccurred())
+	g.Expect(result["unhealthy"]).To(gomega.MatchError(gomega.ContainSubstring("kube-scheduler")))
+	g.Expect(result["missing-pod"]).To(gomega.MatchError(gomega.ContainSubstring("kube-apiserver")))
+}
+
+func TestCanSafelyRemoveEtcdMemberSingleReplica(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	w := &Workload{Client: fake.NewFakeClientWithScheme(scheme.Scheme, controlPlaneNode("only"))}
+	machine := &clusterv1.Machine{
+		Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "only"}},
+	}
+
+	// The only etcd member can't be removed, and this is not an error.
+	ok, err := w.CanSafelyRemoveEtcdMember(context.Background(), machine)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ok).To(gomega.BeFalse())
+}
+
+func TestHealthCheckResultCompareMachines(t *testing.T) {
+	machine := func(name string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: name},
+			},
+		}
+	}
+	noNode := machine("no-node")
+	noNode.Status.NodeRef = nil
+
+	tests := []struct {
+		name      string
+		result    HealthCheckResult
+		machines  []*clusterv1.Machine
+		expectErr bool
+	}{
+		{
+			name:     "all Machines are healthy",
+			result:   HealthCheckResult{"a": nil, "b": nil},
+			machines: []*clusterv1.Machine{machine("a"), machine("b")},
+		},
+		{
+			name:      "a node reports an error",
+			result:    HealthCheckResult{"a": nil, "b": errors.New("unhealthy")},
+			machines:  []*clusterv1.Machine{machine("a"), machine("b")},
+			expectErr: true,
+		},
+		{
+			name:      "a node doesn't belong to any Machine",
+			result:    HealthCheckResult{"a": nil, "b": nil},
+			machines:  []*clusterv1.Machine{machine("a")},
+			expectErr: true,
+		},
+		{
+			name:      "the node of a Machine was not checked",
+			result:    HealthCheckResult{"a": nil},
+			machines:  []*clusterv1.Machine{machine("a"), machine("b")},
+			expectErr: true,
+		},
+		{
+			name:      "a Machine doesn't have a node",
+			result:    HealthCheckResult{"a": nil},
+			machines:  []*clusterv1.Machine{machine("a"), noNode},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			err := tt.result.CompareMachines(tt.machines)
+			if tt.expectErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+		})
+	}
+}
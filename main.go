@@ -35,6 +35,7 @@ import (
 	clusterv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -54,17 +55,21 @@ func init() {
 
 func main() {
 	var (
-		metricsAddr                  string
-		enableLeaderElection         bool
-		watchNamespace               string
-		profilerAddress              string
-		clusterConcurrency           int
-		machineConcurrency           int
-		machineSetConcurrency        int
-		machineDeploymentConcurrency int
-		machinePoolConcurrency       int
-		syncPeriod                   time.Duration
-		webhookPort                  int
+		metricsAddr                   string
+		enableLeaderElection          bool
+		watchNamespace                string
+		profilerAddress               string
+		clusterConcurrency            int
+		machineConcurrency            int
+		machineSetConcurrency         int
+		machineDeploymentConcurrency  int
+		machinePoolConcurrency        int
+		machineHealthCheckConcurrency int
+		syncPeriod                    time.Duration
+		webhookPort                   int
+		nodeDrainDisableEviction      bool
+		nodeDrainSkipPodSelector      string
+		nodeDrainGracePeriod          int
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080",
@@ -94,12 +99,24 @@ func main() {
 	flag.IntVar(&machinePoolConcurrency, "machinepool-concurrency", 10,
 		"Number of machine pools to process simultaneously")
 
+	flag.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
+		"Number of machine health checks to process simultaneously")
+
 	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
 	flag.IntVar(&webhookPort, "webhook-port", 9443,
 		"Webhook Server port (set to 0 to disable)")
 
+	flag.BoolVar(&nodeDrainDisableEviction, "node-drain-disable-eviction", false,
+		"Delete pods instead of evicting them when draining nodes, ignoring PodDisruptionBudgets")
+
+	flag.StringVar(&nodeDrainSkipPodSelector, "node-drain-skip-pod-selector", "",
+		"Label selector of the pods that are not evicted when draining nodes (e.g. app=critical)")
+
+	flag.IntVar(&nodeDrainGracePeriod, "node-drain-grace-period", -1,
+		"Period of time in seconds given to each pod to terminate gracefully when draining nodes. If negative, the default value specified in the pod will be used")
+
 	flag.Parse()
 
 	ctrl.SetLogger(klogr.New())
@@ -126,6 +143,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Set up a ClusterCacheTracker and ClusterCacheReconciler to provide to controllers
+	// requiring a connection to a remote cluster
+	tracker, err := remote.NewClusterCacheTracker(
+		ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
+		mgr,
+	)
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster cache tracker")
+		os.Exit(1)
+	}
+	if err := (&remote.ClusterCacheReconciler{
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("remote").WithName("ClusterCacheReconciler"),
+		Tracker: tracker,
+	}).SetupWithManager(mgr, concurrency(clusterConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterCacheReconciler")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.ClusterReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("Cluster"),
@@ -134,15 +170,22 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.MachineReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Machine"),
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("controllers").WithName("Machine"),
+		Tracker: tracker,
+		NodeDrainOptions: controllers.NodeDrainOptions{
+			DisableEviction:    nodeDrainDisableEviction,
+			SkipPodSelector:    nodeDrainSkipPodSelector,
+			GracePeriodSeconds: &nodeDrainGracePeriod,
+		},
 	}).SetupWithManager(mgr, concurrency(machineConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Machine")
 		os.Exit(1)
 	}
 	if err = (&controllers.MachineSetReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachineSet"),
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("controllers").WithName("MachineSet"),
+		Tracker: tracker,
 	}).SetupWithManager(mgr, concurrency(machineSetConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
 		os.Exit(1)
@@ -155,12 +198,21 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.MachinePoolReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachinePool"),
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("controllers").WithName("MachinePool"),
+		Tracker: tracker,
 	}).SetupWithManager(mgr, concurrency(machinePoolConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachinePool")
 		os.Exit(1)
 	}
+	if err = (&controllers.MachineHealthCheckReconciler{
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("controllers").WithName("MachineHealthCheck"),
+		Tracker: tracker,
+	}).SetupWithManager(mgr, concurrency(machineHealthCheckConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachineHealthCheck")
+		os.Exit(1)
+	}
 
 	if webhookPort != 0 {
 		if err = (&clusterv1alpha2.Cluster{}).SetupWebhookWithManager(mgr); err != nil {
@@ -223,6 +275,11 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "MachinePool")
 			os.Exit(1)
 		}
+
+		if err = (&clusterv1alpha3.MachineHealthCheck{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MachineHealthCheck")
+			os.Exit(1)
+		}
 	}
 
 	// +kubebuilder:scaffold:builder
@@ -51,8 +51,16 @@ type Helper struct {
 	DeleteLocalData     bool
 	Selector            string
 	PodSelector         string
-	Out                 io.Writer
-	ErrOut              io.Writer
+
+	// SkipPodSelector is a label selector; pods matching it are left on the node.
+	SkipPodSelector string
+
+	// DisableEviction forces drain to use delete rather than evict, so
+	// PodDisruptionBudgets are not honored.
+	DisableEviction bool
+
+	Out    io.Writer
+	ErrOut io.Writer
 
 	// TODO(justinsb): unnecessary?
 	DryRun bool
@@ -135,6 +143,14 @@ func (d *Helper) GetPodsForDeletion(nodeName string) (*podDeleteList, []error) {
 		return nil, []error{err}
 	}
 
+	var skipSelector labels.Selector
+	if d.SkipPodSelector != "" {
+		skipSelector, err = labels.Parse(d.SkipPodSelector)
+		if err != nil {
+			return nil, []error{err}
+		}
+	}
+
 	podList, err := d.Client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
 		LabelSelector: labelSelector.String(),
 		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": nodeName}).String()})
@@ -145,6 +161,10 @@ func (d *Helper) GetPodsForDeletion(nodeName string) (*podDeleteList, []error) {
 	pods := []podDelete{}
 
 	for _, pod := range podList.Items {
+		if skipSelector != nil && skipSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
 		var status podDeleteStatus
 		for _, filter := range d.makeFilters() {
 			status = filter(pod)
@@ -176,17 +196,20 @@ func (d *Helper) DeleteOrEvictPods(pods []corev1.Pod) error {
 		return nil
 	}
 
-	policyGroupVersion, err := CheckEvictionSupport(d.Client)
-	if err != nil {
-		return err
-	}
-
 	// TODO(justinsb): unnecessary?
 	getPodFn := func(namespace, name string) (*corev1.Pod, error) {
 		return d.Client.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
 	}
-	if len(policyGroupVersion) > 0 {
-		return d.evictPods(pods, policyGroupVersion, getPodFn)
+
+	if !d.DisableEviction {
+		policyGroupVersion, err := CheckEvictionSupport(d.Client)
+		if err != nil {
+			return err
+		}
+
+		if len(policyGroupVersion) > 0 {
+			return d.evictPods(pods, policyGroupVersion, getPodFn)
+		}
 	}
 
 	return d.deletePods(pods, getPodFn)
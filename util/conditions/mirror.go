@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+// MirrorOption defines an option for the SetMirror operation.
+type MirrorOption func(*mirrorOptions)
+
+type mirrorOptions struct {
+	fallbackTo       *bool
+	fallbackReason   string
+	fallbackSeverity clusterv1.ConditionSeverity
+	fallbackMessage  string
+}
+
+// WithFallbackValue specifies the condition to be used when the source object doesn't
+// report a Ready condition; the condition is True if fallbackValue is true, otherwise
+// False with the given reason, severity and message.
+func WithFallbackValue(fallbackValue bool, reason string, severity clusterv1.ConditionSeverity, message string) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.fallbackTo = &fallbackValue
+		o.fallbackReason = reason
+		o.fallbackSeverity = severity
+		o.fallbackMessage = message
+	}
+}
+
+// SetMirror creates a new condition by mirroring the Ready condition from a dependent object;
+// if the Ready condition does not exist in the source object, the fallback value is used, if any,
+// otherwise no target condition is generated.
+func SetMirror(to Setter, targetCondition clusterv1.ConditionType, from Getter, options ...MirrorOption) {
+	Set(to, mirror(from, targetCondition, options...))
+}
+
+// mirror extracts the Ready condition from a dependent object and returns it as a condition
+// with the target type.
+func mirror(from Getter, targetCondition clusterv1.ConditionType, options ...MirrorOption) *clusterv1.Condition {
+	mirrorOpt := &mirrorOptions{}
+	for _, o := range options {
+		o(mirrorOpt)
+	}
+
+	if from != nil {
+		if condition := Get(from, clusterv1.ReadyCondition); condition != nil {
+			mirrored := condition.DeepCopy()
+			mirrored.Type = targetCondition
+			return mirrored
+		}
+	}
+
+	if mirrorOpt.fallbackTo == nil {
+		return nil
+	}
+	if *mirrorOpt.fallbackTo {
+		return TrueCondition(targetCondition)
+	}
+	return FalseCondition(targetCondition, mirrorOpt.fallbackReason, mirrorOpt.fallbackSeverity, mirrorOpt.fallbackMessage)
+}
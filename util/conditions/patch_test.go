@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestNewPatch(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	before := &fakeObject{}
+	MarkTrue(before, "foo")
+	MarkTrue(before, "bar")
+
+	after := &fakeObject{}
+	MarkFalse(after, "foo", "Reason", clusterv1.ConditionSeverityWarning, "")
+	MarkTrue(after, "baz")
+
+	patch := NewPatch(before, after)
+	g.Expect(patch).To(gomega.HaveLen(3))
+
+	ops := map[clusterv1.ConditionType]PatchOperationType{}
+	for _, op := range patch {
+		if op.After != nil {
+			ops[op.After.Type] = op.Op
+		} else {
+			ops[op.Before.Type] = op.Op
+		}
+	}
+	g.Expect(ops).To(gomega.Equal(map[clusterv1.ConditionType]PatchOperationType{
+		"foo": ChangeConditionPatch,
+		"bar": RemoveConditionPatch,
+		"baz": AddConditionPatch,
+	}))
+
+	g.Expect(NewPatch(before, before).IsZero()).To(gomega.BeTrue())
+}
+
+func TestPatchApply(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	before := &fakeObject{}
+	MarkTrue(before, "foo")
+
+	after := &fakeObject{}
+	MarkFalse(after, "foo", "Reason", clusterv1.ConditionSeverityWarning, "")
+	MarkTrue(after, "bar")
+
+	// Changes to other conditions made by another process in the meantime are preserved.
+	latest := &fakeObject{}
+	MarkTrue(latest, "foo")
+	MarkTrue(latest, "baz")
+
+	g.Expect(NewPatch(before, after).Apply(latest)).To(gomega.Succeed())
+	g.Expect(IsFalse(latest, "foo")).To(gomega.BeTrue())
+	g.Expect(IsTrue(latest, "bar")).To(gomega.BeTrue())
+	g.Expect(IsTrue(latest, "baz")).To(gomega.BeTrue())
+
+	// A condition changed in a different way by another process is a conflict.
+	latest = &fakeObject{}
+	MarkFalse(latest, "foo", "OtherReason", clusterv1.ConditionSeverityInfo, "")
+
+	g.Expect(NewPatch(before, after).Apply(latest)).NotTo(gomega.Succeed())
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+type fakeObject struct {
+	conditions clusterv1.Conditions
+}
+
+func (o *fakeObject) GetConditions() clusterv1.Conditions {
+	return o.conditions
+}
+
+func (o *fakeObject) SetConditions(conditions clusterv1.Conditions) {
+	o.conditions = conditions
+}
+
+func TestSet(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	obj := &fakeObject{}
+	MarkTrue(obj, "foo")
+	MarkFalse(obj, "bar", "Reason", clusterv1.ConditionSeverityWarning, "message %d", 1)
+	MarkTrue(obj, clusterv1.ReadyCondition)
+
+	// The Ready condition goes first, the others are sorted by type.
+	g.Expect(obj.conditions).To(gomega.HaveLen(3))
+	g.Expect(obj.conditions[0].Type).To(gomega.Equal(clusterv1.ReadyCondition))
+	g.Expect(obj.conditions[1].Type).To(gomega.Equal(clusterv1.ConditionType("bar")))
+	g.Expect(obj.conditions[2].Type).To(gomega.Equal(clusterv1.ConditionType("foo")))
+
+	bar := Get(obj, "bar")
+	g.Expect(bar.Status).To(gomega.Equal(corev1.ConditionFalse))
+	g.Expect(bar.Reason).To(gomega.Equal("Reason"))
+	g.Expect(bar.Severity).To(gomega.Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(bar.Message).To(gomega.Equal("message 1"))
+	g.Expect(bar.LastTransitionTime.IsZero()).To(gomega.BeFalse())
+}
+
+func TestSetPreservesLastTransitionTime(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	lastTransitionTime := metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	obj := &fakeObject{
+		conditions: clusterv1.Conditions{
+			{Type: "foo", Status: corev1.ConditionFalse, Reason: "Reason", Severity: clusterv1.ConditionSeverityInfo, LastTransitionTime: lastTransitionTime},
+		},
+	}
+
+	// The state doesn't change.
+	MarkFalse(obj, "foo", "Reason", clusterv1.ConditionSeverityInfo, "")
+	g.Expect(Get(obj, "foo").LastTransitionTime).To(gomega.Equal(lastTransitionTime))
+
+	// The state changes.
+	MarkTrue(obj, "foo")
+	g.Expect(Get(obj, "foo").LastTransitionTime).NotTo(gomega.Equal(lastTransitionTime))
+	g.Expect(IsTrue(obj, "foo")).To(gomega.BeTrue())
+}
+
+func TestGetters(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	obj := &fakeObject{}
+	g.Expect(Get(obj, "foo")).To(gomega.BeNil())
+	g.Expect(Has(obj, "foo")).To(gomega.BeFalse())
+	g.Expect(IsTrue(obj, "foo")).To(gomega.BeFalse())
+	g.Expect(IsFalse(obj, "foo")).To(gomega.BeFalse())
+	g.Expect(GetReason(obj, "foo")).To(gomega.BeEmpty())
+
+	MarkFalse(obj, "foo", "Reason", clusterv1.ConditionSeverityError, "message")
+	g.Expect(Has(obj, "foo")).To(gomega.BeTrue())
+	g.Expect(IsTrue(obj, "foo")).To(gomega.BeFalse())
+	g.Expect(IsFalse(obj, "foo")).To(gomega.BeTrue())
+	g.Expect(GetReason(obj, "foo")).To(gomega.Equal("Reason"))
+	g.Expect(GetMessage(obj, "foo")).To(gomega.Equal("message"))
+	g.Expect(*GetSeverity(obj, "foo")).To(gomega.Equal(clusterv1.ConditionSeverityError))
+	g.Expect(GetLastTransitionTime(obj, "foo").IsZero()).To(gomega.BeFalse())
+
+	g.Expect(IsUnknown(obj, "bar")).To(gomega.BeTrue())
+	MarkUnknown(obj, "bar", "Reason", "message")
+	g.Expect(IsUnknown(obj, "bar")).To(gomega.BeTrue())
+	g.Expect(GetSeverity(obj, "baz")).To(gomega.BeNil())
+	g.Expect(GetLastTransitionTime(obj, "baz")).To(gomega.BeNil())
+}
+
+func TestDelete(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	obj := &fakeObject{}
+	MarkTrue(obj, "foo")
+	MarkTrue(obj, "bar")
+	Delete(obj, "foo")
+	g.Expect(Has(obj, "foo")).To(gomega.BeFalse())
+	g.Expect(Has(obj, "bar")).To(gomega.BeTrue())
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+// SummaryOption defines an option for the SetSummary operation.
+type SummaryOption func(*summaryOptions)
+
+type summaryOptions struct {
+	conditionTypes []clusterv1.ConditionType
+}
+
+// WithConditions restricts the summary to the given list of condition types; the order of the
+// list defines the priority of conditions with the same severity when computing the summary.
+func WithConditions(t ...clusterv1.ConditionType) SummaryOption {
+	return func(o *summaryOptions) {
+		o.conditionTypes = t
+	}
+}
+
+// SetSummary sets a Ready condition with the summary of all the conditions existing
+// on an object, or of the conditions selected using WithConditions.
+// If no condition can be summarized, the Ready condition is left untouched.
+func SetSummary(to Setter, options ...SummaryOption) {
+	Set(to, summary(to, options...))
+}
+
+// summary returns a Ready condition with the summary of the given conditions:
+//   - if any of the conditions is False, the Ready condition is False and it reports the
+//     reason, severity and message of the False condition with the highest severity;
+//   - if none of the conditions is False but some are Unknown, the Ready condition is Unknown
+//     and it reports the reason and message of the first Unknown condition;
+//   - otherwise the Ready condition is True.
+func summary(from Getter, options ...SummaryOption) *clusterv1.Condition {
+	summaryOpt := &summaryOptions{}
+	for _, o := range options {
+		o(summaryOpt)
+	}
+
+	var candidates []clusterv1.Condition
+	if summaryOpt.conditionTypes != nil {
+		for _, t := range summaryOpt.conditionTypes {
+			if c := Get(from, t); c != nil {
+				candidates = append(candidates, *c)
+			}
+		}
+	} else {
+		for _, c := range from.GetConditions() {
+			if c.Type != clusterv1.ReadyCondition {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var worstFalse, firstUnknown *clusterv1.Condition
+	for i := range candidates {
+		c := &candidates[i]
+		switch c.Status {
+		case corev1.ConditionFalse:
+			if worstFalse == nil || severityRank(c.Severity) > severityRank(worstFalse.Severity) {
+				worstFalse = c
+			}
+		case corev1.ConditionUnknown:
+			if firstUnknown == nil {
+				firstUnknown = c
+			}
+		}
+	}
+
+	if worstFalse != nil {
+		return FalseCondition(clusterv1.ReadyCondition, worstFalse.Reason, worstFalse.Severity, "%s", worstFalse.Message)
+	}
+	if firstUnknown != nil {
+		return UnknownCondition(clusterv1.ReadyCondition, firstUnknown.Reason, "%s", firstUnknown.Message)
+	}
+	return TrueCondition(clusterv1.ReadyCondition)
+}
+
+// severityRank returns a number that allows to compare condition severities, the higher
+// the number the more severe the condition.
+func severityRank(s clusterv1.ConditionSeverity) int {
+	switch s {
+	case clusterv1.ConditionSeverityError:
+		return 3
+	case clusterv1.ConditionSeverityWarning:
+		return 2
+	case clusterv1.ConditionSeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
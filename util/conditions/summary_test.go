@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestSetSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(obj *fakeObject)
+		options  []SummaryOption
+		expected *clusterv1.Condition
+	}{
+		{
+			name:     "no conditions",
+			setup:    func(obj *fakeObject) {},
+			expected: nil,
+		},
+		{
+			name: "all conditions true",
+			setup: func(obj *fakeObject) {
+				MarkTrue(obj, "foo")
+				MarkTrue(obj, "bar")
+			},
+			expected: TrueCondition(clusterv1.ReadyCondition),
+		},
+		{
+			name: "the false condition with the highest severity wins",
+			setup: func(obj *fakeObject) {
+				MarkFalse(obj, "foo", "InfoReason", clusterv1.ConditionSeverityInfo, "info")
+				MarkFalse(obj, "bar", "ErrorReason", clusterv1.ConditionSeverityError, "error")
+				MarkUnknown(obj, "baz", "UnknownReason", "unknown")
+			},
+			expected: FalseCondition(clusterv1.ReadyCondition, "ErrorReason", clusterv1.ConditionSeverityError, "error"),
+		},
+		{
+			name: "unknown conditions win over true conditions",
+			setup: func(obj *fakeObject) {
+				MarkTrue(obj, "foo")
+				MarkUnknown(obj, "bar", "UnknownReason", "unknown")
+			},
+			expected: UnknownCondition(clusterv1.ReadyCondition, "UnknownReason", "unknown"),
+		},
+		{
+			name: "only the selected conditions are summarized",
+			setup: func(obj *fakeObject) {
+				MarkTrue(obj, "foo")
+				MarkFalse(obj, "bar", "Reason", clusterv1.ConditionSeverityWarning, "")
+			},
+			options:  []SummaryOption{WithConditions("foo", "baz")},
+			expected: TrueCondition(clusterv1.ReadyCondition),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			obj := &fakeObject{}
+			tt.setup(obj)
+			SetSummary(obj, tt.options...)
+
+			ready := Get(obj, clusterv1.ReadyCondition)
+			if tt.expected == nil {
+				g.Expect(ready).To(gomega.BeNil())
+				return
+			}
+			g.Expect(ready).NotTo(gomega.BeNil())
+			g.Expect(hasSameState(ready, tt.expected)).To(gomega.BeTrue(), "expected %+v, got %+v", tt.expected, ready)
+		})
+	}
+}
+
+func TestSetMirror(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	source := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":     "Ready",
+						"status":   "False",
+						"reason":   "Provisioning",
+						"severity": "Info",
+						"message":  "waiting for instance",
+					},
+				},
+			},
+		},
+	}
+
+	// The Ready condition of the source object is mirrored.
+	obj := &fakeObject{}
+	SetMirror(obj, "InfrastructureReady", UnstructuredGetter(source), WithFallbackValue(true, "", "", ""))
+	g.Expect(IsFalse(obj, "InfrastructureReady")).To(gomega.BeTrue())
+	g.Expect(GetReason(obj, "InfrastructureReady")).To(gomega.Equal("Provisioning"))
+	g.Expect(GetMessage(obj, "InfrastructureReady")).To(gomega.Equal("waiting for instance"))
+
+	// The fallback value is used when the source object doesn't report a Ready condition.
+	obj = &fakeObject{}
+	SetMirror(obj, "InfrastructureReady", UnstructuredGetter(&unstructured.Unstructured{Object: map[string]interface{}{}}),
+		WithFallbackValue(false, "WaitingForInfrastructure", clusterv1.ConditionSeverityInfo, ""))
+	g.Expect(Get(obj, "InfrastructureReady").Status).To(gomega.Equal(corev1.ConditionFalse))
+	g.Expect(GetReason(obj, "InfrastructureReady")).To(gomega.Equal("WaitingForInfrastructure"))
+
+	// No condition is set without a fallback value.
+	obj = &fakeObject{}
+	SetMirror(obj, "InfrastructureReady", UnstructuredGetter(&unstructured.Unstructured{Object: map[string]interface{}{}}))
+	g.Expect(Has(obj, "InfrastructureReady")).To(gomega.BeFalse())
+}
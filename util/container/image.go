@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package container implements utilities to manipulate container image references.
+package container
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Image is a container image reference, e.g. k8s.gcr.io/coredns:1.6.2.
+type Image struct {
+	// Repository is the registry and path the image is pulled from, e.g. k8s.gcr.io.
+	Repository string
+	// Name is the name of the image, e.g. coredns.
+	Name string
+	// Tag is the tag of the image, e.g. 1.6.2.
+	Tag string
+	// Digest is the digest of the image, e.g. sha256:... .
+	Digest string
+}
+
+// ImageFromString parses a container image reference.
+func ImageFromString(image string) (Image, error) {
+	var res Image
+	if image == "" {
+		return res, errors.New("image reference is empty")
+	}
+
+	remainder := image
+	if i := strings.Index(remainder, "@"); i >= 0 {
+		res.Digest = remainder[i+1:]
+		remainder = remainder[:i]
+	}
+	if i := strings.LastIndex(remainder, "/"); i >= 0 {
+		res.Repository = remainder[:i]
+		remainder = remainder[i+1:]
+	}
+	// A colon in the last path component separates the tag, a colon before it would be a registry port.
+	if i := strings.Index(remainder, ":"); i >= 0 {
+		res.Tag = remainder[i+1:]
+		remainder = remainder[:i]
+	}
+	res.Name = remainder
+
+	if res.Name == "" {
+		return res, errors.Errorf("image reference %q has no name", image)
+	}
+	return res, nil
+}
+
+// String returns the image reference.
+func (i Image) String() string {
+	var b strings.Builder
+	if i.Repository != "" {
+		b.WriteString(i.Repository)
+		b.WriteString("/")
+	}
+	b.WriteString(i.Name)
+	if i.Tag != "" {
+		b.WriteString(":")
+		b.WriteString(i.Tag)
+	}
+	if i.Digest != "" {
+		b.WriteString("@")
+		b.WriteString(i.Digest)
+	}
+	return b.String()
+}
+
+// SemverToOCIImageTag converts a semantic version to a valid image tag, following the convention used
+// by kubeadm of replacing the "+" build metadata separator with an underscore.
+func SemverToOCIImageTag(version string) string {
+	return strings.Replace(version, "+", "_", -1)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestImageFromString(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected Image
+	}{
+		{
+			image:    "k8s.gcr.io/coredns:1.6.2",
+			expected: Image{Repository: "k8s.gcr.io", Name: "coredns", Tag: "1.6.2"},
+		},
+		{
+			image:    "registry.example.com:5000/kubernetes/kube-proxy:v1.17.0",
+			expected: Image{Repository: "registry.example.com:5000/kubernetes", Name: "kube-proxy", Tag: "v1.17.0"},
+		},
+		{
+			image:    "coredns@sha256:abcd",
+			expected: Image{Name: "coredns", Digest: "sha256:abcd"},
+		},
+		{
+			image:    "registry.example.com:5000/coredns",
+			expected: Image{Repository: "registry.example.com:5000", Name: "coredns"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			image, err := ImageFromString(tt.image)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(image).To(gomega.Equal(tt.expected))
+			g.Expect(image.String()).To(gomega.Equal(tt.image))
+		})
+	}
+}
+
+func TestImageFromStringInvalid(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	_, err := ImageFromString("")
+	g.Expect(err).To(gomega.HaveOccurred())
+	_, err = ImageFromString("k8s.gcr.io/:1.6.2")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestSemverToOCIImageTag(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	g.Expect(SemverToOCIImageTag("v1.17.0")).To(gomega.Equal("v1.17.0"))
+	g.Expect(SemverToOCIImageTag("v1.17.0+build.1")).To(gomega.Equal("v1.17.0_build.1"))
+}
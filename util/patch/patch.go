@@ -21,9 +21,13 @@ import (
 	"reflect"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -36,6 +40,11 @@ type Helper struct {
 	beforeStatus  interface{}
 	resourcePatch client.Patch
 	statusPatch   client.Patch
+
+	// beforeConditions is a copy of the resource taken when the helper was initialized,
+	// used to detect the changes to the conditions; it is nil if the resource doesn't
+	// implement conditions.
+	beforeConditions conditions.Getter
 }
 
 // NewHelper returns an initialized Helper
@@ -70,13 +79,19 @@ func NewHelper(resource runtime.Object, crClient client.Client) (*Helper, error)
 		unstructured.RemoveNestedField(before, "status")
 	}
 
+	var beforeConditions conditions.Getter
+	if getter, ok := resource.DeepCopyObject().(conditions.Getter); ok {
+		beforeConditions = getter
+	}
+
 	return &Helper{
-		client:        crClient,
-		before:        before,
-		beforeStatus:  beforeStatus,
-		hasStatus:     hasStatus,
-		resourcePatch: client.MergeFrom(resource.DeepCopyObject()),
-		statusPatch:   client.MergeFrom(resource.DeepCopyObject()),
+		client:           crClient,
+		before:           before,
+		beforeStatus:     beforeStatus,
+		hasStatus:        hasStatus,
+		resourcePatch:    client.MergeFrom(resource.DeepCopyObject()),
+		statusPatch:      client.MergeFrom(resource.DeepCopyObject()),
+		beforeConditions: beforeConditions,
 	}, nil
 }
 
@@ -93,6 +108,12 @@ func (h *Helper) Patch(ctx context.Context, resource runtime.Object) error {
 		resource = resource.DeepCopyObject()
 	}
 
+	// Merge the changes to the conditions into the latest version of the conditions,
+	// so the changes made by other controllers in the meantime are not overwritten.
+	if err := h.mergeConditions(ctx, resource); err != nil {
+		return err
+	}
+
 	// Convert the resource to unstructured to compare against our before copy.
 	after, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
 	if err != nil {
@@ -132,3 +153,84 @@ func (h *Helper) Patch(ctx context.Context, resource runtime.Object) error {
 
 	return kerrors.NewAggregate(errs)
 }
+
+// mergeConditions applies the changes made to the conditions of the resource since the helper
+// was initialized on top of the conditions of the latest version of the resource; the resource
+// is then updated with the resulting conditions.
+func (h *Helper) mergeConditions(ctx context.Context, resource runtime.Object) error {
+	setter, ok := resource.(conditions.Setter)
+	if !ok || h.beforeConditions == nil {
+		return nil
+	}
+
+	diff := conditions.NewPatch(h.beforeConditions, setter)
+	if diff.IsZero() {
+		return nil
+	}
+
+	key, err := client.ObjectKeyFromObject(resource)
+	if err != nil {
+		return err
+	}
+
+	var latest conditions.Setter
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		// Get the latest version of the resource.
+		latestObj := reflect.New(reflect.TypeOf(resource).Elem()).Interface().(runtime.Object)
+		if err := h.client.Get(ctx, key, latestObj); err != nil {
+			return errors.Wrapf(err, "failed to get the latest version of %s before patching its conditions", key)
+		}
+		var ok bool
+		latest, ok = latestObj.(conditions.Setter)
+		if !ok {
+			return errors.Errorf("expected %T to implement conditions.Setter", latestObj)
+		}
+
+		conditionsPatch := mergeFromWithOptimisticLock{from: latestObj.DeepCopyObject()}
+		if err := diff.Apply(latest); err != nil {
+			return err
+		}
+		// Patch the conditions using the resource version of the latest version of the resource, so
+		// that changes made in the meantime by someone else are detected and the merge is retried.
+		return h.client.Status().Patch(ctx, latestObj, conditionsPatch)
+	})
+	if err != nil {
+		return err
+	}
+
+	setter.SetConditions(latest.GetConditions())
+	return nil
+}
+
+// mergeFromWithOptimisticLock is a merge patch which includes the resource version of the object, so
+// that the patch fails with a conflict if the object was changed since it was read.
+type mergeFromWithOptimisticLock struct {
+	from runtime.Object
+}
+
+// Type implements client.Patch.
+func (p mergeFromWithOptimisticLock) Type() types.PatchType {
+	return types.MergePatchType
+}
+
+// Data implements client.Patch.
+func (p mergeFromWithOptimisticLock) Data(obj runtime.Object) ([]byte, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if accessor.GetResourceVersion() == "" {
+		// There is no resource version to lock on, e.g. for objects not read from an API server.
+		return client.MergeFrom(p.from).Data(obj)
+	}
+
+	// Clear the resource version of the original object so that the one of the modified object
+	// is always part of the patch.
+	from := p.from.DeepCopyObject()
+	fromAccessor, err := meta.Accessor(from)
+	if err != nil {
+		return nil, err
+	}
+	fromAccessor.SetResourceVersion("")
+	return client.MergeFrom(from).Data(obj)
+}
@@ -21,16 +21,20 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestHelperUnstructuredPatch(t *testing.T) {
@@ -245,3 +249,130 @@ func TestHelperPatch(t *testing.T) {
 		})
 	}
 }
+
+func TestHelperPatchConditions(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+		},
+	}
+	conditions.MarkTrue(machine, clusterv1.BootstrapReadyCondition)
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, machine.DeepCopy())
+
+	h, err := NewHelper(machine, fakeClient)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Another controller changes a different condition in the meantime.
+	concurrent := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test-machine"}, concurrent)).To(Succeed())
+	conditions.MarkFalse(concurrent, clusterv1.NodeHealthyCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityError, "")
+	g.Expect(fakeClient.Status().Update(ctx, concurrent)).To(Succeed())
+
+	conditions.MarkTrue(machine, clusterv1.InfrastructureReadyCondition)
+	g.Expect(h.Patch(ctx, machine)).To(Succeed())
+
+	// Both changes are preserved.
+	after := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test-machine"}, after)).To(Succeed())
+	g.Expect(conditions.IsTrue(after, clusterv1.BootstrapReadyCondition)).To(BeTrue())
+	g.Expect(conditions.IsTrue(after, clusterv1.InfrastructureReadyCondition)).To(BeTrue())
+	g.Expect(conditions.IsFalse(after, clusterv1.NodeHealthyCondition)).To(BeTrue())
+
+	// A conflicting change to the same condition is reported as an error.
+	h, err = NewHelper(after, fakeClient)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	concurrent = &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test-machine"}, concurrent)).To(Succeed())
+	conditions.MarkFalse(concurrent, clusterv1.InfrastructureReadyCondition, "Reason", clusterv1.ConditionSeverityWarning, "")
+	g.Expect(fakeClient.Status().Update(ctx, concurrent)).To(Succeed())
+
+	conditions.MarkFalse(after, clusterv1.InfrastructureReadyCondition, "OtherReason", clusterv1.ConditionSeverityInfo, "")
+	g.Expect(h.Patch(ctx, after)).NotTo(Succeed())
+}
+
+func TestHelperPatchConditionsRetriesOnConflict(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: "default",
+		},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme)
+	g.Expect(fakeClient.Create(ctx, machine)).To(Succeed())
+
+	// The first attempt to patch the conditions conflicts with a change made by another controller.
+	conflictingClient := &conflictingStatusClient{
+		Client: fakeClient,
+		onConflict: func() error {
+			concurrent := &clusterv1.Machine{}
+			if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test-machine"}, concurrent); err != nil {
+				return err
+			}
+			conditions.MarkTrue(concurrent, clusterv1.BootstrapReadyCondition)
+			return fakeClient.Status().Update(ctx, concurrent)
+		},
+	}
+
+	h, err := NewHelper(machine, conflictingClient)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	conditions.MarkTrue(machine, clusterv1.InfrastructureReadyCondition)
+	g.Expect(h.Patch(ctx, machine)).To(Succeed())
+
+	// The conditions are patched using optimistic locking and merged again after the conflict.
+	g.Expect(conflictingClient.patches).To(HaveLen(2))
+	for _, data := range conflictingClient.patches {
+		g.Expect(data).To(ContainSubstring(`"resourceVersion"`))
+	}
+
+	after := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test-machine"}, after)).To(Succeed())
+	g.Expect(conditions.IsTrue(after, clusterv1.BootstrapReadyCondition)).To(BeTrue())
+	g.Expect(conditions.IsTrue(after, clusterv1.InfrastructureReadyCondition)).To(BeTrue())
+}
+
+// conflictingStatusClient records the status patches using optimistic locking and fails the first
+// one with a conflict, after running onConflict.
+type conflictingStatusClient struct {
+	client.Client
+	onConflict func() error
+	patches    []string
+}
+
+func (c *conflictingStatusClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	client *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := patch.(mergeFromWithOptimisticLock); !ok {
+		return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+	}
+
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	w.client.patches = append(w.client.patches, string(data))
+	if len(w.client.patches) == 1 {
+		if err := w.client.onConflict(); err != nil {
+			return err
+		}
+		return apierrors.NewConflict(schema.GroupResource{Resource: "machines"}, "test-machine", errors.New("conflict"))
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
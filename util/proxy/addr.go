@@ -50,7 +50,7 @@ func (a Addr) String() string {
 }
 
 // NewAddrFromConn creates an Addr from the given connection
-func NewAddrFromConn(c Conn) Addr {
+func NewAddrFromConn(c *Conn) Addr {
 	return Addr{
 		port:       c.stream.Headers().Get(corev1.PortHeader),
 		identifier: c.stream.Identifier(),
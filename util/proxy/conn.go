@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// Conn is a Kubernetes API server proxied type of net/conn
+type Conn struct {
+	stream httpstream.Stream
+
+	lock          sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	readTimer     *time.Timer
+	writeTimer    *time.Timer
+	expired       bool
+}
+
+// timeoutError is returned by the connection once one of its deadlines has passed.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+// Read from the connection
+func (c *Conn) Read(b []byte) (n int, err error) {
+	if c.deadlineExceeded(c.readDeadlineValue()) {
+		return 0, timeoutError{}
+	}
+	n, err = c.stream.Read(b)
+	if err != nil && c.isExpired() {
+		return n, timeoutError{}
+	}
+	return n, err
+}
+
+// Close the underlying proxied connection, the port-forwarded connection it is multiplexed on is left open
+func (c *Conn) Close() error {
+	c.lock.Lock()
+	stopTimer(c.readTimer)
+	stopTimer(c.writeTimer)
+	c.readTimer, c.writeTimer = nil, nil
+	c.lock.Unlock()
+
+	// Closing the stream only closes the writing side, resetting it releases it on both ends.
+	_ = c.stream.Close()
+	return c.stream.Reset()
+}
+
+// Write to the connection
+func (c *Conn) Write(b []byte) (n int, err error) {
+	if c.deadlineExceeded(c.writeDeadlineValue()) {
+		return 0, timeoutError{}
+	}
+	n, err = c.stream.Write(b)
+	if err != nil && c.isExpired() {
+		return n, timeoutError{}
+	}
+	return n, err
+}
+
+// Return a fake address representing the proxied connection
+func (c *Conn) LocalAddr() net.Addr {
+	return NewAddrFromConn(c)
+}
+
+// Return a fake address representing the proxied connection
+func (c *Conn) RemoteAddr() net.Addr {
+	return NewAddrFromConn(c)
+}
+
+// SetDeadline sets the read and write deadlines of the connection.
+// Streams cannot be interrupted and resumed, so when a deadline passes while a read or write is pending
+// the stream is reset and the connection cannot be used any more.
+func (c *Conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetWriteDeadline sets the write deadline of the connection, a zero value disables it.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.writeDeadline = t
+	c.writeTimer = c.resetTimer(c.writeTimer, t)
+	return nil
+}
+
+// SetReadDeadline sets the read deadline of the connection, a zero value disables it.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.readDeadline = t
+	c.readTimer = c.resetTimer(c.readTimer, t)
+	return nil
+}
+
+// resetTimer replaces the given timer with one that expires the connection at the given deadline.
+// It must be called with the lock held.
+func (c *Conn) resetTimer(timer *time.Timer, deadline time.Time) *time.Timer {
+	stopTimer(timer)
+	if deadline.IsZero() {
+		return nil
+	}
+	return time.AfterFunc(time.Until(deadline), c.expire)
+}
+
+// expire resets the stream, unblocking any pending read or write.
+func (c *Conn) expire() {
+	c.lock.Lock()
+	c.expired = true
+	c.lock.Unlock()
+
+	_ = c.stream.Reset()
+}
+
+func (c *Conn) isExpired() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.expired
+}
+
+func (c *Conn) readDeadlineValue() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.readDeadline
+}
+
+func (c *Conn) writeDeadlineValue() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.writeDeadline
+}
+
+func (c *Conn) deadlineExceeded(deadline time.Time) bool {
+	return c.isExpired() || (!deadline.IsZero() && !time.Now().Before(deadline))
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+// NewConn creates a new net/conn interface based on an underlying Kubernetes
+// API server proxy connection
+func NewConn(stream httpstream.Stream) *Conn {
+	return &Conn{
+		stream: stream,
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+// blockingStream is a httpstream.Stream whose reads and writes block until it is reset.
+type blockingStream struct {
+	reset chan struct{}
+}
+
+func newBlockingStream() *blockingStream {
+	return &blockingStream{reset: make(chan struct{})}
+}
+
+func (s *blockingStream) Read(_ []byte) (int, error) {
+	<-s.reset
+	return 0, io.EOF
+}
+
+func (s *blockingStream) Write(_ []byte) (int, error) {
+	<-s.reset
+	return 0, io.ErrClosedPipe
+}
+
+func (s *blockingStream) Close() error { return nil }
+
+func (s *blockingStream) Reset() error {
+	select {
+	case <-s.reset:
+	default:
+		close(s.reset)
+	}
+	return nil
+}
+
+func (s *blockingStream) Headers() http.Header { return http.Header{} }
+
+func (s *blockingStream) Identifier() uint32 { return 1 }
+
+func TestConnDeadlines(t *testing.T) {
+	t.Run("pending read times out", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		c := NewConn(newBlockingStream())
+		g.Expect(c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))).To(gomega.Succeed())
+
+		_, err := c.Read(make([]byte, 1))
+		g.Expect(err).To(gomega.HaveOccurred())
+		netErr, ok := err.(net.Error)
+		g.Expect(ok).To(gomega.BeTrue())
+		g.Expect(netErr.Timeout()).To(gomega.BeTrue())
+	})
+
+	t.Run("pending write times out", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		c := NewConn(newBlockingStream())
+		g.Expect(c.SetDeadline(time.Now().Add(10 * time.Millisecond))).To(gomega.Succeed())
+
+		_, err := c.Write([]byte("x"))
+		g.Expect(err).To(gomega.HaveOccurred())
+		netErr, ok := err.(net.Error)
+		g.Expect(ok).To(gomega.BeTrue())
+		g.Expect(netErr.Timeout()).To(gomega.BeTrue())
+	})
+
+	t.Run("deadline in the past fails immediately", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		s := newBlockingStream()
+		c := NewConn(s)
+		g.Expect(c.SetWriteDeadline(time.Now().Add(-time.Second))).To(gomega.Succeed())
+
+		_, err := c.Write([]byte("x"))
+		g.Expect(err).To(gomega.HaveOccurred())
+		g.Expect(err.(net.Error).Timeout()).To(gomega.BeTrue())
+	})
+
+	t.Run("cleared deadline does not expire the connection", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+
+		s := newBlockingStream()
+		c := NewConn(s)
+		g.Expect(c.SetDeadline(time.Now().Add(10 * time.Millisecond))).To(gomega.Succeed())
+		g.Expect(c.SetDeadline(time.Time{})).To(gomega.Succeed())
+
+		time.Sleep(50 * time.Millisecond)
+		g.Expect(s.reset).NotTo(gomega.BeClosed())
+		g.Expect(c.Close()).To(gomega.Succeed())
+	})
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	httpspdy "k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Dialer creates connections using Kubernetes API Server port-forwarding.
+// All the connections created by a Dialer are multiplexed on a single port-forwarded connection to the API server,
+// which is established again if it's lost. A Dialer is safe for concurrent use.
+type Dialer struct {
+	proxy          Proxy
+	clientset      kubernetes.Interface
+	proxyTransport http.RoundTripper
+	upgrader       spdy.Upgrader
+	timeout        time.Duration
+
+	lock      sync.Mutex
+	conn      httpstream.Connection
+	port      int
+	requestID int
+}
+
+// NewDialer creates a new dialer for a given API server scope
+func NewDialer(p Proxy, options ...func(*Dialer) error) (*Dialer, error) {
+	if p.Port == 0 {
+		return nil, errors.New("port required")
+	}
+	if p.Kind != KindPods && p.Kind != KindServices {
+		return nil, errors.Errorf("unsupported kind %q, must be either %q or %q", p.Kind, KindPods, KindServices)
+	}
+	if p.KubeConfig == nil {
+		return nil, errors.New("kubeconfig required")
+	}
+
+	dialer := &Dialer{
+		proxy: p,
+	}
+
+	for _, option := range options {
+		err := option(dialer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dialer.timeout == 0 {
+		dialer.timeout = defaultTimeout
+	}
+	config := rest.CopyConfig(p.KubeConfig)
+	config.Timeout = dialer.timeout
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	proxyTransport, upgrader, err := roundTripperFor(config, dialer.timeout, p.KeepAlive)
+	if err != nil {
+		return nil, err
+	}
+	dialer.proxyTransport = proxyTransport
+	dialer.upgrader = upgrader
+	dialer.clientset = clientset
+	return dialer, nil
+}
+
+// roundTripperFor returns a round tripper and upgrader to use with SPDY, like spdy.RoundTripperFor does, whose
+// connections to the API server are established within the given timeout and kept alive at the given interval.
+func roundTripperFor(config *rest.Config, timeout time.Duration, keepAlive *time.Duration) (http.RoundTripper, spdy.Upgrader, error) {
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	upgradeRoundTripper := httpspdy.NewSpdyRoundTripper(tlsConfig, true, false)
+	upgradeRoundTripper.Dialer = &net.Dialer{Timeout: timeout}
+	if keepAlive != nil {
+		upgradeRoundTripper.Dialer.KeepAlive = *keepAlive
+	}
+	wrapper, err := rest.HTTPWrappersForConfig(config, upgradeRoundTripper)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wrapper, upgradeRoundTripper, nil
+}
+
+// DialContextWithAddr is a GO grpc compliant dialer construct
+func (d *Dialer) DialContextWithAddr(ctx context.Context, addr string) (net.Conn, error) {
+	return d.DialContext(ctx, scheme, addr)
+}
+
+// DialContext creates proxied port-forwarded connections.
+// The network and address are ignored, connections are always made to the port of the proxied resource.
+func (d *Dialer) DialContext(ctx context.Context, _ string, _ string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	p, err := d.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, fmt.Sprintf("%d", d.port))
+	// Each connection is made of a pair of streams sharing a request ID.
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.Itoa(d.requestID))
+	d.requestID++
+	errorStream, err := p.CreateStream(headers)
+	if err != nil {
+		d.closeConnection()
+		return nil, errors.Wrap(err, "error creating error stream")
+	}
+
+	if err := errorStream.Close(); err != nil {
+		d.closeConnection()
+		return nil, err
+	}
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := p.CreateStream(headers)
+	if err != nil {
+		d.closeConnection()
+		return nil, errors.Wrap(err, "error creating forwarding stream")
+	}
+
+	c := NewConn(dataStream)
+
+	return c, nil
+}
+
+// Close closes the port-forwarded connection to the API server, and with it all the connections created by the Dialer.
+func (d *Dialer) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
+
+// connection returns the port-forwarded connection to the API server, establishing it if needed.
+// It must be called with the lock held.
+func (d *Dialer) connection() (httpstream.Connection, error) {
+	if d.conn != nil {
+		select {
+		case <-d.conn.CloseChan():
+			d.conn = nil
+		default:
+			return d.conn, nil
+		}
+	}
+
+	podName, port := d.proxy.ResourceName, d.proxy.Port
+	if d.proxy.Kind == KindServices {
+		var err error
+		podName, port, err = d.servicePod()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req := d.clientset.CoreV1().RESTClient().
+		Post().
+		Resource(KindPods).
+		Namespace(d.proxy.Namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	dialer := spdy.NewDialer(d.upgrader, &http.Client{Transport: d.proxyTransport}, "POST", req.URL())
+
+	p, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error upgrading connection")
+	}
+	d.conn = p
+	d.port = port
+	d.requestID = 0
+	return p, nil
+}
+
+// closeConnection closes the port-forwarded connection to the API server after a failure, so that the next connection
+// establishes a new one. It must be called with the lock held.
+func (d *Dialer) closeConnection() {
+	if d.conn != nil {
+		_ = d.conn.Close()
+		d.conn = nil
+	}
+}
+
+// servicePod returns the name of a running pod selected by the proxied service, along with the port of the pod
+// the service port is forwarded to.
+func (d *Dialer) servicePod() (string, int, error) {
+	service, err := d.clientset.CoreV1().Services(d.proxy.Namespace).Get(d.proxy.ResourceName, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to get service %s/%s", d.proxy.Namespace, d.proxy.ResourceName)
+	}
+	if len(service.Spec.Selector) == 0 {
+		return "", 0, errors.Errorf("service %s/%s doesn't select any pod", d.proxy.Namespace, d.proxy.ResourceName)
+	}
+
+	var servicePort *corev1.ServicePort
+	for i := range service.Spec.Ports {
+		if int(service.Spec.Ports[i].Port) == d.proxy.Port {
+			servicePort = &service.Spec.Ports[i]
+			break
+		}
+	}
+	if servicePort == nil {
+		return "", 0, errors.Errorf("service %s/%s doesn't expose port %d", d.proxy.Namespace, d.proxy.ResourceName, d.proxy.Port)
+	}
+
+	pods, err := d.clientset.CoreV1().Pods(d.proxy.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "failed to list the pods of service %s/%s", d.proxy.Namespace, d.proxy.ResourceName)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if port, ok := targetPort(pod, servicePort); ok {
+			return pod.Name, port, nil
+		}
+	}
+	return "", 0, errors.Errorf("no running pod of service %s/%s serves port %d", d.proxy.Namespace, d.proxy.ResourceName, d.proxy.Port)
+}
+
+// targetPort returns the port of the pod a service port is forwarded to, resolving named ports against the ports
+// of the pod containers.
+func targetPort(pod *corev1.Pod, servicePort *corev1.ServicePort) (int, bool) {
+	switch target := servicePort.TargetPort; {
+	case target.Type == intstr.String && target.StrVal != "":
+		for _, c := range pod.Spec.Containers {
+			for _, p := range c.Ports {
+				if p.Name == target.StrVal {
+					return int(p.ContainerPort), true
+				}
+			}
+		}
+		return 0, false
+	case target.Type == intstr.Int && target.IntVal != 0:
+		return int(target.IntVal), true
+	default:
+		// The target port defaults to the service port.
+		return int(servicePort.Port), true
+	}
+}
+
+// NewHTTPTransport returns an HTTP transport whose connections are created by the given dialer, HTTPS requests use
+// the given TLS configuration. Idle connections are kept by the transport to be reused by the following requests.
+func NewHTTPTransport(d ContextDialer, tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		DialContext:         d.DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: defaultTimeout,
+	}
+}
+
+// DialTimeout sets the timeout
+func DialTimeout(duration time.Duration) func(*Dialer) error {
+	return func(d *Dialer) error {
+		return d.setTimeout(duration)
+	}
+}
+
+func (d *Dialer) setTimeout(duration time.Duration) error {
+	d.timeout = duration
+	return nil
+}
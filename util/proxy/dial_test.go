@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewDialer(t *testing.T) {
+	tests := []struct {
+		name      string
+		proxy     Proxy
+		expectErr bool
+	}{
+		{
+			name:  "pod proxy",
+			proxy: Proxy{Kind: KindPods, Namespace: "kube-system", ResourceName: "etcd-node", KubeConfig: &rest.Config{Host: "https://cluster:6443"}, Port: 2379},
+		},
+		{
+			name:  "service proxy",
+			proxy: Proxy{Kind: KindServices, Namespace: "default", ResourceName: "webhook", KubeConfig: &rest.Config{Host: "https://cluster:6443"}, Port: 443},
+		},
+		{
+			name:      "missing port",
+			proxy:     Proxy{Kind: KindPods, Namespace: "kube-system", ResourceName: "etcd-node", KubeConfig: &rest.Config{Host: "https://cluster:6443"}},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported kind",
+			proxy:     Proxy{Kind: "deployments", Namespace: "default", ResourceName: "webhook", KubeConfig: &rest.Config{Host: "https://cluster:6443"}, Port: 443},
+			expectErr: true,
+		},
+		{
+			name:      "missing kubeconfig",
+			proxy:     Proxy{Kind: KindPods, Namespace: "kube-system", ResourceName: "etcd-node", Port: 2379},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			_, err := NewDialer(tt.proxy)
+			if tt.expectErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			// The caller's kubeconfig is left untouched.
+			g.Expect(tt.proxy.KubeConfig.Timeout).To(gomega.BeZero())
+		})
+	}
+}
+
+func TestDialerServicePod(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "webhook"},
+			Ports: []corev1.ServicePort{
+				{Name: "https", Port: 443, TargetPort: intstr.FromString("webhook")},
+				{Name: "metrics", Port: 8080, TargetPort: intstr.FromInt(9090)},
+				{Name: "health", Port: 8081},
+			},
+		},
+	}
+	newPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "webhook"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "webhook", Ports: []corev1.ContainerPort{{Name: "webhook", ContainerPort: 9443}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		objs       []runtime.Object
+		port       int
+		expectPod  string
+		expectPort int
+		expectErr  bool
+	}{
+		{
+			name:       "resolves a named target port",
+			objs:       []runtime.Object{service, newPod("pending", corev1.PodPending), newPod("running", corev1.PodRunning)},
+			port:       443,
+			expectPod:  "running",
+			expectPort: 9443,
+		},
+		{
+			name:       "uses a numeric target port",
+			objs:       []runtime.Object{service, newPod("running", corev1.PodRunning)},
+			port:       8080,
+			expectPod:  "running",
+			expectPort: 9090,
+		},
+		{
+			name:       "defaults the target port to the service port",
+			objs:       []runtime.Object{service, newPod("running", corev1.PodRunning)},
+			port:       8081,
+			expectPod:  "running",
+			expectPort: 8081,
+		},
+		{
+			name:      "fails when the service doesn't expose the port",
+			objs:      []runtime.Object{service, newPod("running", corev1.PodRunning)},
+			port:      80,
+			expectErr: true,
+		},
+		{
+			name:      "fails when no pod is running",
+			objs:      []runtime.Object{service, newPod("pending", corev1.PodPending)},
+			port:      443,
+			expectErr: true,
+		},
+		{
+			name:      "fails when the service doesn't exist",
+			port:      443,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			d := &Dialer{
+				proxy:     Proxy{Kind: KindServices, Namespace: "default", ResourceName: "webhook", Port: tt.port},
+				clientset: fake.NewSimpleClientset(tt.objs...),
+			}
+			pod, port, err := d.servicePod()
+			if tt.expectErr {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(pod).To(gomega.Equal(tt.expectPod))
+			g.Expect(port).To(gomega.Equal(tt.expectPort))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake implements an in-process proxy.ContextDialer for tests.
+package fake
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api/util/proxy"
+)
+
+var _ proxy.ContextDialer = &Dialer{}
+
+// Dialer is an in-process implementation of proxy.ContextDialer, the connections it creates are served by a handler
+// running in the same process instead of a port-forwarded workload cluster resource.
+type Dialer struct {
+	// Handler serves the server side of every connection created by the Dialer, it owns the connection and must
+	// close it.
+	Handler func(conn net.Conn)
+
+	// Err, if set, is returned by all the dials instead of creating a connection.
+	Err error
+
+	lock     sync.Mutex
+	dials    []string
+	listener *connListener
+}
+
+// NewDialer returns a Dialer whose connections are served by the given handler.
+func NewDialer(handler func(conn net.Conn)) *Dialer {
+	return &Dialer{Handler: handler}
+}
+
+// NewHTTPDialer returns a Dialer whose connections are served by the given HTTP handler.
+func NewHTTPDialer(handler http.Handler) *Dialer {
+	listener := &connListener{conns: make(chan net.Conn), done: make(chan struct{})}
+	go func() { _ = http.Serve(listener, handler) }()
+	return &Dialer{Handler: listener.serve, listener: listener}
+}
+
+// DialContext creates a connection served by the handler of the Dialer.
+func (d *Dialer) DialContext(ctx context.Context, _ string, addr string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	d.dials = append(d.dials, addr)
+	d.lock.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	client, server := net.Pipe()
+	go d.Handler(server)
+	return client, nil
+}
+
+// DialContextWithAddr is the gRPC compliant version of DialContext.
+func (d *Dialer) DialContextWithAddr(ctx context.Context, addr string) (net.Conn, error) {
+	return d.DialContext(ctx, "", addr)
+}
+
+// Dials returns the addresses of all the connections requested from the Dialer.
+func (d *Dialer) Dials() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]string{}, d.dials...)
+}
+
+// Close stops serving the connections of a Dialer created by NewHTTPDialer.
+func (d *Dialer) Close() error {
+	if d.listener != nil {
+		return d.listener.Close()
+	}
+	return nil
+}
+
+// connListener is a net.Listener accepting the connections it is handed over in-process.
+type connListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (l *connListener) serve(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, errors.New("listener closed")
+	}
+}
+
+func (l *connListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api/util/proxy"
+)
+
+func TestDialer(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	d := NewDialer(func(conn net.Conn) {
+		defer conn.Close()
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte(line))
+	})
+
+	conn, err := d.DialContextWithAddr(context.Background(), "etcd:2379")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping\n"))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(reply).To(gomega.Equal("ping\n"))
+	g.Expect(d.Dials()).To(gomega.ConsistOf("etcd:2379"))
+}
+
+func TestDialerError(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	d := NewDialer(func(conn net.Conn) { conn.Close() })
+	d.Err = errors.New("connection refused")
+
+	_, err := d.DialContext(context.Background(), "tcp", "etcd:2379")
+	g.Expect(err).To(gomega.MatchError("connection refused"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = d.DialContext(ctx, "tcp", "etcd:2379")
+	g.Expect(err).To(gomega.MatchError(context.Canceled))
+}
+
+func TestHTTPDialer(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	d := NewHTTPDialer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello from %s", r.URL.Path)
+	}))
+	defer d.Close()
+
+	client := &http.Client{Transport: proxy.NewHTTPTransport(d, nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("http://webhook.default.svc/healthz")
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		body, err := ioutil.ReadAll(resp.Body)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(resp.Body.Close()).To(gomega.Succeed())
+		g.Expect(string(body)).To(gomega.Equal("hello from /healthz"))
+	}
+	// The connection is reused by the transport.
+	g.Expect(d.Dials()).To(gomega.HaveLen(1))
+}
@@ -14,19 +14,32 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package proxy implements connections to the ports of pods and services of a workload cluster, tunnelled through
+// the port-forwarding of its API server, so that they can be reached without any network route to the cluster.
 package proxy
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
 	"time"
 
 	"k8s.io/client-go/rest"
 )
 
+const (
+	// KindPods is the Proxy kind used to connect to a port of a pod.
+	KindPods = "pods"
+
+	// KindServices is the Proxy kind used to connect to a port of a service, through one of the running pods
+	// selected by the service.
+	KindServices = "services"
+)
+
 // Proxy defines the API server port-forwarded proxy
 type Proxy struct {
 
-	// Kind is the kind of Kubernetes resource
+	// Kind is the kind of Kubernetes resource, either KindPods or KindServices
 	Kind string
 
 	// Namespace is the namespace in which the Kubernetes resource exists
@@ -48,3 +61,13 @@ type Proxy struct {
 	// Port is the port to be forwarded from the relevant resource
 	Port int
 }
+
+// ContextDialer creates connections to a workload cluster resource. It is implemented by Dialer, and by the in-process
+// fake of the fake package for tests.
+type ContextDialer interface {
+	// DialContext creates a connection, the network and address are ignored by the port-forwarded implementation.
+	DialContext(ctx context.Context, network string, addr string) (net.Conn, error)
+
+	// DialContextWithAddr is a gRPC compliant version of DialContext, it can be used with grpc.WithContextDialer.
+	DialContextWithAddr(ctx context.Context, addr string) (net.Conn, error)
+}
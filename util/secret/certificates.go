@@ -94,24 +94,56 @@ func NewCertificatesForInitialControlPlane(config *v1beta1.ClusterConfiguration)
 
 	// TODO make sure all the fields are actually defined and return an error if not
 	if config.Etcd.External != nil {
-		etcdCert = &Certificate{
-			Purpose:  EtcdCA,
-			CertFile: config.Etcd.External.CAFile,
-		}
-		apiserverEtcdClientCert := &Certificate{
-			Purpose:  APIServerEtcdClient,
-			CertFile: config.Etcd.External.CertFile,
-			KeyFile:  config.Etcd.External.KeyFile,
-		}
-		certificates = append(certificates, apiserverEtcdClientCert)
+		return append(certificates, newCertificatesForExternalEtcd(config.Etcd.External)...)
 	}
 
 	certificates = append(certificates, etcdCert)
 	return certificates
 }
 
-// NewCertificatesForJoiningControlPlane gets any certs that exist and writes them to disk
-func NewCertificatesForJoiningControlPlane() Certificates {
+// newCertificatesForExternalEtcd returns the user supplied certificates used by the API server to connect to an
+// external etcd cluster.
+func newCertificatesForExternalEtcd(external *v1beta1.ExternalEtcd) Certificates {
+	return Certificates{
+		&Certificate{
+			Purpose:  EtcdCA,
+			CertFile: external.CAFile,
+			External: true,
+		},
+		&Certificate{
+			Purpose:  APIServerEtcdClient,
+			CertFile: external.CertFile,
+			KeyFile:  external.KeyFile,
+			External: true,
+		},
+	}
+}
+
+// NewCertificatesForJoiningControlPlane gets any certs that exist and writes them to disk.
+// When the given cluster configuration uses an external etcd cluster, the user supplied etcd certificates are
+// used instead of the etcd CA.
+func NewCertificatesForJoiningControlPlane(config *v1beta1.ClusterConfiguration) Certificates {
+	if config != nil && config.Etcd.External != nil {
+		certificates := Certificates{
+			&Certificate{
+				Purpose:  ClusterCA,
+				CertFile: filepath.Join(defaultCertificatesDir, "ca.crt"),
+				KeyFile:  filepath.Join(defaultCertificatesDir, "ca.key"),
+			},
+			&Certificate{
+				Purpose:  ServiceAccount,
+				CertFile: filepath.Join(defaultCertificatesDir, "sa.pub"),
+				KeyFile:  filepath.Join(defaultCertificatesDir, "sa.key"),
+			},
+			&Certificate{
+				Purpose:  FrontProxyCA,
+				CertFile: filepath.Join(defaultCertificatesDir, "front-proxy-ca.crt"),
+				KeyFile:  filepath.Join(defaultCertificatesDir, "front-proxy-ca.key"),
+			},
+		}
+		return append(certificates, newCertificatesForExternalEtcd(config.Etcd.External)...)
+	}
+
 	return Certificates{
 		&Certificate{
 			Purpose:  ClusterCA,
@@ -186,15 +218,19 @@ func (c Certificates) Lookup(ctx context.Context, ctrlclient client.Client, clus
 	return nil
 }
 
-// EnsureAllExist ensure that there is some data present for every certificate
+// EnsureAllExist ensure that there is some data present for every certificate.
+// The key of an external certificate is only required if it is written to disk.
 func (c Certificates) EnsureAllExist() error {
 	for _, certificate := range c {
 		if certificate.KeyPair == nil {
-			return ErrMissingCertificate
+			return errors.Wrapf(ErrMissingCertificate, "for certificate: %s", certificate.Purpose)
 		}
 		if len(certificate.KeyPair.Cert) == 0 {
 			return errors.Wrapf(ErrMissingCrt, "for certificate: %s", certificate.Purpose)
 		}
+		if certificate.External && certificate.KeyFile == "" {
+			continue
+		}
 		if len(certificate.KeyPair.Key) == 0 {
 			return errors.Wrapf(ErrMissingKey, "for certificate: %s", certificate.Purpose)
 		}
@@ -209,10 +245,12 @@ type certGenerator func() (*certs.KeyPair, error)
 func (c Certificates) Generate() error {
 	for _, certificate := range c {
 		if certificate.KeyPair == nil {
+			// Do not generate the external certificates, they are user supplied.
+			if certificate.External {
+				continue
+			}
 			var generator certGenerator
 			switch certificate.Purpose {
-			case APIServerEtcdClient: // Do not generate the APIServerEtcdClient key pair. It is user supplied
-				continue
 			case ServiceAccount:
 				generator = generateServiceAccountKeys
 			default:
@@ -270,6 +308,10 @@ type Certificate struct {
 	Purpose           Purpose
 	KeyPair           *certs.KeyPair
 	CertFile, KeyFile string
+
+	// External is true for the certificates supplied by the user for an external component,
+	// like an external etcd cluster. They are never generated.
+	External bool
 }
 
 // Hashes hashes all the certificates stored in a CA certificate.
@@ -43,3 +43,45 @@ func TestNewCertificatesForControlPlane_External(t *testing.T) {
 		t.Fatal("control planes with external etcd must *not* define the etcd key file")
 	}
 }
+
+func TestNewCertificatesForJoiningControlPlane_External(t *testing.T) {
+	config := &v1beta1.ClusterConfiguration{
+		Etcd: v1beta1.Etcd{
+			External: &v1beta1.ExternalEtcd{
+				CAFile:   "/etc/kubernetes/pki/etcd/ca.crt",
+				CertFile: "/etc/kubernetes/pki/apiserver-etcd-client.crt",
+				KeyFile:  "/etc/kubernetes/pki/apiserver-etcd-client.key",
+			},
+		},
+	}
+
+	certs := secret.NewCertificatesForJoiningControlPlane(config)
+	etcdCA := certs.GetByPurpose(secret.EtcdCA)
+	if etcdCA.KeyFile != "" || !etcdCA.External {
+		t.Fatal("joining control planes with external etcd must use the user supplied etcd CA without key")
+	}
+	if client := certs.GetByPurpose(secret.APIServerEtcdClient); client == nil || client.KeyFile != config.Etcd.External.KeyFile {
+		t.Fatal("joining control planes with external etcd must write the apiserver etcd client certificate")
+	}
+}
+
+func TestGenerate_External(t *testing.T) {
+	config := &v1beta1.ClusterConfiguration{
+		Etcd: v1beta1.Etcd{
+			External: &v1beta1.ExternalEtcd{},
+		},
+	}
+
+	certs := secret.NewCertificatesForInitialControlPlane(config)
+	if err := certs.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, purpose := range []secret.Purpose{secret.EtcdCA, secret.APIServerEtcdClient} {
+		if certs.GetByPurpose(purpose).KeyPair != nil {
+			t.Fatalf("the user supplied %s certificate must not be generated", purpose)
+		}
+	}
+	if err := certs.EnsureAllExist(); err == nil {
+		t.Fatal("expected an error when the external etcd certificates are missing")
+	}
+}